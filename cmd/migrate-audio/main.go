@@ -0,0 +1,113 @@
+// Command migrate-audio moves audio that earlier versions inlined into
+// dialog_turns.audio_url (and dialogs.dialog_json) as base64 data URLs into
+// the configured AudioStore, leaving only the store key on each row.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"leveltalk/internal/audiostore"
+	"leveltalk/internal/config"
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/storage"
+	"leveltalk/migrations"
+)
+
+func main() {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	batchSize := flag.Int("batch", 100, "number of turns to migrate per batch")
+	flag.Parse()
+
+	if err := run(logger, *batchSize); err != nil {
+		logger.Error("audio migration failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+func run(logger *slog.Logger, batchSize int) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	db, err := sql.Open("pgx", cfg.DBDSN)
+	if err != nil {
+		return fmt.Errorf("open db: %w", err)
+	}
+	defer db.Close()
+
+	if err := storage.RunMigrations(ctx, db, migrations.Files); err != nil {
+		return fmt.Errorf("run migrations: %w", err)
+	}
+
+	store, err := audiostore.FromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("init audio store: %w", err)
+	}
+
+	repo := storage.NewDialogRepository(db)
+
+	migrated, failed := 0, 0
+	afterDialog, afterPosition := uuid.Nil, -1
+	for {
+		turns, err := repo.ListInlineAudioTurns(ctx, afterDialog, afterPosition, batchSize)
+		if err != nil {
+			return err
+		}
+		for _, turn := range turns {
+			// Failed turns keep their data URL, so the next batch starts after them.
+			afterDialog, afterPosition = turn.DialogID, turn.Position
+			if err := migrateTurn(ctx, repo, store, turn); err != nil {
+				failed++
+				logger.Warn("skipping turn",
+					slog.String("dialog_id", turn.DialogID.String()),
+					slog.String("turn_id", turn.ID.String()),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+			migrated++
+		}
+
+		logger.Info("batch done", slog.Int("migrated", migrated), slog.Int("failed", failed))
+
+		if len(turns) < batchSize {
+			break
+		}
+	}
+
+	logger.Info("audio migration finished", slog.Int("migrated", migrated), slog.Int("failed", failed))
+	return nil
+}
+
+func migrateTurn(ctx context.Context, repo *storage.DialogRepository, store dialogs.AudioStore, turn storage.InlineAudioTurn) error {
+	data, contentType, err := audiostore.DecodeDataURL(turn.AudioURL)
+	if err != nil {
+		return err
+	}
+	if contentType == "" {
+		contentType = "audio/mpeg"
+	}
+
+	key := dialogs.AudioKey(turn.DialogID, turn.ID)
+	if err := store.Put(ctx, key, data, contentType); err != nil {
+		return fmt.Errorf("put audio: %w", err)
+	}
+	if err := repo.AttachTurnAudio(ctx, turn.DialogID, turn.ID, key); err != nil {
+		return fmt.Errorf("attach audio: %w", err)
+	}
+	return nil
+}
@@ -14,16 +14,31 @@ import (
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
+	"leveltalk/internal/audiostore"
+	"leveltalk/internal/cefr"
 	"leveltalk/internal/config"
 	"leveltalk/internal/dialogs"
 	apphttp "leveltalk/internal/http"
 	"leveltalk/internal/llm"
+	"leveltalk/internal/stemmer"
 	"leveltalk/internal/storage"
 	"leveltalk/internal/tts"
+	"leveltalk/internal/ttscache"
 	"leveltalk/internal/ui"
+	"leveltalk/internal/voices"
+	"leveltalk/internal/worker"
 	"leveltalk/migrations"
 )
 
+const (
+	// staleJobAge is how long a job may sit in a running state before startup
+	// assumes its worker died and requeues it.
+	staleJobAge     = 10 * time.Minute
+	jobDrainTimeout = 30 * time.Second
+
+	ttsCacheEvictInterval = time.Hour
+)
+
 func main() {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 	if err := run(logger); err != nil {
@@ -57,30 +72,93 @@ func run(logger *slog.Logger) error {
 	}
 
 	repo := storage.NewDialogRepository(db)
+	jobRepo := storage.NewJobRepository(db)
 
-	var llmClient dialogs.LLMClient
-	llmClient = llm.NewStubClient(logger)
-	if cfg.LLMAPIKey != "" && cfg.LLMModel != "" {
-		logger.Info("using OpenAI LLM client", slog.String("model", cfg.LLMModel))
-		llmClient = llm.NewOpenAIClient(logger, cfg.LLMAPIKey, cfg.LLMModel, nil)
-	} else {
-		logger.Info("LLM API key or model missing; falling back to stub client")
+	llmClient, err := llm.FromConfig(logger, cfg)
+	if err != nil {
+		return fmt.Errorf("init llm: %w", err)
 	}
+	logger.Info("using LLM provider", slog.String("provider", cfg.LLMProvider), slog.String("model", cfg.LLMModel))
 
-	var ttsClient dialogs.TTSClient = tts.NewStubClient()
-	hasAPIKey := cfg.ElevenLabsAPIKey != ""
-	hasVoice := cfg.ElevenLabsVoice != ""
-	if hasAPIKey && hasVoice {
-		logger.Info("using ElevenLabs TTS client", slog.String("voice", cfg.ElevenLabsVoice))
-		ttsClient = tts.NewElevenLabsClient(logger, cfg.ElevenLabsAPIKey, cfg.ElevenLabsVoice, nil)
-	} else {
-		logger.Info("ElevenLabs API key or voice missing; using TTS stub",
-			slog.Bool("has_api_key", hasAPIKey),
-			slog.Bool("has_voice", hasVoice),
-		)
+	var voiceCaster dialogs.VoiceCaster
+	if cfg.VoiceCatalog != "" {
+		catalog, err := voices.LoadCatalog(cfg.VoiceCatalog, cfg.ElevenLabsVoice)
+		if err != nil {
+			return fmt.Errorf("load voice catalog: %w", err)
+		}
+		logger.Info("using voice catalog", slog.String("path", cfg.VoiceCatalog))
+		voiceCaster = catalog
 	}
 
-	dialogService := dialogs.NewService(repo, llmClient, ttsClient)
+	ttsClient, err := tts.FromConfig(logger, cfg)
+	if err != nil {
+		return fmt.Errorf("init tts: %w", err)
+	}
+	logger.Info("using TTS provider", slog.String("provider", cfg.TTSProvider))
+
+	var ttsCache *ttscache.Client
+	if cfg.TTSCache != "" {
+		var backend ttscache.Backend
+		switch cfg.TTSCache {
+		case "postgres":
+			backend = storage.NewTTSCacheRepository(db)
+		default:
+			backend, err = ttscache.NewDirBackend(cfg.TTSCacheDir)
+			if err != nil {
+				return fmt.Errorf("init tts cache: %w", err)
+			}
+		}
+		ttsCache = ttscache.New(ttsClient, backend, &ttscache.Options{Logger: logger, MaxBytes: cfg.TTSCacheMaxBytes})
+		ttsClient = ttsCache
+		logger.Info("using TTS cache", slog.String("kind", cfg.TTSCache))
+		go maintainTTSCache(ctx, logger, ttsCache)
+	}
+
+	audioStore, err := audiostore.FromConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("init audio store: %w", err)
+	}
+	logger.Info("using audio store", slog.String("kind", cfg.AudioStore))
+
+	stemmers := make(map[string]dialogs.Stemmer)
+	for _, lang := range stemmer.Languages() {
+		s, err := stemmer.New(lang)
+		if err != nil {
+			return fmt.Errorf("init stemmer: %w", err)
+		}
+		stemmers[lang] = s
+	}
+
+	levelAnalyzer, err := cefr.New()
+	if err != nil {
+		return fmt.Errorf("init cefr analyzer: %w", err)
+	}
+
+	dialogService := dialogs.NewService(repo, llmClient, ttsClient, audioStore, &dialogs.ServiceOptions{
+		Logger:                logger,
+		Jobs:                  jobRepo,
+		JobMaxAttempts:        cfg.JobMaxAttempts,
+		Voices:                voiceCaster,
+		TTSConcurrency:        cfg.TTSConcurrency,
+		Stemmers:              stemmers,
+		CoverageThreshold:     cfg.CoverageThreshold,
+		CoverageRegenerations: cfg.CoverageRegenerations,
+		LevelEstimator:        levelAnalyzer,
+		LevelRegenerations:    cfg.LevelRegenerations,
+		RolePlay:              storage.NewRolePlayRepository(db),
+		Review:                storage.NewReviewRepository(db),
+		Practice:              storage.NewPracticeRepository(db),
+	})
+
+	// Jobs still marked as running belong to a process that died mid-flight.
+	if released, err := dialogService.ReleaseStaleJobs(ctx, staleJobAge); err != nil {
+		return fmt.Errorf("release stale jobs: %w", err)
+	} else if released > 0 {
+		logger.Info("returned stale jobs to the queue", slog.Int("jobs", released))
+	}
+
+	pool := worker.NewPool(logger, dialogService, cfg.Workers, nil)
+	pool.Start()
 
 	tmpl, err := ui.ParseTemplates()
 	if err != nil {
@@ -110,7 +188,15 @@ func run(logger *slog.Logger) error {
 		if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return fmt.Errorf("shutdown server: %w", err)
 		}
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), jobDrainTimeout)
+		defer cancelDrain()
+		if err := pool.Shutdown(drainCtx); err != nil {
+			logger.Warn("job workers did not drain in time", slog.String("error", err.Error()))
+		}
 	case err := <-errCh:
+		drainCtx, cancelDrain := context.WithTimeout(context.Background(), jobDrainTimeout)
+		defer cancelDrain()
+		_ = pool.Shutdown(drainCtx)
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return fmt.Errorf("server error: %w", err)
 		}
@@ -119,6 +205,24 @@ func run(logger *slog.Logger) error {
 	return nil
 }
 
+// maintainTTSCache trims the TTS cache on startup and then periodically,
+// logging its hit and miss counters each time whether or not it evicts.
+func maintainTTSCache(ctx context.Context, logger *slog.Logger, cache *ttscache.Client) {
+	ticker := time.NewTicker(ttsCacheEvictInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := cache.Evict(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("tts cache eviction failed", slog.String("error", err.Error()))
+		}
+		cache.LogStats()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func pingDB(ctx context.Context, db *sql.DB) error {
 	const (
 		maxAttempts = 10
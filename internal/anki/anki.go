@@ -0,0 +1,355 @@
+// Package anki exports dialogs as Anki decks (.apkg): a zip holding a
+// schema 11 collection database, a media index and the media files.
+package anki
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// Note type ids are fixed so that importing a second export reuses the note
+// types of the first.
+const (
+	vocabularyModelID = 1718000000001
+	sentenceModelID   = 1718000000002
+	defaultDeckID     = 1
+)
+
+// fieldSeparator joins the fields of a note.
+const fieldSeparator = "\x1f"
+
+// AudioLoader returns the audio of a turn, or nil when it has none.
+type AudioLoader func(ctx context.Context, turn dialogs.DialogTurn) ([]byte, error)
+
+// Options tune an export.
+type Options struct {
+	// Sentences adds a note with a listening card for every turn.
+	Sentences bool
+	// Now stamps the collection and numbers its notes; the zero value
+	// means time.Now.
+	Now time.Time
+}
+
+// Export writes an .apkg deck for dlgs to w. Every dialog becomes a subdeck
+// of "LevelTalk" with one vocabulary note per input word: the word, its
+// translation, the first turn that uses it and that turn's audio.
+func Export(ctx context.Context, w io.Writer, dlgs []dialogs.Dialog, loadAudio AudioLoader, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	b := &builder{now: now, nextID: now.UnixMilli(), media: map[uuid.UUID]string{}}
+	parent := b.addDeck("LevelTalk")
+	for _, dlg := range dlgs {
+		deckID := b.addDeck("LevelTalk::" + deckName(dlg))
+		for _, word := range dlg.InputWords {
+			turn, ok := exampleTurn(dlg, word)
+			var sound string
+			if ok {
+				var err error
+				if sound, err = b.sound(ctx, turn, loadAudio); err != nil {
+					return err
+				}
+			}
+			b.addNote(vocabularyModelID, deckID, "vocabulary:"+dlg.ID.String()+":"+word, tags(dlg), []string{
+				html.EscapeString(word),
+				html.EscapeString(dlg.Translations[word]),
+				html.EscapeString(turn.Text),
+				html.EscapeString(turn.Translation),
+				sound,
+			})
+		}
+		if !opts.Sentences {
+			continue
+		}
+		for _, turn := range dlg.Turns {
+			sound, err := b.sound(ctx, turn, loadAudio)
+			if err != nil {
+				return err
+			}
+			b.addNote(sentenceModelID, deckID, "sentence:"+turn.ID.String(), tags(dlg), []string{
+				html.EscapeString(turn.Text),
+				html.EscapeString(turn.Translation),
+				html.EscapeString(turn.Speaker),
+				sound,
+			})
+		}
+	}
+
+	collection, err := b.collection(parent)
+	if err != nil {
+		return fmt.Errorf("build collection: %w", err)
+	}
+	return b.writePackage(w, collection)
+}
+
+type builder struct {
+	now    time.Time
+	nextID int64
+
+	decks map[string]any
+	notes []sqliteRow
+	cards []sqliteRow
+
+	media      map[uuid.UUID]string // Turn ID -> media file name
+	mediaFiles []mediaFile
+}
+
+type mediaFile struct {
+	name string
+	data []byte
+}
+
+// id returns a fresh id. Anki ids are millisecond timestamps, so they count
+// up from the export time.
+func (b *builder) id() int64 {
+	b.nextID++
+	return b.nextID
+}
+
+func (b *builder) addDeck(name string) int64 {
+	if b.decks == nil {
+		b.decks = map[string]any{strconv.Itoa(defaultDeckID): deck(defaultDeckID, "Default", b.now)}
+	}
+	id := b.id()
+	b.decks[strconv.FormatInt(id, 10)] = deck(id, name, b.now)
+	return id
+}
+
+// addNote adds a note of model with one new card. key identifies the
+// note across exports, so importing again updates it instead of adding a
+// duplicate.
+func (b *builder) addNote(model, deckID int64, key string, tags string, fields []string) {
+	noteID := b.id()
+	sortField := plainText(fields[0])
+	b.notes = append(b.notes, sqliteRow{rowid: noteID, values: []any{
+		nil, guid(key), model, b.now.Unix(), -1, tags, strings.Join(fields, fieldSeparator), sortField, checksum(sortField), 0, "",
+	}})
+	b.cards = append(b.cards, sqliteRow{rowid: b.id(), values: []any{
+		nil, noteID, deckID, 0, b.now.Unix(), -1, 0, 0, len(b.notes), 0, 0, 0, 0, 0, 0, 0, 0, "",
+	}})
+}
+
+// sound returns the [sound:…] tag for the audio of turn, adding the file to
+// the package the first time. Turns without audio yield "".
+func (b *builder) sound(ctx context.Context, turn dialogs.DialogTurn, loadAudio AudioLoader) (string, error) {
+	if name, ok := b.media[turn.ID]; ok {
+		return soundTag(name), nil
+	}
+	data, err := loadAudio(ctx, turn)
+	if err != nil {
+		return "", fmt.Errorf("load audio of turn %s: %w", turn.ID, err)
+	}
+	name := ""
+	if len(data) > 0 {
+		name = "leveltalk-" + turn.ID.String() + ".mp3"
+		b.mediaFiles = append(b.mediaFiles, mediaFile{name: name, data: data})
+	}
+	b.media[turn.ID] = name
+	return soundTag(name), nil
+}
+
+func soundTag(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "[sound:" + name + "]"
+}
+
+func (b *builder) collection(currentDeck int64) ([]byte, error) {
+	models := map[string]any{
+		strconv.Itoa(vocabularyModelID): model(vocabularyModelID, "LevelTalk Vocabulary", b.now,
+			[]string{"Word", "Translation", "Example", "Example translation", "Audio"},
+			"{{Word}}",
+			"{{FrontSide}}\n<hr id=answer>\n{{Translation}}\n<p>{{Example}}</p>\n<p class=\"gloss\">{{Example translation}}</p>\n{{Audio}}"),
+		strconv.Itoa(sentenceModelID): model(sentenceModelID, "LevelTalk Sentence", b.now,
+			[]string{"Sentence", "Translation", "Speaker", "Audio"},
+			"{{Audio}}\n<p>{{Sentence}}</p>",
+			"{{FrontSide}}\n<hr id=answer>\n<p class=\"gloss\">{{Translation}}</p>\n<p class=\"speaker\">{{Speaker}}</p>"),
+	}
+	conf := map[string]any{
+		"nextPos": len(b.notes) + 1, "estTimes": true, "activeDecks": []int64{currentDeck}, "sortType": "noteFld",
+		"timeLim": 0, "sortBackwards": false, "addToCur": true, "curDeck": currentDeck, "newSpread": 0,
+		"dueCounts": true, "curModel": strconv.Itoa(vocabularyModelID), "collapseTime": 1200,
+	}
+
+	var colValues []any
+	for _, v := range []any{conf, models, b.decks, map[string]any{"1": deckConfig(b.now)}, map[string]any{}} {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		colValues = append(colValues, string(encoded))
+	}
+	col := append([]any{nil, b.now.Unix(), b.now.UnixMilli(), b.now.UnixMilli(), 11, 0, 0, 0}, colValues...)
+
+	return writeSQLite([]sqliteTable{
+		{name: "col", sql: colSchema, rows: []sqliteRow{{rowid: 1, values: col}}},
+		{name: "notes", sql: notesSchema, rows: b.notes},
+		{name: "cards", sql: cardsSchema, rows: b.cards},
+		{name: "revlog", sql: revlogSchema},
+		{name: "graves", sql: gravesSchema},
+	})
+}
+
+// writePackage zips the collection with the media files, which are stored
+// under their index in the "media" map.
+func (b *builder) writePackage(w io.Writer, collection []byte) error {
+	zw := zip.NewWriter(w)
+	file, err := zw.Create("collection.anki2")
+	if err != nil {
+		return fmt.Errorf("create collection entry: %w", err)
+	}
+	if _, err := file.Write(collection); err != nil {
+		return fmt.Errorf("write collection: %w", err)
+	}
+
+	index := make(map[string]string, len(b.mediaFiles))
+	for i, media := range b.mediaFiles {
+		key := strconv.Itoa(i)
+		index[key] = media.name
+		file, err := zw.Create(key)
+		if err != nil {
+			return fmt.Errorf("create media entry: %w", err)
+		}
+		if _, err := file.Write(media.data); err != nil {
+			return fmt.Errorf("write media: %w", err)
+		}
+	}
+	encoded, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("marshal media index: %w", err)
+	}
+	file, err = zw.Create("media")
+	if err != nil {
+		return fmt.Errorf("create media index entry: %w", err)
+	}
+	if _, err := file.Write(encoded); err != nil {
+		return fmt.Errorf("write media index: %w", err)
+	}
+	return zw.Close()
+}
+
+// exampleTurn returns the first turn in which word was found.
+func exampleTurn(dlg dialogs.Dialog, word string) (dialogs.DialogTurn, bool) {
+	for _, turn := range dlg.Turns {
+		for _, span := range turn.Spans {
+			if span.Word == word {
+				return turn, true
+			}
+		}
+	}
+	return dialogs.DialogTurn{}, false
+}
+
+func deckName(dlg dialogs.Dialog) string {
+	name := dlg.Title
+	if name == "" {
+		name = fmt.Sprintf("%s→%s %s", strings.ToUpper(dlg.InputLanguage), strings.ToUpper(dlg.DialogLanguage), dlg.CEFRLevel)
+	}
+	// "::" separates deck levels.
+	return strings.ReplaceAll(name, "::", ":")
+}
+
+// tags returns the space-padded tag list Anki stores on notes.
+func tags(dlg dialogs.Dialog) string {
+	list := []string{"leveltalk", dlg.DialogLanguage, dlg.CEFRLevel}
+	for i, tag := range list {
+		list[i] = strings.ReplaceAll(tag, " ", "_")
+	}
+	return " " + strings.Join(list, " ") + " "
+}
+
+// guid derives a stable note guid from key.
+func guid(key string) string {
+	sum := sha1.Sum([]byte("leveltalk:" + key))
+	return base64.RawStdEncoding.EncodeToString(sum[:])[:10]
+}
+
+// checksum is the first 32 bits of the SHA-1 of the sort field, which Anki
+// uses to find duplicates.
+func checksum(field string) int64 {
+	sum := sha1.Sum([]byte(field))
+	v, _ := strconv.ParseInt(hex.EncodeToString(sum[:4]), 16, 64)
+	return v
+}
+
+// plainText undoes the escaping of a field built from plain text.
+func plainText(field string) string {
+	return html.UnescapeString(field)
+}
+
+func deck(id int64, name string, now time.Time) map[string]any {
+	return map[string]any{
+		"id": id, "name": name, "mod": now.Unix(), "usn": -1, "desc": "", "dyn": 0, "conf": 1,
+		"collapsed": false, "browserCollapsed": false, "extendNew": 0, "extendRev": 0,
+		"newToday": []int{0, 0}, "revToday": []int{0, 0}, "lrnToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+func deckConfig(now time.Time) map[string]any {
+	return map[string]any{
+		"id": 1, "name": "Default", "mod": now.Unix(), "usn": -1, "maxTaken": 60, "autoplay": true,
+		"timer": 0, "replayq": true, "dyn": false,
+		"new":   map[string]any{"delays": []float64{1, 10}, "ints": []int{1, 4, 0}, "initialFactor": 2500, "order": 1, "perDay": 20, "bury": false},
+		"rev":   map[string]any{"perDay": 200, "ease4": 1.3, "ivlFct": 1, "maxIvl": 36500, "bury": false, "hardFactor": 1.2},
+		"lapse": map[string]any{"delays": []float64{10}, "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 1},
+	}
+}
+
+func model(id int64, name string, now time.Time, fields []string, front, back string) map[string]any {
+	flds := make([]map[string]any, len(fields))
+	for i, field := range fields {
+		flds[i] = map[string]any{"name": field, "ord": i, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{}}
+	}
+	return map[string]any{
+		"id": id, "name": name, "type": 0, "mod": now.Unix(), "usn": -1, "sortf": 0, "did": defaultDeckID,
+		"flds": flds,
+		"tmpls": []map[string]any{{
+			"name": "Card 1", "ord": 0, "qfmt": front, "afmt": back, "did": nil, "bqfmt": "", "bafmt": "",
+		}},
+		"css":       ".card { font-family: arial; font-size: 22px; text-align: center; }\n.gloss, .speaker { color: #64748b; font-size: 18px; }",
+		"latexPre":  "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"latexsvg":  false,
+		"req":       []any{[]any{0, "any", []int{0}}},
+		"tags":      []string{},
+		"vers":      []int{},
+	}
+}
+
+// Schema 11, the collection format every Anki version can import.
+const (
+	colSchema = `CREATE TABLE col (id integer primary key, crt integer not null, mod integer not null, ` +
+		`scm integer not null, ver integer not null, dty integer not null, usn integer not null, ls integer not null, ` +
+		`conf text not null, models text not null, decks text not null, dconf text not null, tags text not null)`
+	notesSchema = `CREATE TABLE notes (id integer primary key, guid text not null, mid integer not null, ` +
+		`mod integer not null, usn integer not null, tags text not null, flds text not null, sfld integer not null, ` +
+		`csum integer not null, flags integer not null, data text not null)`
+	cardsSchema = `CREATE TABLE cards (id integer primary key, nid integer not null, did integer not null, ` +
+		`ord integer not null, mod integer not null, usn integer not null, type integer not null, queue integer not null, ` +
+		`due integer not null, ivl integer not null, factor integer not null, reps integer not null, lapses integer not null, ` +
+		`left integer not null, odue integer not null, odid integer not null, flags integer not null, data text not null)`
+	revlogSchema = `CREATE TABLE revlog (id integer primary key, cid integer not null, usn integer not null, ` +
+		`ease integer not null, ivl integer not null, lastIvl integer not null, factor integer not null, ` +
+		`time integer not null, type integer not null)`
+	gravesSchema = `CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null)`
+)
@@ -0,0 +1,180 @@
+package anki
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestPutVarint(t *testing.T) {
+	require.Equal(t, []byte{0x00}, putVarint(0))
+	require.Equal(t, []byte{0x7f}, putVarint(127))
+	require.Equal(t, []byte{0x81, 0x00}, putVarint(128))
+	require.Equal(t, []byte{0xff, 0x7f}, putVarint(1<<14-1))
+	require.Len(t, putVarint(1<<56-1), 8)
+	require.Equal(t, []byte{0x80, 0xc0, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, putVarint(1<<56))
+}
+
+func TestEncodeRecord(t *testing.T) {
+	record := encodeRecord([]any{nil, 0, 1, 200, "ab", []byte{7}, 0.5})
+	require.Equal(t, []byte{
+		8, 0, 8, 9, 2, 17, 14, 7, // Header: size, then one serial type per value
+		0x00, 0xc8, 'a', 'b', 7, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0,
+	}, record)
+}
+
+func TestExportBuildsPackage(t *testing.T) {
+	turnID := uuid.New()
+	dlg := dialogs.Dialog{
+		ID:             uuid.New(),
+		Title:          "En casa",
+		InputLanguage:  "ru",
+		DialogLanguage: "es",
+		CEFRLevel:      "A2",
+		InputWords:     []string{"дом", "кошка"},
+		Translations:   map[string]string{"дом": "casa", "кошка": "gato"},
+		Turns: []dialogs.DialogTurn{
+			{ID: turnID, Speaker: "Ana", Text: "Mi casa es bonita.", Translation: "Мой дом красивый.", Spans: []dialogs.VocabSpan{{Start: 3, End: 7, Word: "дом"}}},
+			{ID: uuid.New(), Speaker: "Luis", Text: "¡Qué bien!"},
+		},
+	}
+	loads := 0
+	loadAudio := func(ctx context.Context, turn dialogs.DialogTurn) ([]byte, error) {
+		loads++
+		if turn.ID == turnID {
+			return []byte("mp3"), nil
+		}
+		return nil, nil
+	}
+
+	var buf bytes.Buffer
+	err := Export(context.Background(), &buf, []dialogs.Dialog{dlg}, loadAudio, &Options{Sentences: true, Now: time.Unix(1700000000, 0)})
+	require.NoError(t, err)
+	// The shared turn is loaded once for the word and the sentence.
+	require.Equal(t, 2, loads)
+
+	entries := unzip(t, buf.Bytes())
+
+	var media map[string]string
+	require.NoError(t, json.Unmarshal(entries["media"], &media))
+	require.Equal(t, map[string]string{"0": "leveltalk-" + turnID.String() + ".mp3"}, media)
+	require.Equal(t, []byte("mp3"), entries["0"])
+
+	collection := entries["collection.anki2"]
+	require.True(t, bytes.HasPrefix(collection, []byte("SQLite format 3\x00")))
+	require.Equal(t, len(collection), int(binary.BigEndian.Uint32(collection[28:]))*pageSize)
+	require.Contains(t, string(collection), "дом\x1fcasa\x1fMi casa es bonita.\x1fМой дом красивый.\x1f[sound:leveltalk-"+turnID.String()+".mp3]")
+	require.Contains(t, string(collection), "кошка\x1fgato\x1f\x1f\x1f")
+	require.Contains(t, string(collection), "¡Qué bien!\x1f\x1fLuis\x1f")
+	require.Contains(t, string(collection), "LevelTalk::En casa")
+}
+
+func TestWriteSQLiteSpillsLargeRowsToOverflowPages(t *testing.T) {
+	large := bytes.Repeat([]byte("x"), 3*pageSize)
+	db, err := writeSQLite([]sqliteTable{{
+		name: "t",
+		sql:  "CREATE TABLE t (id integer primary key, v blob)",
+		rows: []sqliteRow{{rowid: 2, values: []any{nil, large}}, {rowid: 1, values: []any{nil, "small"}}},
+	}})
+	require.NoError(t, err)
+	// Page 1, one leaf and the overflow chain of the large row.
+	require.Equal(t, 2+3, len(db)/pageSize)
+
+	_, err = writeSQLite([]sqliteTable{{name: "t", sql: "CREATE TABLE t (a)", rows: []sqliteRow{{rowid: 1}, {rowid: 1}}}})
+	require.ErrorContains(t, err, "duplicate rowid")
+}
+
+// TestExportOpensInSQLite reads an export back with the sqlite3 shell, which
+// checks the hand-written file format against SQLite itself.
+func TestExportOpensInSQLite(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not installed")
+	}
+
+	// Enough notes to need interior b-tree pages, and a long turn for
+	// overflow pages.
+	dlg := dialogs.Dialog{
+		ID:             uuid.New(),
+		Title:          "En casa",
+		InputLanguage:  "ru",
+		DialogLanguage: "es",
+		CEFRLevel:      "A2",
+		Translations:   map[string]string{},
+		Turns:          []dialogs.DialogTurn{{ID: uuid.New(), Speaker: "Ana", Text: strings.Repeat("Mi casa es bonita. ", 400)}},
+	}
+	for i := range 300 {
+		word := fmt.Sprintf("слово%d", i)
+		dlg.InputWords = append(dlg.InputWords, word)
+		dlg.Translations[word] = fmt.Sprintf("palabra%d", i)
+	}
+	noAudio := func(ctx context.Context, turn dialogs.DialogTurn) ([]byte, error) { return nil, nil }
+	var buf bytes.Buffer
+	err := Export(context.Background(), &buf, []dialogs.Dialog{dlg}, noAudio, &Options{Sentences: true, Now: time.Unix(1700000000, 0)})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "collection.anki2")
+	require.NoError(t, os.WriteFile(path, unzip(t, buf.Bytes())["collection.anki2"], 0o644))
+	query := func(sql string, dest any) {
+		t.Helper()
+		out, err := exec.Command("sqlite3", "-readonly", "-json", path, sql).CombinedOutput()
+		require.NoError(t, err, string(out))
+		require.NoError(t, json.Unmarshal(out, dest), string(out))
+	}
+
+	var check []struct {
+		Result string `json:"integrity_check"`
+	}
+	query("PRAGMA integrity_check", &check)
+	require.Equal(t, "ok", check[0].Result)
+
+	var notes []struct {
+		ID     int64  `json:"id"`
+		Fields string `json:"flds"`
+	}
+	query("SELECT id, flds FROM notes ORDER BY id", &notes)
+	require.Len(t, notes, 301)
+	require.Equal(t, "слово0\x1fpalabra0\x1f\x1f\x1f", notes[0].Fields)
+	require.Equal(t, "Ana\x1f", notes[300].Fields[len(notes[300].Fields)-4:])
+
+	var cards []struct {
+		NoteID int64 `json:"nid"`
+		Notes  int   `json:"notes"`
+	}
+	query("SELECT c.nid, COUNT(n.id) AS notes FROM cards c LEFT JOIN notes n ON n.id = c.nid GROUP BY c.id ORDER BY c.id", &cards)
+	require.Len(t, cards, 301)
+	for i, card := range cards {
+		require.Equal(t, notes[i].ID, card.NoteID)
+		require.Equal(t, 1, card.Notes)
+	}
+}
+
+// unzip returns the files of an .apkg by name.
+func unzip(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	entries := map[string][]byte{}
+	for _, file := range zr.File {
+		rc, err := file.Open()
+		require.NoError(t, err)
+		entries[file.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+	}
+	return entries
+}
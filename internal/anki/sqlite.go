@@ -0,0 +1,329 @@
+package anki
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// pageSize is the page size of the databases writeSQLite produces.
+const pageSize = 4096
+
+// sqliteTable is a table to lay out with writeSQLite. For tables with an
+// INTEGER PRIMARY KEY, rowid is that key and its column holds nil.
+type sqliteTable struct {
+	name string
+	sql  string
+	rows []sqliteRow
+}
+
+type sqliteRow struct {
+	rowid  int64
+	values []any // nil, int64, int, float64, string or []byte
+}
+
+// writeSQLite returns a complete SQLite 3 database file holding tables. It
+// writes only what a reader needs: table b-trees with overflow pages and the
+// schema table on page 1. There are no indexes or free pages; the whole
+// schema has to fit on page 1.
+func writeSQLite(tables []sqliteTable) ([]byte, error) {
+	p := &pager{}
+	p.alloc() // Page 1 holds the file header and the schema table.
+
+	schema := make([]cell, len(tables))
+	for i, table := range tables {
+		root, err := p.buildTable(table.rows)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.name, err)
+		}
+		schema[i] = p.tableCell(int64(i+1), encodeRecord([]any{"table", table.name, table.name, int64(root), table.sql}))
+	}
+
+	const headerSize = 100
+	if used := cellsSize(schema); used > pageSize-headerSize-8 {
+		return nil, fmt.Errorf("schema needs %d bytes but page 1 has room for %d", used, pageSize-headerSize-8)
+	}
+	writeLeaf(p.pages[0], headerSize, schema)
+	writeFileHeader(p.pages[0], len(p.pages))
+
+	out := make([]byte, 0, len(p.pages)*pageSize)
+	for _, page := range p.pages {
+		out = append(out, page...)
+	}
+	return out, nil
+}
+
+type pager struct {
+	pages [][]byte
+}
+
+// alloc appends an empty page and returns its 1-based number.
+func (p *pager) alloc() uint32 {
+	p.pages = append(p.pages, make([]byte, pageSize))
+	return uint32(len(p.pages))
+}
+
+func (p *pager) page(n uint32) []byte {
+	return p.pages[n-1]
+}
+
+type cell struct {
+	data  []byte
+	rowid int64
+}
+
+// cellsSize is the room cells take on a page, including their pointers.
+func cellsSize(cells []cell) int {
+	n := 0
+	for _, c := range cells {
+		n += len(c.data) + 2
+	}
+	return n
+}
+
+// tableCell encodes a table leaf cell, moving what does not fit on the page
+// into a chain of overflow pages.
+func (p *pager) tableCell(rowid int64, payload []byte) cell {
+	data := putVarint(uint64(len(payload)))
+	data = append(data, putVarint(uint64(rowid))...)
+
+	local := localPayload(len(payload))
+	data = append(data, payload[:local]...)
+	if local < len(payload) {
+		data = binary.BigEndian.AppendUint32(data, p.writeOverflow(payload[local:]))
+	}
+	return cell{data: data, rowid: rowid}
+}
+
+// localPayload returns how much of a payload of n bytes stays on a table
+// leaf page, following the formula of the file format.
+func localPayload(n int) int {
+	const (
+		usable   = pageSize
+		maxLocal = usable - 35
+		minLocal = (usable-12)*32/255 - 23
+	)
+	if n <= maxLocal {
+		return n
+	}
+	k := minLocal + (n-minLocal)%(usable-4)
+	if k <= maxLocal {
+		return k
+	}
+	return minLocal
+}
+
+// writeOverflow stores rest in a chain of overflow pages and returns the
+// number of the first one.
+func (p *pager) writeOverflow(rest []byte) uint32 {
+	first := p.alloc()
+	for n := first; ; {
+		page := p.page(n)
+		chunk := min(len(rest), pageSize-4)
+		copy(page[4:], rest[:chunk])
+		rest = rest[chunk:]
+		if len(rest) == 0 {
+			return first
+		}
+		next := p.alloc()
+		binary.BigEndian.PutUint32(page, next)
+		n = next
+	}
+}
+
+type child struct {
+	page     uint32
+	maxRowid int64
+}
+
+// buildTable writes the b-tree of a table and returns its root page.
+func (p *pager) buildTable(rows []sqliteRow) (uint32, error) {
+	rows = append([]sqliteRow(nil), rows...)
+	sort.Slice(rows, func(i, j int) bool { return rows[i].rowid < rows[j].rowid })
+	cells := make([]cell, len(rows))
+	for i, row := range rows {
+		if i > 0 && row.rowid == rows[i-1].rowid {
+			return 0, fmt.Errorf("duplicate rowid %d", row.rowid)
+		}
+		cells[i] = p.tableCell(row.rowid, encodeRecord(row.values))
+	}
+
+	var level []child
+	for start := 0; start < len(cells) || len(level) == 0; {
+		end, used := start, 0
+		for end < len(cells) && used+len(cells[end].data)+2 <= pageSize-8 {
+			used += len(cells[end].data) + 2
+			end++
+		}
+		n := p.alloc()
+		writeLeaf(p.page(n), 0, cells[start:end])
+		maxRowid := int64(0)
+		if end > start {
+			maxRowid = cells[end-1].rowid
+		}
+		level = append(level, child{page: n, maxRowid: maxRowid})
+		start = end
+	}
+
+	for len(level) > 1 {
+		var parents []child
+		for start := 0; start < len(level); {
+			// Every child but the last of a page needs a cell; the last one
+			// is the right-most pointer.
+			end, used := start+1, 0
+			for end < len(level) {
+				size := 4 + len(putVarint(uint64(level[end-1].maxRowid))) + 2
+				if used+size > pageSize-12 {
+					break
+				}
+				used += size
+				end++
+			}
+			n := p.alloc()
+			writeInterior(p.page(n), level[start:end])
+			parents = append(parents, child{page: n, maxRowid: level[end-1].maxRowid})
+			start = end
+		}
+		level = parents
+	}
+	return level[0].page, nil
+}
+
+// writeLeaf lays out a table leaf page whose header starts at offset.
+func writeLeaf(page []byte, offset int, cells []cell) {
+	page[offset] = 0x0d
+	content := writeCells(page, offset+8, cells)
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+}
+
+// writeInterior lays out a table interior page over children.
+func writeInterior(page []byte, children []child) {
+	last := children[len(children)-1]
+	cells := make([]cell, len(children)-1)
+	for i, c := range children[:len(children)-1] {
+		data := binary.BigEndian.AppendUint32(nil, c.page)
+		cells[i] = cell{data: append(data, putVarint(uint64(c.maxRowid))...)}
+	}
+	page[0] = 0x05
+	content := writeCells(page, 12, cells)
+	binary.BigEndian.PutUint16(page[3:], uint16(len(cells)))
+	binary.BigEndian.PutUint16(page[5:], uint16(content))
+	binary.BigEndian.PutUint32(page[8:], last.page)
+}
+
+// writeCells fills the cell pointer array at pointers and the cell content
+// from the end of the page, returning where the content starts.
+func writeCells(page []byte, pointers int, cells []cell) int {
+	content := len(page)
+	for i, c := range cells {
+		content -= len(c.data)
+		copy(page[content:], c.data)
+		binary.BigEndian.PutUint16(page[pointers+2*i:], uint16(content))
+	}
+	return content
+}
+
+func writeFileHeader(page []byte, pages int) {
+	copy(page, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(page[16:], pageSize)
+	page[18], page[19] = 1, 1                            // Rollback journal
+	page[21], page[22], page[23] = 64, 32, 32            // Payload fractions
+	binary.BigEndian.PutUint32(page[24:], 1)             // File change counter
+	binary.BigEndian.PutUint32(page[28:], uint32(pages)) // Size in pages
+	binary.BigEndian.PutUint32(page[40:], 1)             // Schema cookie
+	binary.BigEndian.PutUint32(page[44:], 4)             // Schema format
+	binary.BigEndian.PutUint32(page[56:], 1)             // UTF-8
+	binary.BigEndian.PutUint32(page[92:], 1)             // Version-valid-for
+	binary.BigEndian.PutUint32(page[96:], 3040001)       // SQLite version
+}
+
+// encodeRecord encodes values in the record format.
+func encodeRecord(values []any) []byte {
+	var types, body []byte
+	for _, v := range values {
+		if i, ok := v.(int); ok {
+			v = int64(i)
+		}
+		switch v := v.(type) {
+		case nil:
+			types = append(types, 0)
+		case int64:
+			switch {
+			case v == 0:
+				types = append(types, 8)
+			case v == 1:
+				types = append(types, 9)
+			default:
+				serial, size := intSerial(v)
+				types = append(types, putVarint(serial)...)
+				var buf [8]byte
+				binary.BigEndian.PutUint64(buf[:], uint64(v))
+				body = append(body, buf[8-size:]...)
+			}
+		case float64:
+			types = append(types, 7)
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+		case string:
+			types = append(types, putVarint(uint64(2*len(v)+13))...)
+			body = append(body, v...)
+		case []byte:
+			types = append(types, putVarint(uint64(2*len(v)+12))...)
+			body = append(body, v...)
+		default:
+			panic(fmt.Sprintf("anki: unsupported column value %T", v))
+		}
+	}
+
+	// The header size counts its own varint.
+	size := len(types) + 1
+	for len(putVarint(uint64(size)))+len(types) != size {
+		size = len(putVarint(uint64(size))) + len(types)
+	}
+	record := append(putVarint(uint64(size)), types...)
+	return append(record, body...)
+}
+
+// intSerial returns the serial type and byte size of the smallest integer
+// encoding that holds v.
+func intSerial(v int64) (uint64, int) {
+	switch {
+	case v >= math.MinInt8 && v <= math.MaxInt8:
+		return 1, 1
+	case v >= math.MinInt16 && v <= math.MaxInt16:
+		return 2, 2
+	case v >= -1<<23 && v < 1<<23:
+		return 3, 3
+	case v >= math.MinInt32 && v <= math.MaxInt32:
+		return 4, 4
+	case v >= -1<<47 && v < 1<<47:
+		return 5, 6
+	default:
+		return 6, 8
+	}
+}
+
+// putVarint encodes v as a big-endian SQLite varint of 1 to 9 bytes.
+func putVarint(v uint64) []byte {
+	if v > 1<<56-1 {
+		buf := make([]byte, 9)
+		buf[8] = byte(v)
+		v >>= 8
+		for i := 7; i >= 0; i-- {
+			buf[i] = byte(v&0x7f) | 0x80
+			v >>= 7
+		}
+		return buf
+	}
+	var buf []byte
+	for {
+		buf = append([]byte{byte(v&0x7f) | 0x80}, buf...)
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	buf[len(buf)-1] &= 0x7f
+	return buf
+}
@@ -0,0 +1,50 @@
+// Package audiostore provides dialogs.AudioStore implementations backed by the
+// local filesystem or an S3-compatible bucket.
+package audiostore
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"leveltalk/internal/config"
+	"leveltalk/internal/dialogs"
+)
+
+// FromConfig builds the AudioStore selected by cfg.AudioStore.
+func FromConfig(cfg config.Config) (dialogs.AudioStore, error) {
+	switch cfg.AudioStore {
+	case "s3":
+		return NewS3Store(S3Options{
+			Endpoint:        cfg.S3.Endpoint,
+			Region:          cfg.S3.Region,
+			Bucket:          cfg.S3.Bucket,
+			AccessKeyID:     cfg.S3.AccessKeyID,
+			SecretAccessKey: cfg.S3.SecretAccessKey,
+			PathStyle:       cfg.S3.PathStyle,
+		})
+	default:
+		return NewFSStore(cfg.AudioDir)
+	}
+}
+
+// DecodeDataURL extracts the payload of a base64 "data:" URL as produced by
+// earlier versions that inlined audio into database rows.
+func DecodeDataURL(v string) ([]byte, string, error) {
+	if !strings.HasPrefix(v, "data:") {
+		return nil, "", fmt.Errorf("not a data url")
+	}
+	meta, payload, ok := strings.Cut(strings.TrimPrefix(v, "data:"), ",")
+	if !ok {
+		return nil, "", fmt.Errorf("malformed data url")
+	}
+	contentType, encoding, _ := strings.Cut(meta, ";")
+	if encoding != "base64" {
+		return nil, "", fmt.Errorf("unsupported data url encoding %q", encoding)
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("decode data url: %w", err)
+	}
+	return data, contentType, nil
+}
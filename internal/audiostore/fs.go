@@ -0,0 +1,124 @@
+package audiostore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"leveltalk/internal/dialogs"
+)
+
+// FSStore keeps audio blobs as files below a root directory.
+type FSStore struct {
+	root string
+}
+
+// NewFSStore creates the root directory if needed and returns an FSStore.
+func NewFSStore(root string) (*FSStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create audio dir: %w", err)
+	}
+	return &FSStore{root: root}, nil
+}
+
+// Put writes data atomically under key.
+func (s *FSStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create key dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write audio: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close audio: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename audio: %w", err)
+	}
+	return nil
+}
+
+// Open returns the file stored under key.
+func (s *FSStore) Open(ctx context.Context, key string) (dialogs.AudioObject, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return dialogs.AudioObject{}, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return dialogs.AudioObject{}, dialogs.ErrAudioNotFound
+		}
+		return dialogs.AudioObject{}, fmt.Errorf("open audio: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return dialogs.AudioObject{}, fmt.Errorf("stat audio: %w", err)
+	}
+	return dialogs.AudioObject{
+		Content:     f,
+		Size:        info.Size(),
+		ContentType: contentTypeFor(key),
+		ETag:        fmt.Sprintf("\"%x-%x\"", info.ModTime().UnixNano(), info.Size()),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+// Delete removes the file stored under key. Missing files are not an error.
+func (s *FSStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete audio: %w", err)
+	}
+	return nil
+}
+
+func (s *FSStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid audio key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid audio key %q", key)
+		}
+	}
+	return nil
+}
+
+func contentTypeFor(key string) string {
+	switch strings.ToLower(filepath.Ext(key)) {
+	case ".wav":
+		return "audio/wav"
+	case ".ogg":
+		return "audio/ogg"
+	default:
+		return "audio/mpeg"
+	}
+}
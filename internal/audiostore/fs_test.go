@@ -0,0 +1,55 @@
+package audiostore
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestFSStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+
+	key := "dialogs/abc/turn.mp3"
+	require.NoError(t, store.Put(ctx, key, []byte("ID3-audio"), "audio/mpeg"))
+
+	obj, err := store.Open(ctx, key)
+	require.NoError(t, err)
+	defer obj.Content.Close()
+
+	data, err := io.ReadAll(obj.Content)
+	require.NoError(t, err)
+	require.Equal(t, "ID3-audio", string(data))
+	require.Equal(t, int64(len(data)), obj.Size)
+	require.Equal(t, "audio/mpeg", obj.ContentType)
+	require.NotEmpty(t, obj.ETag)
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Open(ctx, key)
+	require.ErrorIs(t, err, dialogs.ErrAudioNotFound)
+	require.NoError(t, store.Delete(ctx, key))
+}
+
+func TestFSStoreRejectsTraversal(t *testing.T) {
+	store, err := NewFSStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../secret.mp3", "a/../../b.mp3", "a//b.mp3"} {
+		require.Errorf(t, store.Put(context.Background(), key, []byte("x"), "audio/mpeg"), "key %q", key)
+	}
+}
+
+func TestDecodeDataURL(t *testing.T) {
+	data, contentType, err := DecodeDataURL("data:audio/mpeg;base64,SUQz")
+	require.NoError(t, err)
+	require.Equal(t, "ID3", string(data))
+	require.Equal(t, "audio/mpeg", contentType)
+
+	_, _, err = DecodeDataURL("/static/audio/placeholder.mp3?turn=1")
+	require.Error(t, err)
+}
@@ -0,0 +1,283 @@
+// Package cefr estimates the CEFR level a text is actually written at. The
+// estimate combines a few measurable features: sentence length, how much of
+// the vocabulary comes from the most frequent words of the language, lexical
+// variety and subordinate clauses. It is a heuristic meant to catch dialogs
+// that miss their requested level by a wide margin, not a placement test.
+//
+// The estimate is coarse. The bundled word lists hold only the 150 to 250
+// most frequent words of each language, enough to tell basic vocabulary from
+// the rest but not B2 words from C1 words, so expect it to be a level off.
+package cefr
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/stemmer"
+	"leveltalk/internal/textutil"
+)
+
+//go:embed wordlists/*.txt
+var wordlists embed.FS
+
+// coreBandSize is the number of top-ranked words in the core frequency band.
+// It is sized for the short bundled lists; longer lists would warrant a
+// larger core band and more bands.
+// The rest of a word list forms the second band and words missing from the
+// list the third.
+const coreBandSize = 100
+
+// Features are the measurements a level estimate is based on.
+type Features struct {
+	Sentences int
+	Tokens    int
+
+	MeanSentenceLength float64 // Words per sentence
+	MeanFrequencyBand  float64 // 0 for core words up to 2 for words off the list; NaN without a list
+	RootTTR            float64 // Distinct words over the square root of all words (Guiraud's index)
+	ClausesPerSentence float64 // Subordinate clause markers per sentence
+}
+
+// Report is the result of analyzing a text.
+type Report struct {
+	Features Features
+	Score    float64 // Continuous level from 0 (A1) to 5 (C2)
+	Level    string
+}
+
+// feature turns one measurement into a level score by linear interpolation
+// between the values typical of each level, A1 to C2.
+type feature struct {
+	weight  float64
+	anchors [6]float64
+	value   func(Features) float64
+}
+
+var features = []feature{
+	{weight: 0.3, anchors: [6]float64{4, 6, 8.5, 11.5, 15, 20}, value: func(f Features) float64 { return f.MeanSentenceLength }},
+	{weight: 0.3, anchors: [6]float64{0.45, 0.6, 0.75, 0.85, 0.95, 1.1}, value: func(f Features) float64 { return f.MeanFrequencyBand }},
+	{weight: 0.15, anchors: [6]float64{4, 5, 6, 7, 8, 9}, value: func(f Features) float64 { return f.RootTTR }},
+	{weight: 0.25, anchors: [6]float64{0.05, 0.2, 0.4, 0.7, 1, 1.4}, value: func(f Features) float64 { return f.ClausesPerSentence }},
+}
+
+// subordinators mark the start of a subordinate clause, per language.
+// Entries ending in an apostrophe match elided forms such as "qu'il".
+var subordinators = map[string][]string{
+	"de": {"dass", "weil", "obwohl", "ob", "damit", "während", "nachdem", "bevor", "sodass", "falls", "indem", "welcher", "welche", "welches"},
+	"en": {"that", "which", "who", "whom", "whose", "because", "although", "though", "while", "whereas", "unless", "if", "whenever", "until"},
+	"es": {"que", "porque", "aunque", "cuando", "si", "mientras", "donde", "cual", "cuales", "cuyo", "cuya", "pues"},
+	"fi": {"että", "koska", "kun", "jos", "vaikka", "jotta", "kunnes", "mikäli", "joka", "jonka", "jota", "jossa", "josta", "johon", "jotka", "joiden", "joita"},
+	"fr": {"que", "qu'", "qui", "parce", "quand", "lorsque", "lorsqu'", "puisque", "puisqu'", "quoique", "dont", "si"},
+	"ru": {"что", "чтобы", "потому", "поскольку", "хотя", "если", "когда", "пока", "который", "которая", "которое", "которые", "которого", "которой", "котором", "которую", "которым", "которых"},
+}
+
+type language struct {
+	ranks         map[string]int // Word key -> frequency rank, 0 for the most frequent
+	subordinators map[string]bool
+	stem          func(string) string
+}
+
+// key is the form words are looked up by in the frequency list.
+func (l *language) key(word string) string {
+	if l.stem != nil {
+		return l.stem(word)
+	}
+	return word
+}
+
+// Analyzer scores texts against the bundled word-frequency lists.
+type Analyzer struct {
+	languages map[string]*language
+}
+
+// New loads the bundled word lists. Languages with a stemmer look words up by
+// stem, so inflected forms share the rank of their most frequent form.
+func New() (*Analyzer, error) {
+	a := &Analyzer{languages: make(map[string]*language)}
+	for code, words := range subordinators {
+		lang := &language{subordinators: make(map[string]bool, len(words))}
+		for _, word := range words {
+			lang.subordinators[word] = true
+		}
+		a.languages[code] = lang
+	}
+
+	files, err := wordlists.ReadDir("wordlists")
+	if err != nil {
+		return nil, fmt.Errorf("read word lists: %w", err)
+	}
+	for _, file := range files {
+		code := strings.TrimSuffix(file.Name(), path.Ext(file.Name()))
+		lang := a.languages[code]
+		if lang == nil {
+			lang = &language{}
+			a.languages[code] = lang
+		}
+		if s, err := stemmer.New(code); err == nil {
+			lang.stem = s.Stem
+		}
+		if lang.ranks, err = readWordList(path.Join("wordlists", file.Name()), lang.key); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// readWordList ranks the words of a list by line, skipping comments.
+func readWordList(name string, key func(string) string) (map[string]int, error) {
+	f, err := wordlists.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("open word list: %w", err)
+	}
+	defer f.Close()
+
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		if _, seen := ranks[key(word)]; !seen {
+			ranks[key(word)] = len(ranks)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read word list %s: %w", name, err)
+	}
+	return ranks, nil
+}
+
+// Languages lists the language codes with a bundled word list.
+func (a *Analyzer) Languages() []string {
+	var codes []string
+	for code, lang := range a.languages {
+		if lang.ranks != nil {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Analyze measures texts, each holding one or more sentences, written in the
+// given language. Languages without a word list are scored on the remaining
+// features.
+func (a *Analyzer) Analyze(languageCode string, texts []string) Report {
+	lang := a.languages[strings.ToLower(languageCode)]
+	if lang == nil {
+		lang = &language{}
+	}
+
+	var f Features
+	var bands, clauses float64
+	types := make(map[string]bool)
+	for _, text := range texts {
+		for _, sentence := range splitSentences(text) {
+			words := splitWords(sentence)
+			if len(words) == 0 {
+				continue
+			}
+			f.Sentences++
+			f.Tokens += len(words)
+			for _, word := range words {
+				key := lang.key(word)
+				types[key] = true
+				bands += frequencyBand(lang.ranks, key)
+				if lang.subordinators[word] {
+					clauses++
+				} else if before, _, ok := strings.Cut(word, "'"); ok && lang.subordinators[before+"'"] {
+					clauses++
+				}
+			}
+		}
+	}
+	if f.Tokens == 0 {
+		return Report{Features: f, Level: dialogs.CEFRLevels[0]}
+	}
+
+	f.MeanSentenceLength = float64(f.Tokens) / float64(f.Sentences)
+	f.MeanFrequencyBand = math.NaN()
+	if lang.ranks != nil {
+		f.MeanFrequencyBand = bands / float64(f.Tokens)
+	}
+	f.RootTTR = float64(len(types)) / math.Sqrt(float64(f.Tokens))
+	f.ClausesPerSentence = clauses / float64(f.Sentences)
+
+	score := scoreFeatures(f)
+	return Report{Features: f, Score: score, Level: dialogs.CEFRLevels[int(math.Round(score))]}
+}
+
+// EstimateLevel returns the CEFR level texts appear to be written at.
+func (a *Analyzer) EstimateLevel(languageCode string, texts []string) string {
+	return a.Analyze(languageCode, texts).Level
+}
+
+// scoreFeatures averages the level scores of the measured features.
+func scoreFeatures(f Features) float64 {
+	var sum, weights float64
+	for _, feat := range features {
+		v := feat.value(f)
+		if math.IsNaN(v) {
+			continue
+		}
+		sum += feat.weight * interpolate(v, feat.anchors)
+		weights += feat.weight
+	}
+	return sum / weights
+}
+
+// interpolate maps v onto 0..5 between the anchors, clamping outside them.
+func interpolate(v float64, anchors [6]float64) float64 {
+	if v <= anchors[0] {
+		return 0
+	}
+	for i := 1; i < len(anchors); i++ {
+		if v <= anchors[i] {
+			return float64(i-1) + (v-anchors[i-1])/(anchors[i]-anchors[i-1])
+		}
+	}
+	return float64(len(anchors) - 1)
+}
+
+func frequencyBand(ranks map[string]int, key string) float64 {
+	rank, ok := ranks[key]
+	switch {
+	case !ok:
+		return 2
+	case rank < coreBandSize:
+		return 0
+	default:
+		return 1
+	}
+}
+
+// splitSentences cuts text after sentence-final punctuation.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i, r := range text {
+		switch r {
+		case '.', '!', '?', '…', '。', '！', '？':
+			sentences = append(sentences, text[start:i])
+			start = i + len(string(r))
+		}
+	}
+	return append(sentences, text[start:])
+}
+
+// splitWords returns the lowercase words of a sentence, as textutil.Words
+// splits them, with typographic apostrophes folded to ASCII ("don't", "qu'il").
+func splitWords(sentence string) []string {
+	var words []string
+	for _, span := range textutil.Words(sentence) {
+		words = append(words, strings.ReplaceAll(strings.ToLower(sentence[span.Start:span.End]), "’", "'"))
+	}
+	return words
+}
@@ -0,0 +1,62 @@
+package cefr
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	spanishA1 = []string{
+		"Hola, Luis. ¿Cómo estás?",
+		"Bien, gracias. ¿Y tú?",
+		"Muy bien. Tengo un perro nuevo.",
+		"¡Qué bonito! ¿Cómo se llama?",
+		"Se llama Toby. Es pequeño.",
+		"Me gusta mucho tu perro.",
+	}
+	spanishC1 = []string{
+		"Aunque la propuesta parecía razonable, el ayuntamiento decidió aplazar la votación hasta que se publicaran los informes técnicos.",
+		"Sospecho que esa demora responde más a cálculos electorales que a verdaderas dudas sobre la viabilidad del proyecto.",
+		"Es posible, pero no descartaría que los vecinos, cuyas quejas se han multiplicado, hayan presionado para que se revisara el presupuesto.",
+		"En cualquier caso, convendría que la oposición exigiera una rendición de cuentas detallada antes de que se comprometan más fondos públicos.",
+		"Estoy de acuerdo, siempre que el debate no se convierta en un enfrentamiento estéril que paralice cualquier iniciativa.",
+		"Precisamente por eso insisto en que la transparencia resulta imprescindible si queremos recuperar la confianza ciudadana.",
+	}
+)
+
+func TestAnalyzeSeparatesLevels(t *testing.T) {
+	a, err := New()
+	require.NoError(t, err)
+
+	easy := a.Analyze("es", spanishA1)
+	require.Equal(t, "A1", easy.Level)
+	require.Less(t, easy.Features.MeanSentenceLength, 4.0)
+	require.Zero(t, easy.Features.ClausesPerSentence)
+
+	hard := a.Analyze("es", spanishC1)
+	require.Equal(t, "C1", hard.Level)
+	require.Greater(t, hard.Features.MeanFrequencyBand, easy.Features.MeanFrequencyBand)
+	require.Greater(t, hard.Features.RootTTR, easy.Features.RootTTR)
+}
+
+func TestAnalyzeWithoutWordList(t *testing.T) {
+	a, err := New()
+	require.NoError(t, err)
+	require.Equal(t, []string{"de", "en", "es", "fi", "fr", "ru"}, a.Languages())
+
+	report := a.Analyze("sv", []string{"Hej! Hur mår du?", "Bra, tack."})
+	require.True(t, math.IsNaN(report.Features.MeanFrequencyBand))
+	require.Equal(t, 3, report.Features.Sentences)
+	require.Equal(t, "A1", report.Level)
+
+	require.Equal(t, "A1", a.EstimateLevel("es", nil))
+}
+
+func TestInterpolate(t *testing.T) {
+	anchors := [6]float64{0, 10, 20, 30, 40, 50}
+	require.Equal(t, 0.0, interpolate(-5, anchors))
+	require.Equal(t, 1.5, interpolate(15, anchors))
+	require.Equal(t, 5.0, interpolate(80, anchors))
+}
@@ -2,18 +2,83 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds runtime configuration.
 type Config struct {
 	Port             string
 	DBDSN            string
+	LLMProvider      string // Registered llm provider: "stub", "openai", "anthropic", "ollama", "openai_compatible", ...; see defaultLLMProvider
 	LLMAPIKey        string
 	LLMModel         string
+	LLMBaseURL       string        // API root such as http://localhost:11434/v1; empty uses the provider default
+	LLMTimeout       time.Duration // Zero uses the provider default
+	LLMJSONMode      *bool         // Overrides whether response_format is sent; nil keeps the provider default
+	LLMStream        *bool         // Overrides whether completions are streamed; nil keeps the provider default
+	LLMMaxRepairs    int           // Follow-up requests allowed for an invalid dialog; 0 disables repairs
+	LLMMaxTokens     int           // Completion token cap; zero uses the client default
+	TTSProvider      string        // "stub", "elevenlabs" or "local"; see defaultTTSProvider
 	ElevenLabsAPIKey string
 	ElevenLabsVoice  string
+	ElevenLabsSpeed  float64 // Speaking rate between 0.7 and 1.2; 0 keeps the provider default
+	VoiceCatalog     string  // Path to a JSON voice catalog; empty uses ElevenLabsVoice for everyone
+	TTSConcurrency   int     // Turns synthesized in parallel per dialog
+	TTSRateLimit     float64 // TTS requests per second across all dialogs; 0 disables limiting
+	TTSRateBurst     int
+	TTSMaxRetries    int // Retries per turn for transient failures; 0 disables retries
 	BasePath         string
+
+	Workers        int
+	JobMaxAttempts int
+
+	LLMFallback LLMFallbackConfig
+
+	CoverageThreshold     float64 // Share of input words a dialog must use; 0 disables the check
+	CoverageRegenerations int     // Regenerations for dialogs below the threshold; 0 only flags them
+
+	LevelRegenerations int // Regenerations for dialogs estimated more than one CEFR level off; 0 only flags them
+
+	LocalTTS LocalTTSConfig
+
+	TTSCache         string // "", "dir" or "postgres"
+	TTSCacheDir      string
+	TTSCacheMaxBytes int64
+
+	AudioStore string // "fs" or "s3"
+	AudioDir   string
+	S3         S3Config
+}
+
+// LLMFallbackConfig describes a secondary LLM provider used when the primary
+// one fails. Timeout and JSON/stream overrides are shared with the primary.
+type LLMFallbackConfig struct {
+	Provider string // Empty disables the fallback
+	APIKey   string
+	Model    string
+	BaseURL  string
+}
+
+// LocalTTSConfig describes an offline TTS engine run as a subprocess.
+type LocalTTSConfig struct {
+	Engine string            // "piper" or "espeak-ng"
+	Binary string            // Engine executable; empty looks up the engine name on PATH
+	FFmpeg string            // ffmpeg executable used to encode MP3
+	Voices map[string]string // Dialog language -> Piper model path or espeak-ng voice
+}
+
+// S3Config describes an S3-compatible bucket used for audio storage.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PathStyle       bool
 }
 
 // Load parses environment variables into Config and validates required values.
@@ -21,23 +86,208 @@ func Load() (Config, error) {
 	cfg := Config{
 		Port:             getEnv("PORT", "8080"),
 		DBDSN:            os.Getenv("DB_DSN"),
+		LLMProvider:      getEnv("LLM_PROVIDER", defaultLLMProvider()),
 		LLMAPIKey:        os.Getenv("LLM_API_KEY"),
 		LLMModel:         os.Getenv("LLM_MODEL"),
+		LLMBaseURL:       os.Getenv("LLM_BASE_URL"),
+		LLMTimeout:       time.Duration(getEnvInt("LLM_TIMEOUT_SECONDS", 0)) * time.Second,
+		LLMJSONMode:      getEnvBool("LLM_JSON_MODE"),
+		LLMStream:        getEnvBool("LLM_STREAM"),
+		LLMMaxRepairs:    getEnvNonNegativeInt("LLM_MAX_REPAIRS", 2),
+		LLMMaxTokens:     getEnvInt("LLM_MAX_TOKENS", 0),
+		TTSProvider:      getEnv("TTS_PROVIDER", defaultTTSProvider()),
 		ElevenLabsAPIKey: os.Getenv("ELEVENLABS_API_KEY"),
 		ElevenLabsVoice:  os.Getenv("ELEVENLABS_VOICE_ID"),
+		ElevenLabsSpeed:  getEnvFloat("ELEVENLABS_SPEED", 0),
+		VoiceCatalog:     os.Getenv("VOICE_CATALOG"),
+		TTSConcurrency:   getEnvInt("TTS_CONCURRENCY", 4),
+		TTSRateLimit:     getEnvFloat("TTS_RATE_LIMIT", 2),
+		TTSRateBurst:     getEnvInt("TTS_RATE_BURST", 4),
+		TTSMaxRetries:    getEnvNonNegativeInt("TTS_MAX_RETRIES", 3),
 		BasePath:         getEnv("BASE_PATH", ""),
+		Workers:          getEnvInt("WORKERS", 2),
+		JobMaxAttempts:   getEnvInt("JOB_MAX_ATTEMPTS", 3),
+
+		CoverageThreshold:     getEnvFloat("COVERAGE_THRESHOLD", 0.8),
+		CoverageRegenerations: getEnvNonNegativeInt("COVERAGE_REGENERATIONS", 1),
+
+		LevelRegenerations: getEnvInt("LEVEL_REGENERATIONS", 0),
+
+		LLMFallback: LLMFallbackConfig{
+			Provider: os.Getenv("LLM_FALLBACK_PROVIDER"),
+			APIKey:   os.Getenv("LLM_FALLBACK_API_KEY"),
+			Model:    os.Getenv("LLM_FALLBACK_MODEL"),
+			BaseURL:  os.Getenv("LLM_FALLBACK_BASE_URL"),
+		},
+		LocalTTS: LocalTTSConfig{
+			Engine: getEnv("LOCAL_TTS_ENGINE", "piper"),
+			Binary: os.Getenv("LOCAL_TTS_BINARY"),
+			FFmpeg: getEnv("LOCAL_TTS_FFMPEG", "ffmpeg"),
+			Voices: parseLanguageMap(os.Getenv("LOCAL_TTS_VOICES")),
+		},
+		TTSCache:         os.Getenv("TTS_CACHE"),
+		TTSCacheDir:      getEnv("TTS_CACHE_DIR", "data/tts-cache"),
+		TTSCacheMaxBytes: int64(getEnvInt("TTS_CACHE_MAX_MB", 1024)) << 20,
+		AudioStore:       getEnv("AUDIO_STORE", "fs"),
+		AudioDir:         getEnv("AUDIO_DIR", "data/audio"),
+		S3: S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          getEnv("S3_REGION", "us-east-1"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       getEnv("S3_PATH_STYLE", "true") == "true",
+		},
 	}
 
 	if cfg.DBDSN == "" {
 		return Config{}, errors.New("DB_DSN is required")
 	}
 
+	if err := validateLLM("LLM", cfg.LLMProvider, cfg.LLMAPIKey, cfg.LLMModel, cfg.LLMBaseURL); err != nil {
+		return Config{}, err
+	}
+	if cfg.LLMFallback.Provider != "" {
+		fb := cfg.LLMFallback
+		if err := validateLLM("LLM_FALLBACK", fb.Provider, fb.APIKey, fb.Model, fb.BaseURL); err != nil {
+			return Config{}, err
+		}
+	}
+
+	switch cfg.TTSProvider {
+	case "stub":
+	case "elevenlabs":
+		if cfg.ElevenLabsAPIKey == "" {
+			return Config{}, errors.New("ELEVENLABS_API_KEY is required when TTS_PROVIDER=elevenlabs")
+		}
+		if cfg.ElevenLabsVoice == "" && cfg.VoiceCatalog == "" {
+			return Config{}, errors.New("ELEVENLABS_VOICE_ID or VOICE_CATALOG is required when TTS_PROVIDER=elevenlabs")
+		}
+		if cfg.ElevenLabsSpeed != 0 && (cfg.ElevenLabsSpeed < 0.7 || cfg.ElevenLabsSpeed > 1.2) {
+			return Config{}, errors.New("ELEVENLABS_SPEED must be between 0.7 and 1.2")
+		}
+	case "local":
+		if cfg.LocalTTS.Engine != "piper" && cfg.LocalTTS.Engine != "espeak-ng" {
+			return Config{}, errors.New("LOCAL_TTS_ENGINE must be one of: piper, espeak-ng")
+		}
+		if cfg.LocalTTS.Engine == "piper" && len(cfg.LocalTTS.Voices) == 0 {
+			return Config{}, errors.New("LOCAL_TTS_VOICES is required when LOCAL_TTS_ENGINE=piper")
+		}
+	default:
+		return Config{}, errors.New("TTS_PROVIDER must be one of: stub, elevenlabs, local")
+	}
+
+	switch cfg.TTSCache {
+	case "", "dir", "postgres":
+	default:
+		return Config{}, errors.New("TTS_CACHE must be empty or one of: dir, postgres")
+	}
+
+	switch cfg.AudioStore {
+	case "fs":
+	case "s3":
+		if cfg.S3.Endpoint == "" || cfg.S3.Bucket == "" {
+			return Config{}, errors.New("S3_ENDPOINT and S3_BUCKET are required when AUDIO_STORE=s3")
+		}
+	default:
+		return Config{}, errors.New("AUDIO_STORE must be one of: fs, s3")
+	}
+
 	return cfg, nil
 }
 
+// defaultLLMProvider is used when LLM_PROVIDER is unset: OpenAI when an API
+// key and model are configured, as before providers were selectable, and the
+// stub otherwise.
+func defaultLLMProvider() string {
+	if os.Getenv("LLM_API_KEY") != "" && os.Getenv("LLM_MODEL") != "" {
+		return "openai"
+	}
+	return "stub"
+}
+
+// defaultTTSProvider is used when TTS_PROVIDER is unset: ElevenLabs when an
+// API key and a voice or voice catalog are configured, and the stub
+// otherwise.
+func defaultTTSProvider() string {
+	if os.Getenv("ELEVENLABS_API_KEY") != "" && (os.Getenv("ELEVENLABS_VOICE_ID") != "" || os.Getenv("VOICE_CATALOG") != "") {
+		return "elevenlabs"
+	}
+	return "stub"
+}
+
+// validateLLM checks the settings of one LLM provider; prefix is the
+// environment variable prefix the values were read from.
+func validateLLM(prefix, provider, apiKey, model, baseURL string) error {
+	switch provider {
+	case "stub":
+	case "openai", "anthropic":
+		if apiKey == "" || model == "" {
+			return fmt.Errorf("%s_API_KEY and %s_MODEL are required when %s_PROVIDER=%s", prefix, prefix, prefix, provider)
+		}
+	case "openai_compatible":
+		if baseURL == "" {
+			return fmt.Errorf("%s_BASE_URL is required when %s_PROVIDER=openai_compatible", prefix, prefix)
+		}
+		fallthrough
+	default:
+		// Other providers are validated by their registry factory.
+		if model == "" {
+			return fmt.Errorf("%s_MODEL is required unless %s_PROVIDER=stub", prefix, prefix)
+		}
+	}
+	return nil
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// getEnvNonNegativeInt is getEnvInt for settings where zero turns a feature
+// off rather than selecting the default.
+func getEnvNonNegativeInt(key string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value >= 0 {
+		return value
+	}
+	return fallback
+}
+
+// getEnvBool returns nil when key is unset or not a boolean.
+func getEnvBool(key string) *bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && value >= 0 {
+		return value
+	}
+	return fallback
+}
+
+// parseLanguageMap parses "es=/models/es.onnx,fi=/models/fi.onnx".
+func parseLanguageMap(v string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(v, ",") {
+		lang, value, ok := strings.Cut(pair, "=")
+		lang, value = strings.TrimSpace(lang), strings.TrimSpace(value)
+		if !ok || lang == "" || value == "" {
+			continue
+		}
+		result[strings.ToLower(lang)] = value
+	}
+	return result
+}
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setBaseEnv sets the required variables and clears those the tests vary,
+// so the host environment does not leak in.
+func setBaseEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("DB_DSN", "postgres://localhost/leveltalk")
+	for _, key := range []string{"LLM_PROVIDER", "LLM_API_KEY", "LLM_MODEL", "TTS_PROVIDER", "ELEVENLABS_API_KEY", "ELEVENLABS_VOICE_ID", "ELEVENLABS_SPEED", "VOICE_CATALOG"} {
+		t.Setenv(key, "")
+	}
+}
+
+func TestLoadDetectsLLMProvider(t *testing.T) {
+	setBaseEnv(t)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, "stub", cfg.LLMProvider)
+
+	t.Setenv("LLM_API_KEY", "sk-test")
+	t.Setenv("LLM_MODEL", "gpt-4o-mini")
+	cfg, err = Load()
+	require.NoError(t, err)
+	require.Equal(t, "openai", cfg.LLMProvider)
+
+	t.Setenv("LLM_PROVIDER", "stub")
+	cfg, err = Load()
+	require.NoError(t, err)
+	require.Equal(t, "stub", cfg.LLMProvider)
+}
+
+func TestLoadDetectsTTSProvider(t *testing.T) {
+	setBaseEnv(t)
+
+	t.Setenv("ELEVENLABS_API_KEY", "el-test")
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, "stub", cfg.TTSProvider)
+
+	t.Setenv("ELEVENLABS_VOICE_ID", "EXAVITQu4vr4xnSDxMaL")
+	cfg, err = Load()
+	require.NoError(t, err)
+	require.Equal(t, "elevenlabs", cfg.TTSProvider)
+
+	t.Setenv("TTS_PROVIDER", "stub")
+	cfg, err = Load()
+	require.NoError(t, err)
+	require.Equal(t, "stub", cfg.TTSProvider)
+}
+
+func TestGetEnvNonNegativeInt(t *testing.T) {
+	for value, want := range map[string]int{"": 2, "0": 0, "5": 5, "-1": 2, "two": 2} {
+		t.Setenv("LEVELTALK_TEST_INT", value)
+		require.Equal(t, want, getEnvNonNegativeInt("LEVELTALK_TEST_INT", 2), "value %q", value)
+	}
+}
+
+func TestLoadZeroMaxRepairs(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("LLM_MAX_REPAIRS", "0")
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Zero(t, cfg.LLMMaxRepairs)
+}
+
+func TestLoadZeroCoverageRegenerations(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("COVERAGE_REGENERATIONS", "0")
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Zero(t, cfg.CoverageRegenerations)
+}
+
+func TestLoadZeroMaxRetries(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("TTS_MAX_RETRIES", "0")
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Zero(t, cfg.TTSMaxRetries)
+}
+
+func TestLoadElevenLabsSpeed(t *testing.T) {
+	setBaseEnv(t)
+	t.Setenv("ELEVENLABS_API_KEY", "el-test")
+	t.Setenv("ELEVENLABS_VOICE_ID", "EXAVITQu4vr4xnSDxMaL")
+
+	t.Setenv("ELEVENLABS_SPEED", "1.1")
+	cfg, err := Load()
+	require.NoError(t, err)
+	require.Equal(t, 1.1, cfg.ElevenLabsSpeed)
+
+	t.Setenv("ELEVENLABS_SPEED", "2")
+	_, err = Load()
+	require.ErrorContains(t, err, "ELEVENLABS_SPEED")
+}
@@ -0,0 +1,105 @@
+package dialogs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+)
+
+// countingTTS wraps the stub TTS and counts synthesized turns.
+type countingTTS struct {
+	dialogs.TTSClient
+	turns int
+}
+
+func (c *countingTTS) SynthesizeDialog(ctx context.Context, dlg dialogs.Dialog) (dialogs.Dialog, error) {
+	c.turns += len(dlg.Turns)
+	for i := range dlg.Turns {
+		dlg.Turns[i].Audio = []byte("mp3")
+	}
+	return dlg, nil
+}
+
+func TestContinueDialogAppendsTurns(t *testing.T) {
+	ctx := context.Background()
+	repo, audio := newMemoryRepo(), newMemoryAudio()
+	speech := &countingTTS{}
+	llmClient := &recordingLLM{LLMClient: llm.NewStubClient(testLogger())}
+	svc := dialogs.NewService(repo, llmClient, speech, audio, nil)
+
+	source, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	before := len(source.Turns)
+	speech.turns = 0
+
+	dlg, err := svc.ContinueDialog(ctx, source.ID, 3)
+	require.NoError(t, err)
+	require.Len(t, dlg.Turns, before+3)
+	require.Equal(t, 3, speech.turns)
+
+	sent := llmClient.params[1]
+	require.Equal(t, 3, sent.NewTurns)
+	require.Len(t, sent.Prior, before)
+
+	for i, turn := range dlg.Turns {
+		require.Equal(t, i, turn.Position)
+	}
+	for _, turn := range dlg.Turns[before:] {
+		require.True(t, turn.HasStoredAudio())
+	}
+	// The existing turns keep their audio untouched.
+	require.Equal(t, source.Turns[0].AudioKey, dlg.Turns[0].AudioKey)
+	require.NotEqual(t, dlg.Turns[before-1].Speaker, dlg.Turns[before].Speaker)
+
+	stored, err := repo.GetByID(ctx, source.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Turns, before+3)
+
+	_, err = svc.ContinueDialog(ctx, source.ID, dialogs.MaxContinueTurns+1)
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+}
+
+func TestEnqueueContinueRunsAsJob(t *testing.T) {
+	ctx := context.Background()
+	estimator := &scriptedEstimator{levels: []string{"A2", "B1"}}
+	svc, repo, jobs := newTestService(&dialogs.ServiceOptions{LevelEstimator: estimator})
+	source, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	require.Equal(t, "A2", source.EstimatedLevel)
+
+	_, err = svc.EnqueueContinue(ctx, source.ID, dialogs.MaxContinueTurns+1)
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+
+	job, err := svc.EnqueueContinue(ctx, source.ID, 0)
+	require.NoError(t, err)
+	require.Equal(t, dialogs.JobContinue, job.Kind)
+	require.Equal(t, source.ID, *job.ParentID)
+	require.Equal(t, dialogs.DefaultContinueTurns, job.NewTurns)
+
+	_, events, cancel := svc.Subscribe(job.ID)
+	defer cancel()
+	require.NoError(t, svc.ProcessNextJob(ctx))
+
+	var types []dialogs.EventType
+	for ev := range events {
+		types = append(types, ev.Type)
+	}
+	require.Equal(t, dialogs.EventPersisted, types[len(types)-1])
+	require.Contains(t, types, dialogs.EventTurnSynthesized)
+
+	done, err := jobs.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, dialogs.JobDone, done.State)
+	require.Equal(t, source.ID, *done.DialogID)
+
+	stored, err := repo.GetByID(ctx, source.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Turns, len(source.Turns)+dialogs.DefaultContinueTurns)
+	// The level is estimated again over the whole dialog.
+	require.Equal(t, "B1", stored.EstimatedLevel)
+	require.Equal(t, len(stored.Turns), estimator.texts)
+}
@@ -0,0 +1,141 @@
+package dialogs
+
+import (
+	"sort"
+	"strings"
+
+	"leveltalk/internal/textutil"
+)
+
+// VocabSpan marks where a turn realizes one of the input words.
+type VocabSpan struct {
+	Start int    `json:"start"` // Byte offset into DialogTurn.Text
+	End   int    `json:"end"`   // Byte offset just past the match
+	Word  string `json:"word"`  // Input word the span realizes
+}
+
+// CoverageReport tells which input words a dialog actually uses.
+type CoverageReport struct {
+	Matched []string
+	Missing []string
+	Spans   [][]VocabSpan // Per turn, in turn order
+}
+
+// Ratio returns the share of input words found in the turns. A dialog
+// without input words is fully covered.
+func (r CoverageReport) Ratio() float64 {
+	total := len(r.Matched) + len(r.Missing)
+	if total == 0 {
+		return 1
+	}
+	return float64(len(r.Matched)) / float64(total)
+}
+
+type token struct {
+	key        string // Lowercased and stemmed form used for matching
+	start, end int
+}
+
+// tokenize splits text into words with textutil.Words and keys each word
+// with stem when set.
+func tokenize(text string, stem Stemmer) []token {
+	var tokens []token
+	for _, span := range textutil.Words(text) {
+		key := strings.ToLower(text[span.Start:span.End])
+		if stem != nil {
+			key = stem.Stem(key)
+		}
+		tokens = append(tokens, token{key: key, start: span.Start, end: span.End})
+	}
+	return tokens
+}
+
+// VerifyCoverage looks for every input word of dlg in its turns. A word
+// counts as used when its translation appears in some turn; stem, when not
+// nil, lets inflected forms match ("casas" for "casa"). A translation such as
+// "casa / hogar" matches through any alternative, and a multi-word one may
+// match through its content words alone. Words without a translation are
+// looked up as given, which covers cognates and same-language dialogs.
+func VerifyCoverage(dlg Dialog, stem Stemmer) CoverageReport {
+	turns := make([][]token, len(dlg.Turns))
+	for i, turn := range dlg.Turns {
+		turns[i] = tokenize(turn.Text, stem)
+	}
+
+	report := CoverageReport{Spans: make([][]VocabSpan, len(dlg.Turns))}
+	for _, word := range dlg.InputWords {
+		word = strings.TrimSpace(word)
+		found := false
+		for _, candidate := range candidates(word, dlg.Translations[word], stem) {
+			for i, tokens := range turns {
+				for _, span := range findSequence(tokens, candidate) {
+					report.Spans[i] = append(report.Spans[i], VocabSpan{Start: span[0], End: span[1], Word: word})
+					found = true
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if found {
+			report.Matched = append(report.Matched, word)
+		} else {
+			report.Missing = append(report.Missing, word)
+		}
+	}
+	for _, spans := range report.Spans {
+		sort.Slice(spans, func(a, b int) bool { return spans[a].Start < spans[b].Start })
+	}
+	return report
+}
+
+// candidates lists the token sequences that realize word, best first.
+func candidates(word, translation string, stem Stemmer) [][]string {
+	var alternatives []string
+	for _, alt := range strings.FieldsFunc(translation, func(r rune) bool { return r == '/' || r == ',' || r == ';' }) {
+		if alt = strings.TrimSpace(alt); alt != "" {
+			alternatives = append(alternatives, alt)
+		}
+	}
+	if len(alternatives) == 0 {
+		alternatives = []string{word}
+	}
+
+	var result [][]string
+	var reduced [][]string
+	for _, alt := range alternatives {
+		var keys, content []string
+		for _, tok := range tokenize(alt, stem) {
+			keys = append(keys, tok.key)
+			if textutil.IsContentWord(alt[tok.start:tok.end]) {
+				content = append(content, tok.key)
+			}
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		result = append(result, keys)
+		if len(content) > 0 && len(content) < len(keys) {
+			reduced = append(reduced, content)
+		}
+	}
+	return append(result, reduced...)
+}
+
+// findSequence returns the byte ranges where keys occur consecutively.
+func findSequence(tokens []token, keys []string) [][2]int {
+	var spans [][2]int
+	for i := 0; i+len(keys) <= len(tokens); i++ {
+		match := true
+		for j, key := range keys {
+			if tokens[i+j].key != key {
+				match = false
+				break
+			}
+		}
+		if match {
+			spans = append(spans, [2]int{tokens[i].start, tokens[i+len(keys)-1].end})
+		}
+	}
+	return spans
+}
@@ -0,0 +1,91 @@
+package dialogs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/stemmer"
+	"leveltalk/internal/tts"
+)
+
+func TestVerifyCoverageMatchesInflectedForms(t *testing.T) {
+	es, err := stemmer.New("es")
+	require.NoError(t, err)
+
+	dlg := dialogs.Dialog{
+		InputWords:   []string{"дом", "книга", "собака", "кот"},
+		Translations: map[string]string{"дом": "casa", "книга": "el libro", "собака": "perro / can", "кот": "gato"},
+		Turns: []dialogs.DialogTurn{
+			{Text: "¿Cuántas casas hay en la calle?"},
+			{Text: "Tres. Leo libros con mi can."},
+		},
+	}
+
+	report := dialogs.VerifyCoverage(dlg, es)
+	require.Equal(t, []string{"дом", "книга", "собака"}, report.Matched)
+	require.Equal(t, []string{"кот"}, report.Missing)
+	require.InDelta(t, 0.75, report.Ratio(), 0.001)
+
+	require.Equal(t, []dialogs.VocabSpan{{Start: 11, End: 16, Word: "дом"}}, report.Spans[0])
+	text := dlg.Turns[1].Text
+	require.Len(t, report.Spans[1], 2)
+	require.Equal(t, "libros", text[report.Spans[1][0].Start:report.Spans[1][0].End])
+	require.Equal(t, "can", text[report.Spans[1][1].Start:report.Spans[1][1].End])
+
+	// Without a stemmer only exact forms count.
+	require.Equal(t, []string{"собака"}, dialogs.VerifyCoverage(dlg, nil).Matched)
+}
+
+// scriptedLLM returns its dialogs in order, repeating the last one.
+type scriptedLLM struct {
+	dialogs []dialogs.Dialog
+	calls   int
+}
+
+func (s *scriptedLLM) GenerateDialog(ctx context.Context, params dialogs.GenerateDialogParams) (dialogs.Dialog, error) {
+	dlg := s.dialogs[min(s.calls, len(s.dialogs)-1)]
+	s.calls++
+	return dlg, nil
+}
+
+func TestCreateDialogRegeneratesWhenVocabularyIsMissing(t *testing.T) {
+	missing := dialogs.Dialog{
+		Title:        "Sin perro",
+		Translations: map[string]string{"casa": "casa", "perro": "perro"},
+		Turns:        []dialogs.DialogTurn{{Speaker: "Ana", Text: "Mi casa."}, {Speaker: "Luis", Text: "Bonita."}},
+	}
+	covered := missing
+	covered.Turns = []dialogs.DialogTurn{{Speaker: "Ana", Text: "Mi casa."}, {Speaker: "Luis", Text: "Y mis perros."}}
+
+	for name, tc := range map[string]struct {
+		script  []dialogs.Dialog
+		calls   int
+		flagged bool
+	}{
+		"regenerated": {script: []dialogs.Dialog{missing, covered}, calls: 2},
+		"flagged":     {script: []dialogs.Dialog{missing}, calls: 2, flagged: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			es, err := stemmer.New("es")
+			require.NoError(t, err)
+			llmClient := &scriptedLLM{dialogs: tc.script}
+			svc := dialogs.NewService(newMemoryRepo(), llmClient, tts.NewStubClient(), newMemoryAudio(), &dialogs.ServiceOptions{
+				Stemmers:              map[string]dialogs.Stemmer{"es": es},
+				CoverageThreshold:     1,
+				CoverageRegenerations: 1,
+			})
+
+			dlg, err := svc.CreateDialog(context.Background(), testInput)
+			require.NoError(t, err)
+			require.Equal(t, tc.calls, llmClient.calls)
+			require.Equal(t, tc.flagged, dlg.CoverageFlagged)
+			if !tc.flagged {
+				require.Equal(t, 1.0, dlg.Coverage)
+				require.Equal(t, []dialogs.VocabSpan{{Start: 6, End: 12, Word: "perro"}}, dlg.Turns[1].Spans)
+			}
+		})
+	}
+}
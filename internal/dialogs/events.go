@@ -0,0 +1,164 @@
+package dialogs
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names a step of dialog generation.
+type EventType string
+
+const (
+	EventPromptSent      EventType = "prompt_sent"
+	EventTurnGenerated   EventType = "turn_generated"
+	EventTurnsParsed     EventType = "turns_parsed"
+	EventTurnSynthesized EventType = "turn_synthesized"
+	EventPersisted       EventType = "persisted"
+	EventFailed          EventType = "failed"
+)
+
+// Terminal reports whether no further events follow for the job.
+func (t EventType) Terminal() bool {
+	return t == EventPersisted || t == EventFailed
+}
+
+// Event reports progress of a generation job. Fields beyond Type, JobID, Seq
+// and At are populated depending on the event type.
+type Event struct {
+	Type      EventType  `json:"type"`
+	JobID     uuid.UUID  `json:"job_id"`
+	Seq       int        `json:"seq"` // Increases by one per published event of the job
+	At        time.Time  `json:"at"`
+	Title     string     `json:"title,omitempty"`      // turns_parsed
+	TurnCount int        `json:"turn_count,omitempty"` // turns_parsed, turn_synthesized
+	Turn      *EventTurn `json:"turn,omitempty"`       // turn_generated, turn_synthesized
+	DialogID  *uuid.UUID `json:"dialog_id,omitempty"`  // persisted
+	Error     string     `json:"error,omitempty"`      // failed
+	Retrying  bool       `json:"retrying,omitempty"`   // failed
+}
+
+// EventTurn is the part of a turn that progress events carry.
+type EventTurn struct {
+	ID          *uuid.UUID `json:"id,omitempty"` // Unset until the turn is stored
+	Speaker     string     `json:"speaker"`
+	Text        string     `json:"text"`
+	Position    int        `json:"position"`
+	Translation string     `json:"translation,omitempty"`
+}
+
+func eventTurn(turn DialogTurn) *EventTurn {
+	ev := &EventTurn{Speaker: turn.Speaker, Text: turn.Text, Position: turn.Position, Translation: turn.Translation}
+	if turn.ID != uuid.Nil {
+		ev.ID = &turn.ID
+	}
+	return ev
+}
+
+const (
+	subscriberBuffer = 64
+	historyTTL       = 5 * time.Minute
+)
+
+// EventBus fans out job events to in-process subscribers. Each job keeps a
+// short history so late subscribers can replay what already happened.
+type EventBus struct {
+	mu     sync.Mutex
+	topics map[uuid.UUID]*topic
+}
+
+type topic struct {
+	seq     int
+	history []Event
+	subs    map[chan Event]struct{}
+	closed  bool
+}
+
+// NewEventBus constructs an empty EventBus.
+func NewEventBus() *EventBus {
+	return &EventBus{topics: make(map[uuid.UUID]*topic)}
+}
+
+// Publish delivers ev to every subscriber of ev.JobID. Slow subscribers whose
+// buffer is full miss the event rather than blocking generation.
+func (b *EventBus) Publish(ev Event) {
+	if ev.At.IsZero() {
+		ev.At = time.Now().UTC()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topics[ev.JobID]
+	if t == nil {
+		t = &topic{subs: make(map[chan Event]struct{})}
+		b.topics[ev.JobID] = t
+	}
+	if ev.Type == EventPromptSent {
+		// Each attempt starts from scratch, so replays only show the latest run.
+		t.history = nil
+		t.closed = false
+	}
+	t.seq++
+	ev.Seq = t.seq
+	t.history = append(t.history, ev)
+
+	for ch := range t.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+
+	if ev.Type.Terminal() && !ev.Retrying {
+		t.closed = true
+		for ch := range t.subs {
+			close(ch)
+			delete(t.subs, ch)
+		}
+		jobID := ev.JobID
+		time.AfterFunc(historyTTL, func() { b.forget(jobID, t) })
+	}
+}
+
+// Subscribe returns the job's past events and a channel of future ones. The
+// channel is closed after a terminal event; call cancel to stop listening early.
+func (b *EventBus) Subscribe(jobID uuid.UUID) (history []Event, events <-chan Event, cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := b.topics[jobID]
+	if t == nil {
+		t = &topic{subs: make(map[chan Event]struct{})}
+		b.topics[jobID] = t
+	}
+	history = append([]Event(nil), t.history...)
+
+	ch := make(chan Event, subscriberBuffer)
+	if t.closed {
+		close(ch)
+		return history, ch, func() {}
+	}
+	t.subs[ch] = struct{}{}
+
+	return history, ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := t.subs[ch]; ok {
+			delete(t.subs, ch)
+			close(ch)
+		}
+		if len(t.subs) == 0 && len(t.history) == 0 && b.topics[jobID] == t {
+			delete(b.topics, jobID)
+		}
+	}
+}
+
+func (b *EventBus) forget(jobID uuid.UUID, t *topic) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.topics[jobID] == t && t.closed && len(t.subs) == 0 {
+		delete(b.topics, jobID)
+	}
+}
@@ -0,0 +1,54 @@
+package dialogs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBusReplaysHistoryAndClosesOnTerminalEvent(t *testing.T) {
+	bus := NewEventBus()
+	jobID := uuid.New()
+
+	bus.Publish(Event{Type: EventPromptSent, JobID: jobID})
+	bus.Publish(Event{Type: EventTurnsParsed, JobID: jobID, TurnCount: 2})
+
+	history, events, cancel := bus.Subscribe(jobID)
+	defer cancel()
+	require.Len(t, history, 2)
+	require.Equal(t, 1, history[0].Seq)
+	require.Equal(t, 2, history[1].Seq)
+
+	bus.Publish(Event{Type: EventFailed, JobID: jobID, Retrying: true})
+	ev := <-events
+	require.Equal(t, EventFailed, ev.Type)
+	require.Equal(t, 3, ev.Seq)
+
+	// A new attempt drops the previous run from the replay history.
+	bus.Publish(Event{Type: EventPromptSent, JobID: jobID})
+	bus.Publish(Event{Type: EventPersisted, JobID: jobID})
+	require.Equal(t, EventPromptSent, (<-events).Type)
+	require.Equal(t, EventPersisted, (<-events).Type)
+	_, open := <-events
+	require.False(t, open)
+
+	history, events, _ = bus.Subscribe(jobID)
+	require.Len(t, history, 2)
+	require.Equal(t, 5, history[1].Seq)
+	_, open = <-events
+	require.False(t, open)
+}
+
+func TestEventTurnJSON(t *testing.T) {
+	turn := DialogTurn{Speaker: "Ana", Text: "Hola.", Position: 2, Translation: "Привет.", AudioKey: "dialogs/x.mp3", VoiceID: "v"}
+	data, err := json.Marshal(Event{Type: EventTurnGenerated, Turn: eventTurn(turn)})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"turn":{"speaker":"Ana","text":"Hola.","position":2,"translation":"Привет."}`)
+
+	turn.ID = uuid.New()
+	data, err = json.Marshal(eventTurn(turn))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"id":"`+turn.ID.String()+`","speaker":"Ana","text":"Hola.","position":2,"translation":"Привет."}`, string(data))
+}
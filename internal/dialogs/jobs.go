@@ -0,0 +1,226 @@
+package dialogs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoJob signals that no queued job is ready to be claimed.
+var ErrNoJob = errors.New("no job available")
+
+// JobState describes where a generation job is in its lifecycle.
+type JobState string
+
+const (
+	JobQueued         JobState = "queued"
+	JobGeneratingText JobState = "generating_text"
+	JobSynthesizing   JobState = "synthesizing"
+	JobDone           JobState = "done"
+	JobFailed         JobState = "failed"
+)
+
+// Finished reports whether the job reached a terminal state.
+func (s JobState) Finished() bool {
+	return s == JobDone || s == JobFailed
+}
+
+// JobKind says what a generation job produces.
+type JobKind string
+
+const (
+	JobCreate   JobKind = "create"   // A new dialog from Input
+	JobRelevel  JobKind = "relevel"  // A rewrite of ParentID at Input.CEFRLevel
+	JobContinue JobKind = "continue" // NewTurns more turns appended to ParentID
+)
+
+// Job is a queued request to generate a dialog in the background.
+type Job struct {
+	ID    uuid.UUID
+	Kind  JobKind
+	State JobState
+	// Input describes the dialog to produce. For relevel and continue jobs
+	// it is copied from the parent, with the target level.
+	Input       CreateDialogInput
+	ParentID    *uuid.UUID // Dialog the job derives from; nil for JobCreate
+	NewTurns    int        // Turns a JobContinue appends
+	DialogID    *uuid.UUID
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// JobQueue defines the persistence contract for generation jobs.
+type JobQueue interface {
+	Enqueue(ctx context.Context, job Job) error
+	// Claim atomically moves the oldest runnable queued job to generating_text.
+	Claim(ctx context.Context) (Job, error)
+	GetJob(ctx context.Context, id uuid.UUID) (Job, error)
+	SetState(ctx context.Context, id uuid.UUID, state JobState) error
+	Complete(ctx context.Context, id, dialogID uuid.UUID) error
+	// Retry puts the job back into the queue to run no earlier than runAfter.
+	Retry(ctx context.Context, id uuid.UUID, lastError string, runAfter time.Time) error
+	Fail(ctx context.Context, id uuid.UUID, lastError string) error
+	// Release returns an interrupted job to the queue without consuming an attempt.
+	Release(ctx context.Context, id uuid.UUID) error
+	// ReleaseStale requeues jobs stuck in a running state since before olderThan.
+	ReleaseStale(ctx context.Context, olderThan time.Time) (int, error)
+}
+
+// EnqueueDialog validates input and queues a background generation job.
+func (s *Service) EnqueueDialog(ctx context.Context, input CreateDialogInput) (Job, error) {
+	if err := validateCreateInput(input); err != nil {
+		return Job{}, fmt.Errorf("validate input: %w", err)
+	}
+	return s.enqueue(ctx, Job{Kind: JobCreate, Input: input})
+}
+
+// EnqueueRelevel queues a rewrite of the dialog id at targetLevel. See
+// RelevelDialog.
+func (s *Service) EnqueueRelevel(ctx context.Context, id uuid.UUID, targetLevel string) (Job, error) {
+	source, targetLevel, err := s.relevelSource(ctx, id, targetLevel)
+	if err != nil {
+		return Job{}, err
+	}
+
+	return s.enqueue(ctx, Job{
+		Kind: JobRelevel,
+		Input: CreateDialogInput{
+			InputLanguage:  source.InputLanguage,
+			DialogLanguage: source.DialogLanguage,
+			CEFRLevel:      targetLevel,
+			InputWords:     source.InputWords,
+		},
+		ParentID: &source.ID,
+	})
+}
+
+// EnqueueContinue queues a continuation of the dialog id by newTurns turns.
+// See ContinueDialog.
+func (s *Service) EnqueueContinue(ctx context.Context, id uuid.UUID, newTurns int) (Job, error) {
+	newTurns, err := continueTurns(newTurns)
+	if err != nil {
+		return Job{}, err
+	}
+	dlg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Job{}, err
+	}
+
+	return s.enqueue(ctx, Job{
+		Kind: JobContinue,
+		Input: CreateDialogInput{
+			InputLanguage:  dlg.InputLanguage,
+			DialogLanguage: dlg.DialogLanguage,
+			CEFRLevel:      dlg.CEFRLevel,
+			InputWords:     dlg.InputWords,
+		},
+		ParentID: &dlg.ID,
+		NewTurns: newTurns,
+	})
+}
+
+// enqueue fills in the bookkeeping fields of job and queues it.
+func (s *Service) enqueue(ctx context.Context, job Job) (Job, error) {
+	now := time.Now().UTC()
+	job.ID = uuid.New()
+	job.State = JobQueued
+	job.MaxAttempts = s.jobMaxAttempts
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if err := s.jobs.Enqueue(ctx, job); err != nil {
+		return Job{}, fmt.Errorf("enqueue job: %w", err)
+	}
+	return job, nil
+}
+
+// GetJob fetches a generation job by id.
+func (s *Service) GetJob(ctx context.Context, id uuid.UUID) (Job, error) {
+	return s.jobs.GetJob(ctx, id)
+}
+
+// ProcessNextJob claims one queued job and runs it. It returns ErrNoJob when
+// the queue is empty. Jobs interrupted by ctx cancellation are released back
+// to the queue so another worker (or the next process) can pick them up.
+func (s *Service) ProcessNextJob(ctx context.Context) error {
+	job, err := s.jobs.Claim(ctx)
+	if err != nil {
+		return err
+	}
+
+	bg := context.WithoutCancel(ctx)
+	var persisted *Event
+	dlg, err := s.runJob(ctx, job, func(ev Event) {
+		ev.JobID = job.ID
+		switch ev.Type {
+		case EventPromptSent:
+			// Progress updates are advisory; Complete/Retry/Fail below are authoritative.
+			_ = s.jobs.SetState(ctx, job.ID, JobGeneratingText)
+		case EventTurnsParsed:
+			_ = s.jobs.SetState(ctx, job.ID, JobSynthesizing)
+		case EventPersisted:
+			// Published once the job row says done, so subscribers never see a dialog before its job completes.
+			persisted = &ev
+			return
+		}
+		s.events.Publish(ev)
+	})
+	if err == nil {
+		if err := s.jobs.Complete(bg, job.ID, dlg.ID); err != nil {
+			return fmt.Errorf("complete job: %w", err)
+		}
+		s.events.Publish(*persisted)
+		return nil
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		if releaseErr := s.jobs.Release(bg, job.ID); releaseErr != nil {
+			return fmt.Errorf("release job: %w", releaseErr)
+		}
+	case errors.Is(err, ErrInvalidInput) || errors.Is(err, ErrNotFound) || job.Attempts >= job.MaxAttempts:
+		if failErr := s.jobs.Fail(bg, job.ID, err.Error()); failErr != nil {
+			return fmt.Errorf("fail job: %w", failErr)
+		}
+		s.events.Publish(Event{Type: EventFailed, JobID: job.ID, Error: err.Error()})
+	default:
+		runAfter := time.Now().UTC().Add(retryBackoff(job.Attempts))
+		if retryErr := s.jobs.Retry(bg, job.ID, err.Error(), runAfter); retryErr != nil {
+			return fmt.Errorf("retry job: %w", retryErr)
+		}
+		s.events.Publish(Event{Type: EventFailed, JobID: job.ID, Error: err.Error(), Retrying: true})
+	}
+	return fmt.Errorf("job %s: %w", job.ID, err)
+}
+
+// runJob produces the dialog job asks for, reporting progress through emit.
+func (s *Service) runJob(ctx context.Context, job Job, emit func(Event)) (Dialog, error) {
+	switch job.Kind {
+	case JobRelevel, JobContinue:
+		if job.ParentID == nil {
+			// The parent was deleted while the job waited.
+			return Dialog{}, fmt.Errorf("%s: %w", job.Kind, ErrNotFound)
+		}
+		if job.Kind == JobRelevel {
+			return s.relevelDialog(ctx, *job.ParentID, job.Input.CEFRLevel, emit)
+		}
+		return s.continueDialog(ctx, *job.ParentID, job.NewTurns, emit)
+	default:
+		return s.createDialog(ctx, job.Input, emit)
+	}
+}
+
+// ReleaseStaleJobs requeues jobs left running by a crashed process.
+func (s *Service) ReleaseStaleJobs(ctx context.Context, olderThan time.Duration) (int, error) {
+	return s.jobs.ReleaseStale(ctx, time.Now().UTC().Add(-olderThan))
+}
+
+// retryBackoff grows linearly with the attempt count and is capped at a minute.
+func retryBackoff(attempt int) time.Duration {
+	return min(time.Duration(attempt)*10*time.Second, time.Minute)
+}
@@ -0,0 +1,34 @@
+package dialogs
+
+import "strings"
+
+// CEFRLevels lists the CEFR levels from easiest to hardest.
+var CEFRLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// LevelDistance returns how many levels to lies above from, negative when it
+// is easier. Unknown or empty levels are zero apart.
+func LevelDistance(from, to string) int {
+	i, j := levelIndex(from), levelIndex(to)
+	if i < 0 || j < 0 {
+		return 0
+	}
+	return j - i
+}
+
+// levelMissed reports whether estimated lies more than one level from
+// requested. The level estimate is coarse, so a level either way does not
+// count as missing it.
+func levelMissed(requested, estimated string) bool {
+	distance := LevelDistance(requested, estimated)
+	return distance > 1 || distance < -1
+}
+
+func levelIndex(level string) int {
+	level = strings.ToUpper(strings.TrimSpace(level))
+	for i, l := range CEFRLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
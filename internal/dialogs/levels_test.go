@@ -0,0 +1,58 @@
+package dialogs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+// scriptedEstimator returns its levels in order, repeating the last one.
+type scriptedEstimator struct {
+	levels []string
+	calls  int
+	texts  int // Texts passed to the last call
+}
+
+func (s *scriptedEstimator) EstimateLevel(language string, texts []string) string {
+	level := s.levels[min(s.calls, len(s.levels)-1)]
+	s.calls++
+	s.texts = len(texts)
+	return level
+}
+
+func TestLevelDistance(t *testing.T) {
+	require.Equal(t, 2, dialogs.LevelDistance("A2", "B2"))
+	require.Equal(t, -1, dialogs.LevelDistance("c1", "B2"))
+	require.Zero(t, dialogs.LevelDistance("A2", ""))
+}
+
+func TestCreateDialogRegeneratesWhenLevelIsOff(t *testing.T) {
+	for name, tc := range map[string]struct {
+		levels   []string
+		regens   int
+		calls    int
+		level    string
+		mismatch bool
+	}{
+		"regenerated":      {levels: []string{"C1", "A2"}, regens: 1, calls: 2, level: "A2"},
+		"one level off":    {levels: []string{"B1"}, regens: 1, calls: 1, level: "B1"},
+		"regeneration off": {levels: []string{"C1"}, calls: 1, level: "C1", mismatch: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			estimator := &scriptedEstimator{levels: tc.levels}
+			svc, _, _ := newTestService(&dialogs.ServiceOptions{
+				LevelEstimator:     estimator,
+				LevelRegenerations: tc.regens,
+			})
+
+			dlg, err := svc.CreateDialog(context.Background(), testInput)
+			require.NoError(t, err)
+			require.Equal(t, tc.calls, estimator.calls)
+			require.Equal(t, tc.level, dlg.EstimatedLevel)
+			require.Equal(t, tc.mismatch, dlg.LevelMismatch())
+		})
+	}
+}
@@ -3,6 +3,9 @@ package dialogs
 import (
 	"context"
 	"errors"
+	"io"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,6 +17,16 @@ var (
 
 	// ErrInvalidInput signals validation errors when creating dialogs.
 	ErrInvalidInput = errors.New("invalid dialog input")
+
+	// ErrAudioNotFound signals that no stored audio exists for a turn.
+	ErrAudioNotFound = errors.New("audio not found")
+
+	// ErrConflict signals that a dialog changed since it was read.
+	ErrConflict = errors.New("dialog was changed concurrently")
+
+	// ErrSynthesisFailed signals that no turn of a new dialog could be
+	// synthesized, so it is not worth storing.
+	ErrSynthesisFailed = errors.New("synthesis failed for every turn")
 )
 
 // Dialog represents a generated dialog with metadata.
@@ -27,15 +40,93 @@ type Dialog struct {
 	Translations   map[string]string // Maps input word to translated word
 	Turns          []DialogTurn
 	CreatedAt      time.Time
+
+	// RepairAttempts counts the follow-up requests the LLM needed before its
+	// answer satisfied the dialog contract.
+	RepairAttempts int
+
+	// Coverage is the share of input words found in the turns, and
+	// CoverageFlagged marks dialogs that stayed below the threshold after
+	// every allowed regeneration.
+	Coverage        float64
+	CoverageFlagged bool
+
+	// EstimatedLevel is the CEFR level the turns appear to be written at,
+	// kept next to the requested CEFRLevel. Empty when no estimate was made.
+	EstimatedLevel string
+
+	// ParentID links a dialog rewritten at another level to its source.
+	ParentID uuid.NullUUID
+}
+
+// LevelMismatch reports whether the estimated level is more than one level
+// from the requested one, the same margin that triggers a regeneration.
+func (d Dialog) LevelMismatch() bool {
+	return levelMissed(d.CEFRLevel, d.EstimatedLevel)
+}
+
+// HasTurnTranslations reports whether any turn carries a translation.
+func (d Dialog) HasTurnTranslations() bool {
+	for _, turn := range d.Turns {
+		if turn.Translation != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Speakers returns the distinct speaker names in order of first appearance.
+func (d Dialog) Speakers() []string {
+	var speakers []string
+	for _, turn := range d.Turns {
+		if !slices.Contains(speakers, turn.Speaker) {
+			speakers = append(speakers, turn.Speaker)
+		}
+	}
+	return speakers
 }
 
 // DialogTurn is a single utterance inside a dialog.
 type DialogTurn struct {
 	ID       uuid.UUID
 	Speaker  string
+	Gender   string // Speaker gender hint from the LLM: "female", "male" or empty
+	VoiceID  string // TTS voice cast for the speaker; reused on re-synthesis
 	Text     string
-	AudioURL string
+	AudioURL string // Static/placeholder URL when no stored audio exists
+	AudioKey string // Key of the synthesized audio inside the AudioStore
 	Position int
+
+	// AudioPending marks a turn whose synthesis failed and still needs audio.
+	AudioPending bool
+
+	// Translation glosses Text in the dialog's InputLanguage. It is optional;
+	// older dialogs and models that skip it leave it empty.
+	Translation string
+
+	// Spans locate the input words realized in Text, ordered by offset.
+	// The dialog_turn_spans table is their only store, so they are left out
+	// of JSON snapshots.
+	Spans []VocabSpan `json:"-"`
+
+	// Audio carries freshly synthesized bytes from the TTS client until the
+	// service moves them into the AudioStore. It is never persisted.
+	Audio []byte `json:"-"`
+}
+
+// HasStoredAudio reports whether the turn's audio lives in the AudioStore.
+func (t DialogTurn) HasStoredAudio() bool {
+	return t.AudioKey != ""
+}
+
+// PlaceholderAudioPath is the silent clip TTS clients point a turn at when
+// they produced no audio for it.
+const PlaceholderAudioPath = "/static/audio/placeholder.mp3"
+
+// HasPlaceholderAudio reports whether the turn still lacks real audio, either
+// because synthesis failed or because the TTS client returned nothing.
+func (t DialogTurn) HasPlaceholderAudio() bool {
+	return !t.HasStoredAudio() && (t.AudioPending || strings.HasPrefix(t.AudioURL, PlaceholderAudioPath))
 }
 
 // GenerateDialogParams describe the request to the LLM client.
@@ -44,6 +135,20 @@ type GenerateDialogParams struct {
 	DialogLanguage string
 	CEFRLevel      string
 	InputWords     []string
+
+	// Source, when set, is an existing dialog to rewrite at CEFRLevel with
+	// the same storyline, speakers and vocabulary.
+	Source *Dialog
+
+	// Prior, when set, holds the turns of a conversation to continue with
+	// NewTurns more turns; the LLM returns only the new ones.
+	Prior    []DialogTurn
+	NewTurns int
+
+	// Rewrite, when set, is the turn of Prior to write again so that it fits
+	// the turns around it. The LLM returns only the new version, spoken by
+	// the same speaker; NewTurns is 1.
+	Rewrite *DialogTurn
 }
 
 // CreateDialogInput collects user input required to create a dialog.
@@ -69,6 +174,30 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (Dialog, error)
 	Search(ctx context.Context, filter DialogFilter) ([]Dialog, error)
 	Delete(ctx context.Context, id uuid.UUID) error
+	GetTurn(ctx context.Context, id uuid.UUID) (DialogTurn, error)
+	// AppendTurns stores dlg.Turns[from:] as new turns of an existing dialog
+	// and rewrites its turn snapshot and coverage in the same transaction.
+	AppendTurns(ctx context.Context, dlg Dialog, from int) error
+	// ListFamily returns the other dialogs linked to id through ParentID,
+	// in either direction, without their turns.
+	ListFamily(ctx context.Context, id uuid.UUID) ([]Dialog, error)
+	// Update rewrites the title, translations, turns, coverage and level
+	// estimate of an existing dialog. The content it replaces is kept as a
+	// new DialogRevision in the same transaction.
+	Update(ctx context.Context, dlg Dialog) error
+	// ListRevisions returns the revisions of a dialog, newest first.
+	ListRevisions(ctx context.Context, dialogID uuid.UUID) ([]DialogRevision, error)
+	GetRevision(ctx context.Context, dialogID uuid.UUID, number int) (DialogRevision, error)
+	// SetTurnAudio stores the audio fields of turn and rewrites the dialog's
+	// turn snapshot, without creating a revision.
+	SetTurnAudio(ctx context.Context, dialogID uuid.UUID, turn DialogTurn) error
+	// ListPlaceholderDialogs returns the ids of dialogs that have at least
+	// one turn with placeholder or pending audio, oldest first.
+	ListPlaceholderDialogs(ctx context.Context) ([]uuid.UUID, error)
+	// SaveQuiz stores quiz, replacing the earlier quiz of its dialog.
+	SaveQuiz(ctx context.Context, quiz Quiz) error
+	// GetQuiz returns the quiz of a dialog, or ErrQuizNotFound.
+	GetQuiz(ctx context.Context, dialogID uuid.UUID) (Quiz, error)
 }
 
 // LLMClient describes the interface to generate dialogs with an LLM.
@@ -76,7 +205,56 @@ type LLMClient interface {
 	GenerateDialog(ctx context.Context, params GenerateDialogParams) (Dialog, error)
 }
 
+// StreamingLLMClient is an optional LLMClient extension for clients that can
+// report turns while the model is still generating. onTurn receives each turn
+// in order; the returned Dialog is the complete, validated result.
+type StreamingLLMClient interface {
+	LLMClient
+	StreamDialog(ctx context.Context, params GenerateDialogParams, onTurn func(DialogTurn)) (Dialog, error)
+}
+
 // TTSClient describes the interface to synthesize audio URLs for dialog turns.
 type TTSClient interface {
 	SynthesizeDialog(ctx context.Context, dlg Dialog) (Dialog, error)
 }
+
+// TurnSynthesizer is implemented by TTS clients that can synthesize a single
+// turn, which lets the service report progress after every turn.
+type TurnSynthesizer interface {
+	SynthesizeTurn(ctx context.Context, dlg Dialog, turn DialogTurn) (DialogTurn, error)
+}
+
+// VoiceCaster assigns TTS voices to the speakers of a dialog. Implementations
+// must be deterministic so that re-casting the same dialog yields the same
+// voices.
+type VoiceCaster interface {
+	CastVoices(dlg Dialog) map[string]string // speaker -> voice ID
+}
+
+// Stemmer reduces a lowercase word to a stem shared by its inflected forms,
+// so that coverage checks match "casas" against the translation "casa".
+type Stemmer interface {
+	Stem(word string) string
+}
+
+// LevelEstimator guesses the CEFR level ("A1" to "C2") that texts in a
+// language are written at.
+type LevelEstimator interface {
+	EstimateLevel(language string, texts []string) string
+}
+
+// AudioObject is a stored audio blob opened for reading.
+type AudioObject struct {
+	Content     io.ReadSeekCloser
+	Size        int64
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+}
+
+// AudioStore persists synthesized audio outside of the database rows.
+type AudioStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Open(ctx context.Context, key string) (AudioObject, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,171 @@
+package dialogs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClozeAttempt is one graded submission of a dialog's cloze exercise.
+type ClozeAttempt struct {
+	ID        uuid.UUID
+	LearnerID uuid.UUID // Anonymous id kept by the learner's browser
+	DialogID  uuid.UUID
+	Answers   []ClozeAnswer
+	Correct   int
+	CreatedAt time.Time
+}
+
+// ClozeAnswer is the learner's answer to one blank.
+type ClozeAnswer struct {
+	TurnID   uuid.UUID `json:"turn_id"`
+	Expected string    `json:"expected"`
+	Given    string    `json:"given"`
+	Correct  bool      `json:"correct"`
+}
+
+// Total is the number of blanks of the attempt.
+func (a ClozeAttempt) Total() int {
+	return len(a.Answers)
+}
+
+// DictationAttempt is one graded dictation of a turn.
+type DictationAttempt struct {
+	ID        uuid.UUID
+	LearnerID uuid.UUID
+	DialogID  uuid.UUID
+	TurnID    uuid.UUID
+	Given     string // What the learner typed
+	Correct   int    // Words typed exactly
+	Total     int    // Words of the turn plus extra words typed
+	CreatedAt time.Time
+}
+
+// Accuracy is the share of words typed exactly.
+func (a DictationAttempt) Accuracy() float64 {
+	if a.Total == 0 {
+		return 0
+	}
+	return float64(a.Correct) / float64(a.Total)
+}
+
+// TurnAccuracy sums up the dictations of a turn by one learner.
+type TurnAccuracy struct {
+	TurnID   uuid.UUID
+	Attempts int
+	Correct  int // Over all attempts
+	Total    int
+	LastAt   time.Time
+}
+
+// Accuracy is the share of words typed exactly over all attempts.
+func (a TurnAccuracy) Accuracy() float64 {
+	if a.Total == 0 {
+		return 0
+	}
+	return float64(a.Correct) / float64(a.Total)
+}
+
+// HardTurn is a turn the learner struggles to take down, with its record.
+type HardTurn struct {
+	Turn DialogTurn
+	TurnAccuracy
+}
+
+// PracticeStore defines the persistence contract for exercise attempts.
+type PracticeStore interface {
+	AddClozeAttempt(ctx context.Context, attempt ClozeAttempt) error
+	// ListClozeAttempts returns the newest attempts of a learner at a dialog.
+	ListClozeAttempts(ctx context.Context, learnerID, dialogID uuid.UUID, limit int) ([]ClozeAttempt, error)
+	AddDictationAttempt(ctx context.Context, attempt DictationAttempt) error
+	// TurnAccuracies sums up the dictations of a learner per turn of a
+	// dialog. Turns never dictated are left out.
+	TurnAccuracies(ctx context.Context, learnerID, dialogID uuid.UUID) ([]TurnAccuracy, error)
+}
+
+// RecordClozeAttempt stores a graded cloze submission.
+func (s *Service) RecordClozeAttempt(ctx context.Context, attempt ClozeAttempt) (ClozeAttempt, error) {
+	if attempt.LearnerID == uuid.Nil {
+		return ClozeAttempt{}, fmt.Errorf("%w: learner is required", ErrInvalidInput)
+	}
+	if len(attempt.Answers) == 0 {
+		return ClozeAttempt{}, fmt.Errorf("%w: attempt has no answers", ErrInvalidInput)
+	}
+	if _, err := s.repo.GetByID(ctx, attempt.DialogID); err != nil {
+		return ClozeAttempt{}, err
+	}
+
+	attempt.ID = uuid.New()
+	attempt.CreatedAt = time.Now().UTC()
+	attempt.Correct = 0
+	for _, answer := range attempt.Answers {
+		if answer.Correct {
+			attempt.Correct++
+		}
+	}
+	if err := s.practice.AddClozeAttempt(ctx, attempt); err != nil {
+		return ClozeAttempt{}, fmt.Errorf("persist attempt: %w", err)
+	}
+	return attempt, nil
+}
+
+// ClozeAttempts returns the newest cloze attempts of a learner at a dialog.
+func (s *Service) ClozeAttempts(ctx context.Context, learnerID, dialogID uuid.UUID, limit int) ([]ClozeAttempt, error) {
+	return s.practice.ListClozeAttempts(ctx, learnerID, dialogID, limit)
+}
+
+// RecordDictationAttempt stores a graded dictation of a turn.
+func (s *Service) RecordDictationAttempt(ctx context.Context, attempt DictationAttempt) (DictationAttempt, error) {
+	if attempt.LearnerID == uuid.Nil {
+		return DictationAttempt{}, fmt.Errorf("%w: learner is required", ErrInvalidInput)
+	}
+	if attempt.Total <= 0 || attempt.Correct < 0 || attempt.Correct > attempt.Total {
+		return DictationAttempt{}, fmt.Errorf("%w: %d of %d words correct", ErrInvalidInput, attempt.Correct, attempt.Total)
+	}
+	dlg, err := s.repo.GetByID(ctx, attempt.DialogID)
+	if err != nil {
+		return DictationAttempt{}, err
+	}
+	if turnIndex(dlg.Turns, attempt.TurnID) < 0 {
+		return DictationAttempt{}, ErrNotFound
+	}
+
+	attempt.ID = uuid.New()
+	attempt.CreatedAt = time.Now().UTC()
+	if err := s.practice.AddDictationAttempt(ctx, attempt); err != nil {
+		return DictationAttempt{}, fmt.Errorf("persist attempt: %w", err)
+	}
+	return attempt, nil
+}
+
+// HardestTurns returns up to limit turns of a dialog the learner has not yet
+// taken down without mistakes, lowest accuracy first.
+func (s *Service) HardestTurns(ctx context.Context, learnerID, dialogID uuid.UUID, limit int) ([]HardTurn, error) {
+	dlg, err := s.repo.GetByID(ctx, dialogID)
+	if err != nil {
+		return nil, err
+	}
+	accuracies, err := s.practice.TurnAccuracies(ctx, learnerID, dialogID)
+	if err != nil {
+		return nil, fmt.Errorf("list turn accuracies: %w", err)
+	}
+
+	var hard []HardTurn
+	for _, accuracy := range accuracies {
+		i := turnIndex(dlg.Turns, accuracy.TurnID)
+		if i < 0 || accuracy.Correct >= accuracy.Total {
+			continue
+		}
+		hard = append(hard, HardTurn{Turn: dlg.Turns[i], TurnAccuracy: accuracy})
+	}
+	sort.SliceStable(hard, func(i, j int) bool {
+		if a, b := hard[i].Accuracy(), hard[j].Accuracy(); a != b {
+			return a < b
+		}
+		return hard[i].Turn.Position < hard[j].Turn.Position
+	})
+	return hard[:min(limit, len(hard))], nil
+}
@@ -0,0 +1,160 @@
+package dialogs_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+	"leveltalk/internal/tts"
+)
+
+// memoryPractice is an in-memory dialogs.PracticeStore.
+type memoryPractice struct {
+	mu        sync.Mutex
+	cloze     []dialogs.ClozeAttempt
+	dictation []dialogs.DictationAttempt
+}
+
+func (m *memoryPractice) AddClozeAttempt(ctx context.Context, attempt dialogs.ClozeAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cloze = append(m.cloze, attempt)
+	return nil
+}
+
+func (m *memoryPractice) ListClozeAttempts(ctx context.Context, learnerID, dialogID uuid.UUID, limit int) ([]dialogs.ClozeAttempt, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var attempts []dialogs.ClozeAttempt
+	for _, attempt := range m.cloze {
+		if attempt.LearnerID == learnerID && attempt.DialogID == dialogID {
+			attempts = append(attempts, attempt)
+		}
+	}
+	sort.SliceStable(attempts, func(i, j int) bool { return attempts[i].CreatedAt.After(attempts[j].CreatedAt) })
+	return attempts[:min(limit, len(attempts))], nil
+}
+
+func (m *memoryPractice) AddDictationAttempt(ctx context.Context, attempt dialogs.DictationAttempt) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dictation = append(m.dictation, attempt)
+	return nil
+}
+
+func (m *memoryPractice) TurnAccuracies(ctx context.Context, learnerID, dialogID uuid.UUID) ([]dialogs.TurnAccuracy, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	byTurn := map[uuid.UUID]*dialogs.TurnAccuracy{}
+	var accuracies []*dialogs.TurnAccuracy
+	for _, attempt := range m.dictation {
+		if attempt.LearnerID != learnerID || attempt.DialogID != dialogID {
+			continue
+		}
+		accuracy, ok := byTurn[attempt.TurnID]
+		if !ok {
+			accuracy = &dialogs.TurnAccuracy{TurnID: attempt.TurnID}
+			byTurn[attempt.TurnID] = accuracy
+			accuracies = append(accuracies, accuracy)
+		}
+		accuracy.Attempts++
+		accuracy.Correct += attempt.Correct
+		accuracy.Total += attempt.Total
+		if attempt.CreatedAt.After(accuracy.LastAt) {
+			accuracy.LastAt = attempt.CreatedAt
+		}
+	}
+	var out []dialogs.TurnAccuracy
+	for _, accuracy := range accuracies {
+		out = append(out, *accuracy)
+	}
+	return out, nil
+}
+
+func TestRecordClozeAttemptKeepsLearnersApart(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryPractice{}
+	svc := dialogs.NewService(newMemoryRepo(), llm.NewStubClient(testLogger()), tts.NewStubClient(), newMemoryAudio(), &dialogs.ServiceOptions{Practice: store})
+
+	dlg, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	learner, other := uuid.New(), uuid.New()
+
+	attempt, err := svc.RecordClozeAttempt(ctx, dialogs.ClozeAttempt{
+		LearnerID: learner,
+		DialogID:  dlg.ID,
+		Answers: []dialogs.ClozeAnswer{
+			{Expected: "casa", Given: "casa", Correct: true},
+			{Expected: "perro", Given: "gato"},
+		},
+		Correct: 2, // Recounted from the answers
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, attempt.ID)
+	require.Equal(t, 1, attempt.Correct)
+	require.Equal(t, 2, attempt.Total())
+
+	attempts, err := svc.ClozeAttempts(ctx, learner, dlg.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	attempts, err = svc.ClozeAttempts(ctx, other, dlg.ID, 10)
+	require.NoError(t, err)
+	require.Empty(t, attempts)
+
+	_, err = svc.RecordClozeAttempt(ctx, dialogs.ClozeAttempt{DialogID: dlg.ID, Answers: attempt.Answers})
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+	_, err = svc.RecordClozeAttempt(ctx, dialogs.ClozeAttempt{LearnerID: learner, DialogID: uuid.New(), Answers: attempt.Answers})
+	require.ErrorIs(t, err, dialogs.ErrNotFound)
+}
+
+func TestHardestTurnsRanksDictationsByAccuracy(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryPractice{}
+	svc := dialogs.NewService(newMemoryRepo(), llm.NewStubClient(testLogger()), tts.NewStubClient(), newMemoryAudio(), &dialogs.ServiceOptions{Practice: store})
+
+	dlg, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(dlg.Turns), 3)
+	learner := uuid.New()
+
+	record := func(turn, correct, total int) {
+		t.Helper()
+		_, err := svc.RecordDictationAttempt(ctx, dialogs.DictationAttempt{
+			LearnerID: learner,
+			DialogID:  dlg.ID,
+			TurnID:    dlg.Turns[turn].ID,
+			Given:     "…",
+			Correct:   correct,
+			Total:     total,
+		})
+		require.NoError(t, err)
+	}
+	record(0, 2, 8)
+	record(0, 8, 8) // 10 of 16 over both attempts
+	record(1, 1, 8) // Hardest
+	record(2, 8, 8) // Mastered, not listed
+	record(1, 3, 8)
+
+	hard, err := svc.HardestTurns(ctx, learner, dlg.ID, 5)
+	require.NoError(t, err)
+	require.Len(t, hard, 2)
+	require.Equal(t, dlg.Turns[1].ID, hard[0].Turn.ID)
+	require.Equal(t, 2, hard[0].Attempts)
+	require.Equal(t, 0.25, hard[0].Accuracy())
+	require.Equal(t, dlg.Turns[0].ID, hard[1].Turn.ID)
+
+	hard, err = svc.HardestTurns(ctx, uuid.New(), dlg.ID, 5)
+	require.NoError(t, err)
+	require.Empty(t, hard)
+
+	_, err = svc.RecordDictationAttempt(ctx, dialogs.DictationAttempt{LearnerID: learner, DialogID: dlg.ID, TurnID: uuid.New(), Correct: 1, Total: 1})
+	require.ErrorIs(t, err, dialogs.ErrNotFound)
+	_, err = svc.RecordDictationAttempt(ctx, dialogs.DictationAttempt{LearnerID: learner, DialogID: dlg.ID, TurnID: dlg.Turns[0].ID, Correct: 2, Total: 1})
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+}
@@ -0,0 +1,242 @@
+package dialogs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrQuizUnsupported signals that the configured LLM client cannot write
+	// quizzes.
+	ErrQuizUnsupported = errors.New("LLM client does not support quizzes")
+
+	// ErrQuizNotFound signals that no quiz was generated for a dialog yet.
+	ErrQuizNotFound = errors.New("quiz not found")
+)
+
+const (
+	// DefaultQuizQuestions is how many questions GenerateQuiz asks for.
+	DefaultQuizQuestions = 6
+	// minQuizQuestions is how many questions have to pass validation for a
+	// quiz to be stored.
+	minQuizQuestions = 3
+	// quizAttempts bounds how often GenerateQuiz asks the model.
+	quizAttempts = 2
+
+	minQuizOptions = 3
+	maxQuizOptions = 5
+)
+
+// QuestionKind tells how a quiz question is answered.
+type QuestionKind string
+
+const (
+	QuestionMultipleChoice QuestionKind = "multiple_choice"
+	QuestionTrueFalse      QuestionKind = "true_false"
+)
+
+// QuizQuestion is a comprehension question about a dialog, written in the
+// dialog language.
+type QuizQuestion struct {
+	Kind   QuestionKind `json:"kind"`
+	Prompt string       `json:"prompt"`
+	// Options are the choices of a multiple-choice question. True/false
+	// questions have none.
+	Options []string `json:"options,omitempty"`
+	// Answer is the index of the right option, or 0 for true and 1 for false.
+	Answer int `json:"answer"`
+	// Turn is the index of the turn that answers the question, and Evidence
+	// the words of that turn that do.
+	Turn     int    `json:"turn"`
+	Evidence string `json:"evidence"`
+}
+
+// Choices returns how many answers the question offers.
+func (q QuizQuestion) Choices() int {
+	if q.Kind == QuestionTrueFalse {
+		return 2
+	}
+	return len(q.Options)
+}
+
+// Quiz is the stored set of comprehension questions of a dialog.
+type Quiz struct {
+	DialogID  uuid.UUID
+	Questions []QuizQuestion
+	CreatedAt time.Time
+}
+
+// QuizScore is a graded set of quiz answers.
+type QuizScore struct {
+	Given   []int  // Chosen answer per question, -1 when unanswered
+	Right   []bool // Per question
+	Correct int
+}
+
+// Score grades answers, given per question; missing or out-of-range answers
+// count as wrong.
+func (q Quiz) Score(answers []int) QuizScore {
+	score := QuizScore{Given: make([]int, len(q.Questions)), Right: make([]bool, len(q.Questions))}
+	for i, question := range q.Questions {
+		score.Given[i] = -1
+		if i < len(answers) && answers[i] >= 0 && answers[i] < question.Choices() {
+			score.Given[i] = answers[i]
+		}
+		if score.Given[i] == question.Answer {
+			score.Right[i] = true
+			score.Correct++
+		}
+	}
+	return score
+}
+
+// QuizParams describe the request to a QuizWriter.
+type QuizParams struct {
+	InputLanguage  string
+	DialogLanguage string
+	CEFRLevel      string
+	Title          string
+	Turns          []DialogTurn
+	Questions      int
+}
+
+// QuizWriter is an optional LLMClient extension for clients that can write
+// comprehension questions about a dialog.
+type QuizWriter interface {
+	GenerateQuiz(ctx context.Context, params QuizParams) ([]QuizQuestion, error)
+}
+
+// GenerateQuiz asks the LLM for comprehension questions about the dialog id
+// and stores those that pass validation, replacing any earlier quiz. The
+// model is asked again when too few questions pass.
+func (s *Service) GenerateQuiz(ctx context.Context, dialogID uuid.UUID) (Quiz, error) {
+	writer, ok := s.llm.(QuizWriter)
+	if !ok {
+		return Quiz{}, ErrQuizUnsupported
+	}
+	dlg, err := s.repo.GetByID(ctx, dialogID)
+	if err != nil {
+		return Quiz{}, err
+	}
+	if len(dlg.Turns) == 0 {
+		return Quiz{}, fmt.Errorf("%w: dialog has no turns", ErrInvalidInput)
+	}
+
+	params := QuizParams{
+		InputLanguage:  dlg.InputLanguage,
+		DialogLanguage: dlg.DialogLanguage,
+		CEFRLevel:      dlg.CEFRLevel,
+		Title:          dlg.Title,
+		Turns:          dlg.Turns,
+		Questions:      DefaultQuizQuestions,
+	}
+	var valid []QuizQuestion
+	for attempt := 0; attempt < quizAttempts && len(valid) < minQuizQuestions; attempt++ {
+		questions, err := writer.GenerateQuiz(ctx, params)
+		if err != nil {
+			return Quiz{}, fmt.Errorf("generate quiz: %w", err)
+		}
+		valid = s.validQuizQuestions(dlg, questions)
+	}
+	if len(valid) < minQuizQuestions {
+		return Quiz{}, fmt.Errorf("generate quiz: only %d of the questions match the dialog", len(valid))
+	}
+
+	quiz := Quiz{DialogID: dlg.ID, Questions: valid, CreatedAt: time.Now().UTC()}
+	if err := s.repo.SaveQuiz(ctx, quiz); err != nil {
+		return Quiz{}, fmt.Errorf("persist quiz: %w", err)
+	}
+	return quiz, nil
+}
+
+// GetQuiz fetches the stored quiz of a dialog, or ErrQuizNotFound.
+func (s *Service) GetQuiz(ctx context.Context, dialogID uuid.UUID) (Quiz, error) {
+	return s.repo.GetQuiz(ctx, dialogID)
+}
+
+// validQuizQuestions keeps the questions whose answer key holds up against
+// the dialog, see checkQuizQuestion.
+func (s *Service) validQuizQuestions(dlg Dialog, questions []QuizQuestion) []QuizQuestion {
+	stem := s.stemmers[strings.ToLower(dlg.DialogLanguage)]
+	var valid []QuizQuestion
+	for _, question := range questions {
+		if checkQuizQuestion(dlg, question, stem) == nil {
+			valid = append(valid, question)
+		}
+	}
+	return valid
+}
+
+// checkQuizQuestion validates the answer key of question against the turn it
+// cites: the evidence has to be quoted from that turn, and the right option
+// of a multiple-choice question has to share a word with the turn or its
+// speaker, so a key the model made up does not pass.
+func checkQuizQuestion(dlg Dialog, question QuizQuestion, stem Stemmer) error {
+	if strings.TrimSpace(question.Prompt) == "" {
+		return errors.New("empty prompt")
+	}
+	if question.Turn < 0 || question.Turn >= len(dlg.Turns) {
+		return fmt.Errorf("turn %d out of range", question.Turn)
+	}
+	turn := dlg.Turns[question.Turn]
+	evidence := collapseSpaces(question.Evidence)
+	if evidence == "" || !strings.Contains(collapseSpaces(turn.Text), evidence) {
+		return fmt.Errorf("evidence %q is not part of turn %d", question.Evidence, question.Turn)
+	}
+
+	switch question.Kind {
+	case QuestionTrueFalse:
+		if len(question.Options) > 0 {
+			return errors.New("true/false question with options")
+		}
+		if question.Answer != 0 && question.Answer != 1 {
+			return fmt.Errorf("answer %d is neither true nor false", question.Answer)
+		}
+	case QuestionMultipleChoice:
+		if n := len(question.Options); n < minQuizOptions || n > maxQuizOptions {
+			return fmt.Errorf("%d options", n)
+		}
+		seen := make(map[string]bool)
+		for _, option := range question.Options {
+			key := collapseSpaces(option)
+			if key == "" || seen[key] {
+				return fmt.Errorf("empty or repeated option %q", option)
+			}
+			seen[key] = true
+		}
+		if question.Answer < 0 || question.Answer >= len(question.Options) {
+			return fmt.Errorf("answer %d out of range", question.Answer)
+		}
+		if !sharesWord(question.Options[question.Answer], turn.Speaker+": "+turn.Text, stem) {
+			return fmt.Errorf("answer %q does not appear in turn %d", question.Options[question.Answer], question.Turn)
+		}
+	default:
+		return fmt.Errorf("unknown question kind %q", question.Kind)
+	}
+	return nil
+}
+
+// sharesWord reports whether a and b have a word in common, comparing stems
+// when stem is not nil.
+func sharesWord(a, b string, stem Stemmer) bool {
+	words := make(map[string]bool)
+	for _, tok := range tokenize(b, stem) {
+		words[tok.key] = true
+	}
+	for _, tok := range tokenize(a, stem) {
+		if words[tok.key] {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseSpaces lower-cases s and collapses its runs of white space.
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
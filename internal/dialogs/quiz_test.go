@@ -0,0 +1,114 @@
+package dialogs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+	"leveltalk/internal/tts"
+)
+
+// quizLLM is the stub LLM with scripted quizzes, one per call.
+type quizLLM struct {
+	*llm.StubClient
+	quizzes [][]dialogs.QuizQuestion
+	calls   int
+}
+
+func (q *quizLLM) GenerateQuiz(ctx context.Context, params dialogs.QuizParams) ([]dialogs.QuizQuestion, error) {
+	quiz := q.quizzes[min(q.calls, len(q.quizzes)-1)]
+	q.calls++
+	return quiz, nil
+}
+
+func TestGenerateQuizWithStubLLM(t *testing.T) {
+	ctx := context.Background()
+	svc := dialogs.NewService(newMemoryRepo(), llm.NewStubClient(testLogger()), tts.NewStubClient(), newMemoryAudio(), nil)
+	dlg, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	_, err = svc.GetQuiz(ctx, dlg.ID)
+	require.ErrorIs(t, err, dialogs.ErrQuizNotFound)
+
+	quiz, err := svc.GenerateQuiz(ctx, dlg.ID)
+	require.NoError(t, err)
+	require.Len(t, quiz.Questions, dialogs.DefaultQuizQuestions)
+	require.Equal(t, dialogs.QuestionMultipleChoice, quiz.Questions[0].Kind)
+	require.Equal(t, dialogs.QuestionTrueFalse, quiz.Questions[1].Kind)
+
+	again, err := svc.GenerateQuiz(ctx, dlg.ID)
+	require.NoError(t, err)
+	require.Equal(t, quiz.Questions, again.Questions)
+
+	stored, err := svc.GetQuiz(ctx, dlg.ID)
+	require.NoError(t, err)
+	require.Equal(t, quiz.Questions, stored.Questions)
+
+	answers := make([]int, len(quiz.Questions))
+	for i, question := range quiz.Questions {
+		answers[i] = question.Answer
+	}
+	require.Equal(t, len(answers), quiz.Score(answers).Correct)
+
+	answers[0] = (answers[0] + 1) % quiz.Questions[0].Choices()
+	score := quiz.Score(answers[:4])
+	require.Equal(t, 3, score.Correct) // Unanswered questions count as wrong
+	require.Equal(t, []int{answers[0], answers[1], answers[2], answers[3], -1, -1}, score.Given)
+	require.False(t, score.Right[0])
+}
+
+func TestGenerateQuizDropsUnsupportedAnswerKeys(t *testing.T) {
+	ctx := context.Background()
+	dlg := dialogs.Dialog{
+		ID: uuid.New(),
+		Turns: []dialogs.DialogTurn{
+			{Speaker: "Ana", Text: "Mi perro se llama Toby."},
+			{Speaker: "Luis", Text: "Yo vivo en una casa  grande."},
+		},
+	}
+	valid := []dialogs.QuizQuestion{
+		{Kind: dialogs.QuestionMultipleChoice, Prompt: "¿Cómo se llama el perro?", Options: []string{"Rex", "Toby", "Max"}, Answer: 1, Turn: 0, Evidence: "se llama Toby"},
+		{Kind: dialogs.QuestionTrueFalse, Prompt: "Luis vive en un piso.", Answer: 1, Turn: 1, Evidence: "vivo en una casa grande"},
+		{Kind: dialogs.QuestionMultipleChoice, Prompt: "¿Quién tiene un perro?", Options: []string{"Luis", "Ana", "Marta"}, Answer: 1, Turn: 0, Evidence: "Mi perro"},
+	}
+	invalid := []dialogs.QuizQuestion{
+		// The right option is not in the turn.
+		{Kind: dialogs.QuestionMultipleChoice, Prompt: "¿Cómo se llama el perro?", Options: []string{"Rex", "Toby", "Max"}, Answer: 0, Turn: 0, Evidence: "se llama Toby"},
+		// The evidence is made up.
+		{Kind: dialogs.QuestionTrueFalse, Prompt: "Luis vive en un piso.", Answer: 1, Turn: 1, Evidence: "vivo en un piso"},
+		// Too few options, a turn out of range and a repeated option.
+		{Kind: dialogs.QuestionMultipleChoice, Prompt: "¿Quién?", Options: []string{"Ana", "Luis"}, Answer: 0, Turn: 0, Evidence: "Mi perro"},
+		{Kind: dialogs.QuestionTrueFalse, Prompt: "Ana habla.", Answer: 0, Turn: 2, Evidence: "Mi perro"},
+		{Kind: dialogs.QuestionMultipleChoice, Prompt: "¿Quién?", Options: []string{"Ana", "ana", "Luis"}, Answer: 0, Turn: 0, Evidence: "Mi perro"},
+	}
+
+	client := &quizLLM{StubClient: llm.NewStubClient(testLogger()), quizzes: [][]dialogs.QuizQuestion{invalid, append(invalid, valid...)}}
+	repo := newMemoryRepo()
+	require.NoError(t, repo.Create(ctx, dlg))
+	svc := dialogs.NewService(repo, client, tts.NewStubClient(), newMemoryAudio(), nil)
+
+	quiz, err := svc.GenerateQuiz(ctx, dlg.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, client.calls)
+	require.Equal(t, valid, quiz.Questions)
+
+	client.calls = 0
+	client.quizzes = [][]dialogs.QuizQuestion{invalid}
+	_, err = svc.GenerateQuiz(ctx, dlg.ID)
+	require.Error(t, err)
+	require.Equal(t, 2, client.calls)
+}
+
+func TestGenerateQuizRequiresQuizWriter(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryRepo()
+	dlg := dialogs.Dialog{ID: uuid.New(), Turns: []dialogs.DialogTurn{{Speaker: "Ana", Text: "Hola."}}}
+	require.NoError(t, repo.Create(ctx, dlg))
+	svc := dialogs.NewService(repo, &scriptedLLM{dialogs: []dialogs.Dialog{dlg}}, tts.NewStubClient(), newMemoryAudio(), nil)
+	_, err := svc.GenerateQuiz(ctx, dlg.ID)
+	require.ErrorIs(t, err, dialogs.ErrQuizUnsupported)
+}
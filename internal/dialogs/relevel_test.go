@@ -0,0 +1,103 @@
+package dialogs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+	"leveltalk/internal/tts"
+)
+
+// recordingLLM delegates to the stub client and keeps the params it saw.
+type recordingLLM struct {
+	dialogs.LLMClient
+	params []dialogs.GenerateDialogParams
+}
+
+func (r *recordingLLM) GenerateDialog(ctx context.Context, params dialogs.GenerateDialogParams) (dialogs.Dialog, error) {
+	r.params = append(r.params, params)
+	return r.LLMClient.GenerateDialog(ctx, params)
+}
+
+func TestRelevelDialogStoresLinkedRewrite(t *testing.T) {
+	ctx := context.Background()
+	llmClient := &recordingLLM{LLMClient: llm.NewStubClient(testLogger())}
+	svc := dialogs.NewService(newMemoryRepo(), llmClient, tts.NewStubClient(), newMemoryAudio(), nil)
+
+	source, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	rewrite, err := svc.RelevelDialog(ctx, source.ID, "b2")
+	require.NoError(t, err)
+	require.Equal(t, "B2", rewrite.CEFRLevel)
+	require.Equal(t, source.InputWords, rewrite.InputWords)
+	require.True(t, rewrite.ParentID.Valid)
+	require.Equal(t, source.ID, rewrite.ParentID.UUID)
+	for _, turn := range rewrite.Turns {
+		require.NotEmpty(t, turn.AudioURL)
+	}
+
+	require.Len(t, llmClient.params, 2)
+	sent := llmClient.params[1]
+	require.Equal(t, "B2", sent.CEFRLevel)
+	require.NotNil(t, sent.Source)
+	require.Equal(t, source.ID, sent.Source.ID)
+
+	again, err := svc.RelevelDialog(ctx, rewrite.ID, "C1")
+	require.NoError(t, err)
+
+	levels, err := svc.ListDialogLevels(ctx, rewrite.ID)
+	require.NoError(t, err)
+	require.Len(t, levels, 2)
+	require.Equal(t, "A2", levels[0].CEFRLevel)
+	require.Equal(t, again.ID, levels[1].ID)
+}
+
+func TestRelevelDialogRejectsBadLevels(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestService(nil)
+	source, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	_, err = svc.RelevelDialog(ctx, source.ID, "D1")
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+	_, err = svc.RelevelDialog(ctx, source.ID, "A2")
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+	_, err = svc.EnqueueRelevel(ctx, source.ID, "A2")
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+}
+
+func TestEnqueueRelevelRunsAsJob(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, jobs := newTestService(nil)
+	source, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	job, err := svc.EnqueueRelevel(ctx, source.ID, "b1")
+	require.NoError(t, err)
+	require.Equal(t, dialogs.JobRelevel, job.Kind)
+	require.Equal(t, source.ID, *job.ParentID)
+	require.Equal(t, "B1", job.Input.CEFRLevel)
+	require.Equal(t, source.InputWords, job.Input.InputWords)
+
+	_, events, cancel := svc.Subscribe(job.ID)
+	defer cancel()
+	require.NoError(t, svc.ProcessNextJob(ctx))
+
+	var last dialogs.Event
+	for ev := range events {
+		last = ev
+	}
+	require.Equal(t, dialogs.EventPersisted, last.Type)
+
+	done, err := jobs.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, dialogs.JobDone, done.State)
+	rewrite, err := repo.GetByID(ctx, *done.DialogID)
+	require.NoError(t, err)
+	require.Equal(t, "B1", rewrite.CEFRLevel)
+	require.Equal(t, source.ID, rewrite.ParentID.UUID)
+}
@@ -0,0 +1,231 @@
+package dialogs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoReviewDue signals that no vocabulary is due for review.
+var ErrNoReviewDue = errors.New("no review due")
+
+const (
+	// DefaultReviewEase is the SM-2 ease factor of a new review item.
+	DefaultReviewEase = 2.5
+	minReviewEase     = 1.3
+
+	// relearnDelay is how soon a forgotten word comes back.
+	relearnDelay = 10 * time.Minute
+)
+
+// ReviewGrade is how well the learner remembered a review item.
+type ReviewGrade int
+
+const (
+	ReviewAgain ReviewGrade = iota + 1 // Forgotten
+	ReviewHard
+	ReviewGood
+	ReviewEasy
+)
+
+// ReviewItem schedules one input word of a dialog for spaced repetition.
+// The translation and example turn are read from the dialog when the item is
+// shown, so edits to the dialog carry over.
+type ReviewItem struct {
+	ID       uuid.UUID
+	DialogID uuid.UUID
+	Word     string
+
+	// SM-2 state: the ease factor, the last interval in days and the number
+	// of successful reviews in a row.
+	Ease         float64
+	IntervalDays int
+	Repetitions  int
+	Lapses       int // Times the word was forgotten after being learned
+
+	DueAt      time.Time
+	ReviewedAt time.Time // Zero until the first review
+	CreatedAt  time.Time
+}
+
+// ReviewCard is a due item together with what the review page shows.
+type ReviewCard struct {
+	Item        ReviewItem
+	DialogTitle string
+	Translation string
+	// Turn is the first turn that uses the word, nil when no turn does.
+	Turn *DialogTurn
+	// Due counts the items due now, this one included.
+	Due int
+}
+
+// ReviewDay is the number of review items due on a day.
+type ReviewDay struct {
+	Date time.Time // Midnight in the server's time zone
+	Due  int
+}
+
+// ReviewStore defines the persistence contract for review items.
+type ReviewStore interface {
+	// AddItems inserts items, skipping words their dialog already has.
+	AddItems(ctx context.Context, items []ReviewItem) error
+	GetItem(ctx context.Context, id uuid.UUID) (ReviewItem, error)
+	// DueItems returns up to limit items due at now, most overdue first.
+	DueItems(ctx context.Context, now time.Time, limit int) ([]ReviewItem, error)
+	// CountDue counts the items due before until.
+	CountDue(ctx context.Context, until time.Time) (int, error)
+	// UpdateSchedule stores the scheduling fields of item.
+	UpdateSchedule(ctx context.Context, item ReviewItem) error
+}
+
+// ScheduleReview applies grade to item with the SM-2 algorithm and returns
+// the rescheduled item. Again resets the streak and brings the word back
+// within minutes; the other grades grow the interval by the ease factor,
+// which Hard lowers and Easy raises.
+func ScheduleReview(item ReviewItem, grade ReviewGrade, now time.Time) ReviewItem {
+	if item.Ease == 0 {
+		item.Ease = DefaultReviewEase
+	}
+	// SM-2 rates answers from 0 to 5; Again maps to 2, an answer that was
+	// wrong but familiar once shown.
+	quality := float64(grade) + 1
+	item.Ease = math.Max(minReviewEase, item.Ease+0.1-(5-quality)*(0.08+(5-quality)*0.02))
+	item.ReviewedAt = now
+
+	if grade == ReviewAgain {
+		if item.Repetitions > 0 {
+			item.Lapses++
+		}
+		item.Repetitions = 0
+		item.IntervalDays = 0
+		item.DueAt = now.Add(relearnDelay)
+		return item
+	}
+
+	item.Repetitions++
+	switch item.Repetitions {
+	case 1:
+		item.IntervalDays = 1
+	case 2:
+		item.IntervalDays = 6
+	default:
+		item.IntervalDays = int(math.Round(float64(item.IntervalDays) * item.Ease))
+	}
+	if grade == ReviewEasy && item.Repetitions <= 2 {
+		item.IntervalDays = int(math.Round(float64(item.IntervalDays) * 1.5))
+	}
+	item.DueAt = now.AddDate(0, 0, item.IntervalDays)
+	return item
+}
+
+// NextReview returns the most overdue review item with its context, or
+// ErrNoReviewDue when nothing is due.
+func (s *Service) NextReview(ctx context.Context) (ReviewCard, error) {
+	now := time.Now().UTC()
+	items, err := s.review.DueItems(ctx, now, 1)
+	if err != nil {
+		return ReviewCard{}, fmt.Errorf("list due items: %w", err)
+	}
+	if len(items) == 0 {
+		return ReviewCard{}, ErrNoReviewDue
+	}
+	due, err := s.review.CountDue(ctx, now)
+	if err != nil {
+		return ReviewCard{}, fmt.Errorf("count due items: %w", err)
+	}
+
+	dlg, err := s.repo.GetByID(ctx, items[0].DialogID)
+	if err != nil {
+		return ReviewCard{}, err
+	}
+	card := ReviewCard{
+		Item:        items[0],
+		DialogTitle: dlg.Title,
+		Translation: dlg.Translations[items[0].Word],
+		Due:         max(due, 1),
+	}
+	if i := exampleTurn(dlg, items[0].Word); i >= 0 {
+		card.Turn = &dlg.Turns[i]
+	}
+	return card, nil
+}
+
+// GradeReview records the learner's grade for the item id and schedules its
+// next review.
+func (s *Service) GradeReview(ctx context.Context, id uuid.UUID, grade ReviewGrade) (ReviewItem, error) {
+	if grade < ReviewAgain || grade > ReviewEasy {
+		return ReviewItem{}, fmt.Errorf("%w: unknown grade %d", ErrInvalidInput, grade)
+	}
+	item, err := s.review.GetItem(ctx, id)
+	if err != nil {
+		return ReviewItem{}, err
+	}
+	item = ScheduleReview(item, grade, time.Now().UTC())
+	if err := s.review.UpdateSchedule(ctx, item); err != nil {
+		return ReviewItem{}, fmt.Errorf("update schedule: %w", err)
+	}
+	return item, nil
+}
+
+// ReviewForecast counts the review items due on each of the next days,
+// starting today in the server's time zone. Today's count includes
+// everything overdue.
+func (s *Service) ReviewForecast(ctx context.Context, days int) ([]ReviewDay, error) {
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	forecast := make([]ReviewDay, days)
+	before := 0
+	for i := range forecast {
+		date := midnight.AddDate(0, 0, i)
+		total, err := s.review.CountDue(ctx, date.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, fmt.Errorf("count due items: %w", err)
+		}
+		forecast[i] = ReviewDay{Date: date, Due: total - before}
+		before = total
+	}
+	return forecast, nil
+}
+
+// seedReview adds the input words of a new dialog to the review queue. It is
+// best effort: the dialog is already stored, so a failure is only logged.
+func (s *Service) seedReview(ctx context.Context, dlg Dialog) {
+	if s.review == nil {
+		return
+	}
+	now := time.Now().UTC()
+	items := make([]ReviewItem, 0, len(dlg.InputWords))
+	for _, word := range dlg.InputWords {
+		items = append(items, ReviewItem{
+			ID:        uuid.New(),
+			DialogID:  dlg.ID,
+			Word:      word,
+			Ease:      DefaultReviewEase,
+			DueAt:     now,
+			CreatedAt: now,
+		})
+	}
+	if err := s.review.AddItems(ctx, items); err != nil {
+		s.logger.Warn("seed review items",
+			slog.String("dialog_id", dlg.ID.String()),
+			slog.String("error", err.Error()))
+	}
+}
+
+// exampleTurn returns the index of the first turn that uses word, or -1.
+func exampleTurn(dlg Dialog, word string) int {
+	for i, turn := range dlg.Turns {
+		for _, span := range turn.Spans {
+			if span.Word == word {
+				return i
+			}
+		}
+	}
+	return -1
+}
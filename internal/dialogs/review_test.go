@@ -0,0 +1,186 @@
+package dialogs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+	"leveltalk/internal/tts"
+)
+
+// memoryReview is an in-memory dialogs.ReviewStore.
+type memoryReview struct {
+	mu    sync.Mutex
+	items map[uuid.UUID]dialogs.ReviewItem
+}
+
+func newMemoryReview() *memoryReview {
+	return &memoryReview{items: map[uuid.UUID]dialogs.ReviewItem{}}
+}
+
+func (m *memoryReview) AddItems(ctx context.Context, items []dialogs.ReviewItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, item := range items {
+		exists := false
+		for _, other := range m.items {
+			exists = exists || (other.DialogID == item.DialogID && other.Word == item.Word)
+		}
+		if !exists {
+			m.items[item.ID] = item
+		}
+	}
+	return nil
+}
+
+func (m *memoryReview) GetItem(ctx context.Context, id uuid.UUID) (dialogs.ReviewItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[id]
+	if !ok {
+		return dialogs.ReviewItem{}, dialogs.ErrNotFound
+	}
+	return item, nil
+}
+
+func (m *memoryReview) DueItems(ctx context.Context, now time.Time, limit int) ([]dialogs.ReviewItem, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var due []dialogs.ReviewItem
+	for _, item := range m.items {
+		if !item.DueAt.After(now) {
+			due = append(due, item)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].DueAt.Before(due[j].DueAt) })
+	return due[:min(limit, len(due))], nil
+}
+
+func (m *memoryReview) CountDue(ctx context.Context, until time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for _, item := range m.items {
+		if item.DueAt.Before(until) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryReview) UpdateSchedule(ctx context.Context, item dialogs.ReviewItem) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.items[item.ID]; !ok {
+		return dialogs.ErrNotFound
+	}
+	m.items[item.ID] = item
+	return nil
+}
+
+func TestScheduleReviewFollowsSM2(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	item := dialogs.ReviewItem{Ease: dialogs.DefaultReviewEase}
+
+	item = dialogs.ScheduleReview(item, dialogs.ReviewGood, now)
+	require.Equal(t, 1, item.IntervalDays)
+	require.Equal(t, now.AddDate(0, 0, 1), item.DueAt)
+	require.InDelta(t, 2.5, item.Ease, 1e-9)
+
+	item = dialogs.ScheduleReview(item, dialogs.ReviewGood, now)
+	require.Equal(t, 6, item.IntervalDays)
+
+	item = dialogs.ScheduleReview(item, dialogs.ReviewEasy, now)
+	require.InDelta(t, 2.6, item.Ease, 1e-9)
+	require.Equal(t, 16, item.IntervalDays)
+
+	item = dialogs.ScheduleReview(item, dialogs.ReviewAgain, now)
+	require.Equal(t, 0, item.Repetitions)
+	require.Equal(t, 1, item.Lapses)
+	require.Equal(t, now.Add(10*time.Minute), item.DueAt)
+	require.InDelta(t, 2.28, item.Ease, 1e-9)
+
+	// The ease factor never drops below 1.3.
+	for range 10 {
+		item = dialogs.ScheduleReview(item, dialogs.ReviewAgain, now)
+	}
+	require.InDelta(t, 1.3, item.Ease, 1e-9)
+	require.Equal(t, 1, item.Lapses)
+}
+
+// failingReview is a ReviewStore that cannot store items.
+type failingReview struct {
+	*memoryReview
+}
+
+func (failingReview) AddItems(ctx context.Context, items []dialogs.ReviewItem) error {
+	return errors.New("connection reset")
+}
+
+func TestCreateDialogLogsFailedReviewSeed(t *testing.T) {
+	var logs bytes.Buffer
+	svc := dialogs.NewService(newMemoryRepo(), llm.NewStubClient(testLogger()), tts.NewStubClient(), newMemoryAudio(), &dialogs.ServiceOptions{
+		Logger: slog.New(slog.NewTextHandler(&logs, nil)),
+		Review: failingReview{newMemoryReview()},
+	})
+
+	dlg, err := svc.CreateDialog(context.Background(), testInput)
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "seed review items")
+	require.Contains(t, logs.String(), dlg.ID.String())
+	require.Contains(t, logs.String(), "connection reset")
+}
+
+func TestReviewQueueFollowsNewDialogs(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryReview()
+	svc := dialogs.NewService(newMemoryRepo(), llm.NewStubClient(testLogger()), tts.NewStubClient(), newMemoryAudio(), &dialogs.ServiceOptions{Review: store})
+
+	_, err := svc.NextReview(ctx)
+	require.ErrorIs(t, err, dialogs.ErrNoReviewDue)
+
+	dlg, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	require.Len(t, store.items, len(dlg.InputWords))
+
+	forecast, err := svc.ReviewForecast(ctx, 3)
+	require.NoError(t, err)
+	require.Len(t, forecast, 3)
+	require.Equal(t, len(dlg.InputWords), forecast[0].Due)
+	require.Zero(t, forecast[1].Due+forecast[2].Due)
+	require.Equal(t, forecast[0].Date.AddDate(0, 0, 1), forecast[1].Date)
+
+	card, err := svc.NextReview(ctx)
+	require.NoError(t, err)
+	require.Equal(t, dlg.ID, card.Item.DialogID)
+	require.Equal(t, dlg.Translations[card.Item.Word], card.Translation)
+	require.Equal(t, len(dlg.InputWords), card.Due)
+	require.NotNil(t, card.Turn)
+	var used []string
+	for _, span := range card.Turn.Spans {
+		used = append(used, span.Word)
+	}
+	require.Contains(t, used, card.Item.Word)
+
+	item, err := svc.GradeReview(ctx, card.Item.ID, dialogs.ReviewGood)
+	require.NoError(t, err)
+	require.Equal(t, 1, item.IntervalDays)
+
+	forecast, err = svc.ReviewForecast(ctx, 3)
+	require.NoError(t, err)
+	require.Equal(t, len(dlg.InputWords)-1, forecast[0].Due)
+	require.Equal(t, 1, forecast[1].Due)
+
+	_, err = svc.GradeReview(ctx, card.Item.ID, dialogs.ReviewGrade(9))
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+}
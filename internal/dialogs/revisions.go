@@ -0,0 +1,272 @@
+package dialogs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DialogRevision is an earlier version of a dialog's editable content. Every
+// Repository.Update keeps the content it replaces as the next revision,
+// numbered from 1.
+type DialogRevision struct {
+	DialogID     uuid.UUID
+	Number       int
+	Title        string
+	Translations map[string]string
+	Turns        []DialogTurn
+	CreatedAt    time.Time // When this content was replaced
+}
+
+// DialogEdit lists changes to a dialog. Nil or empty fields keep the current
+// value.
+type DialogEdit struct {
+	Title        *string
+	Speakers     map[string]string    // Current speaker name -> new name
+	Turns        map[uuid.UUID]string // Turn ID -> new text
+	Translations map[string]string    // Input word -> new translation
+}
+
+// EditDialog applies edit to the dialog id and stores the result, keeping
+// the previous content as a revision. Only turns whose text changed are
+// synthesized again; renaming a speaker keeps the voice. An edit that changes
+// nothing returns the dialog without creating a revision.
+func (s *Service) EditDialog(ctx context.Context, id uuid.UUID, edit DialogEdit) (Dialog, error) {
+	dlg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Dialog{}, err
+	}
+
+	changed := false
+	if edit.Title != nil {
+		title := strings.TrimSpace(*edit.Title)
+		if title == "" && dlg.Title != "" {
+			return Dialog{}, fmt.Errorf("%w: title is empty", ErrInvalidInput)
+		}
+		if title != dlg.Title {
+			dlg.Title = title
+			changed = true
+		}
+	}
+
+	if len(edit.Speakers) > 0 {
+		before := len(dlg.Speakers())
+		renamed := make([]string, len(dlg.Turns))
+		for i, turn := range dlg.Turns {
+			renamed[i] = turn.Speaker
+			name, ok := edit.Speakers[turn.Speaker]
+			if !ok {
+				continue
+			}
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return Dialog{}, fmt.Errorf("%w: speaker name is empty", ErrInvalidInput)
+			}
+			renamed[i] = name
+		}
+		for i := range dlg.Turns {
+			if dlg.Turns[i].Speaker != renamed[i] {
+				dlg.Turns[i].Speaker = renamed[i]
+				changed = true
+			}
+		}
+		if len(dlg.Speakers()) < before {
+			return Dialog{}, fmt.Errorf("%w: two speakers cannot share a name", ErrInvalidInput)
+		}
+	}
+
+	for word, translation := range edit.Translations {
+		if !slices.Contains(dlg.InputWords, word) {
+			return Dialog{}, fmt.Errorf("%w: %q is not part of the vocabulary", ErrInvalidInput, word)
+		}
+		translation = strings.TrimSpace(translation)
+		if dlg.Translations[word] != translation {
+			dlg.Translations[word] = translation
+			changed = true
+		}
+	}
+
+	var edited []int
+	for turnID, text := range edit.Turns {
+		i := turnIndex(dlg.Turns, turnID)
+		if i < 0 {
+			return Dialog{}, fmt.Errorf("%w: unknown turn %s", ErrInvalidInput, turnID)
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return Dialog{}, fmt.Errorf("%w: turn text is empty", ErrInvalidInput)
+		}
+		if text == dlg.Turns[i].Text {
+			continue
+		}
+		// The old gloss and audio describe the old text.
+		turn := &dlg.Turns[i]
+		turn.Text = text
+		turn.Translation = ""
+		turn.AudioKey = ""
+		turn.AudioURL = ""
+		turn.AudioPending = false
+		edited = append(edited, i)
+	}
+
+	if !changed && len(edited) == 0 {
+		return dlg, nil
+	}
+	return s.saveRevised(ctx, dlg, edited)
+}
+
+// saveRevised synthesizes the turns of dlg at edited, rechecks it and stores
+// it with Repository.Update.
+func (s *Service) saveRevised(ctx context.Context, dlg Dialog, edited []int) (Dialog, error) {
+	s.castVoices(&dlg)
+	if err := s.synthesizeTurns(ctx, &dlg, edited); err != nil {
+		return Dialog{}, fmt.Errorf("tts synthesize: %w", err)
+	}
+	added, err := s.storeRevisedAudio(ctx, &dlg)
+	if err != nil {
+		return Dialog{}, fmt.Errorf("store audio: %w", err)
+	}
+	s.recheck(&dlg)
+
+	if err := s.repo.Update(ctx, dlg); err != nil {
+		s.deleteAudio(ctx, added)
+		return Dialog{}, fmt.Errorf("persist dialog: %w", err)
+	}
+	return dlg, nil
+}
+
+// ListRevisions returns the earlier versions of a dialog, newest first.
+func (s *Service) ListRevisions(ctx context.Context, id uuid.UUID) ([]DialogRevision, error) {
+	return s.repo.ListRevisions(ctx, id)
+}
+
+// GetRevision fetches one earlier version of a dialog. Revisions do not
+// store vocabulary spans, so they are found again for its turns.
+func (s *Service) GetRevision(ctx context.Context, id uuid.UUID, number int) (DialogRevision, error) {
+	rev, err := s.repo.GetRevision(ctx, id, number)
+	if err != nil {
+		return DialogRevision{}, err
+	}
+	dlg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return DialogRevision{}, err
+	}
+
+	dlg.Translations = rev.Translations
+	dlg.Turns = rev.Turns
+	coverage := s.VerifyCoverage(dlg)
+	for i := range rev.Turns {
+		rev.Turns[i].Spans = coverage.Spans[i]
+	}
+	return rev, nil
+}
+
+// OpenRevisionAudio opens the stored audio of a turn as it was in revision
+// number of dialog id.
+func (s *Service) OpenRevisionAudio(ctx context.Context, id uuid.UUID, number int, turnID uuid.UUID) (AudioObject, error) {
+	rev, err := s.repo.GetRevision(ctx, id, number)
+	if err != nil {
+		return AudioObject{}, err
+	}
+	i := turnIndex(rev.Turns, turnID)
+	if i < 0 || !rev.Turns[i].HasStoredAudio() {
+		return AudioObject{}, ErrAudioNotFound
+	}
+	return s.audio.Open(ctx, rev.Turns[i].AudioKey)
+}
+
+// RestoreRevision makes revision number the current content of dialog id.
+// The content it replaces becomes a revision itself, so a restore can be
+// undone. Revisions keep their audio, so nothing is synthesized.
+func (s *Service) RestoreRevision(ctx context.Context, id uuid.UUID, number int) (Dialog, error) {
+	rev, err := s.repo.GetRevision(ctx, id, number)
+	if err != nil {
+		return Dialog{}, err
+	}
+	dlg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Dialog{}, err
+	}
+
+	dlg.Title = rev.Title
+	dlg.Translations = rev.Translations
+	if dlg.Translations == nil {
+		dlg.Translations = make(map[string]string)
+	}
+	dlg.Turns = rev.Turns
+	s.recheck(&dlg)
+
+	if err := s.repo.Update(ctx, dlg); err != nil {
+		return Dialog{}, fmt.Errorf("persist dialog: %w", err)
+	}
+	return dlg, nil
+}
+
+// synthesizeTurns synthesizes the turns of dlg at indices and leaves the
+// audio bytes on them.
+func (s *Service) synthesizeTurns(ctx context.Context, dlg *Dialog, indices []int) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	pending := *dlg
+	pending.Turns = make([]DialogTurn, len(indices))
+	for j, i := range indices {
+		pending.Turns[j] = dlg.Turns[i]
+	}
+	pending, err := s.synthesize(ctx, pending, func(Event) {})
+	if err != nil {
+		return err
+	}
+	for j, i := range indices {
+		dlg.Turns[i] = pending.Turns[j]
+	}
+	return nil
+}
+
+// storeRevisedAudio moves freshly synthesized bytes into the AudioStore like
+// storeAudio, but under new keys: the old keys may still be referenced by
+// revisions. It returns the turns whose audio was stored.
+func (s *Service) storeRevisedAudio(ctx context.Context, dlg *Dialog) ([]DialogTurn, error) {
+	var stored []DialogTurn
+	for i := range dlg.Turns {
+		turn := &dlg.Turns[i]
+		if len(turn.Audio) == 0 {
+			continue
+		}
+		key := AudioKey(dlg.ID, uuid.New())
+		if err := s.audio.Put(ctx, key, turn.Audio, audioContentType); err != nil {
+			s.deleteAudio(ctx, stored)
+			return nil, fmt.Errorf("put turn %d: %w", turn.Position, err)
+		}
+		turn.AudioKey = key
+		turn.AudioURL = ""
+		turn.Audio = nil
+		stored = append(stored, *turn)
+	}
+	return stored, nil
+}
+
+// recheck recomputes the vocabulary spans, coverage and level estimate of
+// dlg after its content changed.
+func (s *Service) recheck(dlg *Dialog) {
+	coverage := s.VerifyCoverage(*dlg)
+	for i := range dlg.Turns {
+		dlg.Turns[i].Spans = coverage.Spans[i]
+	}
+	dlg.Coverage = coverage.Ratio()
+	dlg.CoverageFlagged = dlg.Coverage < s.coverageThreshold
+	dlg.EstimatedLevel = s.EstimateLevel(*dlg)
+}
+
+func turnIndex(turns []DialogTurn, id uuid.UUID) int {
+	for i, turn := range turns {
+		if turn.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,96 @@
+package dialogs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+)
+
+func TestEditDialogResynthesizesOnlyEditedTurns(t *testing.T) {
+	ctx := context.Background()
+	repo, audio := newMemoryRepo(), newMemoryAudio()
+	speech := &countingTTS{}
+	svc := dialogs.NewService(repo, llm.NewStubClient(testLogger()), speech, audio, nil)
+
+	source, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	speech.turns = 0
+
+	title := "En el parque"
+	edited := source.Turns[1]
+	dlg, err := svc.EditDialog(ctx, source.ID, dialogs.DialogEdit{
+		Title:        &title,
+		Speakers:     map[string]string{source.Turns[0].Speaker: "Marta"},
+		Turns:        map[uuid.UUID]string{edited.ID: "Mi perro vive en una casa."},
+		Translations: map[string]string{"perro": "perrito"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, speech.turns)
+
+	require.Equal(t, "En el parque", dlg.Title)
+	require.Equal(t, "Marta", dlg.Turns[0].Speaker)
+	require.Equal(t, source.Turns[0].AudioKey, dlg.Turns[0].AudioKey)
+	require.Equal(t, source.Turns[0].VoiceID, dlg.Turns[0].VoiceID)
+	require.Equal(t, edited.ID, dlg.Turns[1].ID)
+	require.Equal(t, "Mi perro vive en una casa.", dlg.Turns[1].Text)
+	require.NotEqual(t, edited.AudioKey, dlg.Turns[1].AudioKey)
+	require.Empty(t, dlg.Turns[1].Translation)
+	require.Equal(t, "perrito", dlg.Translations["perro"])
+
+	revisions, err := svc.ListRevisions(ctx, source.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, source.Title, revisions[0].Title)
+
+	// The old audio stays available for the revision.
+	_, err = audio.Open(ctx, edited.AudioKey)
+	require.NoError(t, err)
+
+	// Snapshots keep no spans; they are found again for display.
+	rev, err := svc.GetRevision(ctx, source.ID, 1)
+	require.NoError(t, err)
+	for i, turn := range rev.Turns {
+		require.Equal(t, source.Turns[i].Spans, turn.Spans, "turn %d", i)
+	}
+
+	restored, err := svc.RestoreRevision(ctx, source.ID, 1)
+	require.NoError(t, err)
+	require.Equal(t, source.Title, restored.Title)
+	require.Equal(t, edited.Text, restored.Turns[1].Text)
+	require.Equal(t, edited.AudioKey, restored.Turns[1].AudioKey)
+	require.Equal(t, 1, speech.turns)
+
+	revisions, err = svc.ListRevisions(ctx, source.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	require.Equal(t, "En el parque", revisions[0].Title)
+}
+
+func TestEditDialogRejectsInvalidEdits(t *testing.T) {
+	ctx := context.Background()
+	svc := dialogs.NewService(newMemoryRepo(), llm.NewStubClient(testLogger()), &countingTTS{}, newMemoryAudio(), nil)
+	dlg, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	for name, edit := range map[string]dialogs.DialogEdit{
+		"merged speakers": {Speakers: map[string]string{dlg.Turns[0].Speaker: dlg.Turns[1].Speaker}},
+		"empty text":      {Turns: map[uuid.UUID]string{dlg.Turns[0].ID: " "}},
+		"unknown turn":    {Turns: map[uuid.UUID]string{uuid.New(): "Hola."}},
+		"unknown word":    {Translations: map[string]string{"gato": "gato"}},
+	} {
+		_, err := svc.EditDialog(ctx, dlg.ID, edit)
+		require.ErrorIs(t, err, dialogs.ErrInvalidInput, name)
+	}
+
+	unchanged, err := svc.EditDialog(ctx, dlg.ID, dialogs.DialogEdit{Title: &dlg.Title})
+	require.NoError(t, err)
+	require.Equal(t, dlg.Title, unchanged.Title)
+	revisions, err := svc.ListRevisions(ctx, dlg.ID)
+	require.NoError(t, err)
+	require.Empty(t, revisions)
+}
@@ -0,0 +1,470 @@
+package dialogs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ErrRolePlayUnsupported signals that the configured LLM client cannot play
+// a role-play partner.
+var ErrRolePlayUnsupported = errors.New("LLM client does not support role-play")
+
+// MaxRolePlayMessageLength bounds a learner message, in characters.
+const MaxRolePlayMessageLength = 500
+
+// RolePlaySession is a conversation in which the learner speaks as one
+// speaker and the LLM plays the other.
+type RolePlaySession struct {
+	ID             uuid.UUID
+	InputLanguage  string
+	DialogLanguage string
+	CEFRLevel      string
+	Scenario       string
+	InputWords     []string
+	LearnerSpeaker string
+	PartnerSpeaker string
+	PartnerGender  string // "female", "male" or empty
+	PartnerVoiceID string // TTS voice cast for the partner once, at the start
+	CreatedAt      time.Time
+
+	// DialogID is set once the session was converted into a dialog; the
+	// session takes no further messages after that.
+	DialogID uuid.NullUUID
+
+	// Messages is filled by RolePlayStore.GetSession, ordered by Position.
+	Messages []RolePlayMessage
+}
+
+// Converted reports whether the session was turned into a dialog.
+func (s RolePlaySession) Converted() bool {
+	return s.DialogID.Valid
+}
+
+// RolePlayMessage is a single line of a role-play session.
+type RolePlayMessage struct {
+	ID       uuid.UUID
+	Position int
+	Speaker  string
+	Learner  bool // Written by the learner rather than the LLM partner
+	Text     string
+	AudioURL string // Placeholder URL when no stored audio exists
+	AudioKey string // Key of the partner's synthesized audio inside the AudioStore
+
+	// Correction rewrites a learner message the way a native speaker would
+	// say it, and Note explains the change in the input language. Both are
+	// empty when the message needed no correction.
+	Correction string
+	Note       string
+	CreatedAt  time.Time
+}
+
+// HasStoredAudio reports whether the message's audio lives in the AudioStore.
+func (m RolePlayMessage) HasStoredAudio() bool {
+	return m.AudioKey != ""
+}
+
+// RolePlayInput collects what the learner chooses when starting a session.
+type RolePlayInput struct {
+	InputLanguage  string
+	DialogLanguage string
+	CEFRLevel      string
+	Scenario       string
+	InputWords     []string
+	LearnerSpeaker string
+	PartnerSpeaker string
+	PartnerGender  string
+}
+
+// RolePlayParams describe the request to a RolePlayer.
+type RolePlayParams struct {
+	InputLanguage  string
+	DialogLanguage string
+	CEFRLevel      string
+	Scenario       string
+	InputWords     []string
+	LearnerSpeaker string
+	PartnerSpeaker string
+	PartnerGender  string
+
+	// History holds the session so far. When it ends with a learner message
+	// the partner answers it; when it is empty the partner opens the scene.
+	History []RolePlayMessage
+}
+
+// RolePlayReply is the partner's next line together with the correction of
+// the learner message it answers.
+type RolePlayReply struct {
+	Text       string
+	Correction string
+	Note       string
+}
+
+// RolePlayer is an optional LLMClient extension for clients that can play
+// the partner of a role-play session.
+type RolePlayer interface {
+	RolePlay(ctx context.Context, params RolePlayParams) (RolePlayReply, error)
+}
+
+// RolePlayStore defines the persistence contract for role-play sessions.
+type RolePlayStore interface {
+	CreateSession(ctx context.Context, session RolePlaySession) error
+	// GetSession returns the session with its messages.
+	GetSession(ctx context.Context, id uuid.UUID) (RolePlaySession, error)
+	// ListSessions returns the newest sessions without their messages.
+	ListSessions(ctx context.Context, limit int) ([]RolePlaySession, error)
+	AddMessages(ctx context.Context, sessionID uuid.UUID, messages []RolePlayMessage) error
+	SetSessionDialog(ctx context.Context, sessionID, dialogID uuid.UUID) error
+}
+
+// StartRolePlay validates input, lets the partner open the scene and
+// persists the new session with that first message.
+func (s *Service) StartRolePlay(ctx context.Context, input RolePlayInput) (RolePlaySession, error) {
+	input.Scenario = strings.TrimSpace(input.Scenario)
+	input.LearnerSpeaker = strings.TrimSpace(input.LearnerSpeaker)
+	input.PartnerSpeaker = strings.TrimSpace(input.PartnerSpeaker)
+	if err := validateRolePlayInput(input); err != nil {
+		return RolePlaySession{}, fmt.Errorf("validate input: %w", err)
+	}
+
+	session := RolePlaySession{
+		ID:             uuid.New(),
+		InputLanguage:  input.InputLanguage,
+		DialogLanguage: input.DialogLanguage,
+		CEFRLevel:      input.CEFRLevel,
+		Scenario:       input.Scenario,
+		InputWords:     input.InputWords,
+		LearnerSpeaker: input.LearnerSpeaker,
+		PartnerSpeaker: input.PartnerSpeaker,
+		PartnerGender:  input.PartnerGender,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if session.InputWords == nil {
+		session.InputWords = []string{}
+	}
+	if s.voices != nil {
+		session.PartnerVoiceID = s.voices.CastVoices(session.dialog())[session.PartnerSpeaker]
+	}
+
+	opening, err := s.partnerReply(ctx, session)
+	if err != nil {
+		return RolePlaySession{}, err
+	}
+
+	if err := s.rolePlay.CreateSession(ctx, session); err != nil {
+		return RolePlaySession{}, fmt.Errorf("persist session: %w", err)
+	}
+	if err := s.addRolePlayMessages(ctx, &session, opening); err != nil {
+		return RolePlaySession{}, err
+	}
+	return session, nil
+}
+
+// SendRolePlayMessage records a learner message, asks the partner to answer
+// it and returns the session with both messages appended. The learner
+// message carries the partner's correction; the answer carries its audio.
+func (s *Service) SendRolePlayMessage(ctx context.Context, id uuid.UUID, text string) (RolePlaySession, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return RolePlaySession{}, fmt.Errorf("%w: message is empty", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(text) > MaxRolePlayMessageLength {
+		return RolePlaySession{}, fmt.Errorf("%w: messages are limited to %d characters", ErrInvalidInput, MaxRolePlayMessageLength)
+	}
+
+	session, err := s.rolePlay.GetSession(ctx, id)
+	if err != nil {
+		return RolePlaySession{}, err
+	}
+	if session.Converted() {
+		return RolePlaySession{}, fmt.Errorf("%w: session was already turned into a dialog", ErrInvalidInput)
+	}
+
+	learner := RolePlayMessage{
+		ID:        uuid.New(),
+		Position:  len(session.Messages),
+		Speaker:   session.LearnerSpeaker,
+		Learner:   true,
+		Text:      text,
+		CreatedAt: time.Now().UTC(),
+	}
+	asked := session
+	asked.Messages = append(session.Messages[:len(session.Messages):len(session.Messages)], learner)
+
+	answer, err := s.partnerReply(ctx, asked)
+	if err != nil {
+		return RolePlaySession{}, err
+	}
+	learner.Correction, learner.Note = answer.Correction, answer.Note
+	answer.Correction, answer.Note = "", ""
+	answer.Position = learner.Position + 1
+
+	if err := s.addRolePlayMessages(ctx, &session, learner, answer); err != nil {
+		return RolePlaySession{}, err
+	}
+	return session, nil
+}
+
+// GetRolePlay fetches a session with its messages.
+func (s *Service) GetRolePlay(ctx context.Context, id uuid.UUID) (RolePlaySession, error) {
+	return s.rolePlay.GetSession(ctx, id)
+}
+
+// ListRolePlays returns the most recent sessions, without messages.
+func (s *Service) ListRolePlays(ctx context.Context, limit int) ([]RolePlaySession, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	return s.rolePlay.ListSessions(ctx, limit)
+}
+
+// ConvertRolePlay stores the session as a regular dialog. Learner messages
+// enter the dialog in their corrected form and are synthesized; the partner's
+// lines keep the audio they were played with. Converting a session twice
+// returns the dialog of the first conversion.
+func (s *Service) ConvertRolePlay(ctx context.Context, id uuid.UUID) (Dialog, error) {
+	session, err := s.rolePlay.GetSession(ctx, id)
+	if err != nil {
+		return Dialog{}, err
+	}
+	if session.Converted() {
+		return s.repo.GetByID(ctx, session.DialogID.UUID)
+	}
+	if !session.hasLearnerMessage() {
+		return Dialog{}, fmt.Errorf("%w: the session has no learner messages yet", ErrInvalidInput)
+	}
+
+	dlg := session.dialog()
+	dlg.ID = uuid.New()
+	dlg.CreatedAt = time.Now().UTC()
+	s.castVoices(&dlg)
+
+	coverage := s.VerifyCoverage(dlg)
+	for i := range dlg.Turns {
+		dlg.Turns[i].Spans = coverage.Spans[i]
+	}
+	dlg.Coverage = coverage.Ratio()
+	dlg.CoverageFlagged = dlg.Coverage < s.coverageThreshold
+	dlg.EstimatedLevel = s.EstimateLevel(dlg)
+
+	// Reuse the partner audio and synthesize only what has none.
+	var missing []int
+	for i, msg := range session.Messages {
+		turn := &dlg.Turns[i]
+		if msg.HasStoredAudio() {
+			data, err := s.readAudio(ctx, msg.AudioKey)
+			if err == nil {
+				turn.Audio = data
+				continue
+			}
+		}
+		missing = append(missing, i)
+	}
+	if err := s.synthesizeTurns(ctx, &dlg, missing); err != nil {
+		return Dialog{}, fmt.Errorf("tts synthesize: %w", err)
+	}
+
+	if err := s.storeAudio(ctx, &dlg); err != nil {
+		return Dialog{}, fmt.Errorf("store audio: %w", err)
+	}
+	if err := s.repo.Create(ctx, dlg); err != nil {
+		s.deleteAudio(ctx, dlg.Turns)
+		return Dialog{}, fmt.Errorf("persist dialog: %w", err)
+	}
+	s.seedReview(ctx, dlg)
+	if err := s.rolePlay.SetSessionDialog(ctx, session.ID, dlg.ID); err != nil {
+		return Dialog{}, fmt.Errorf("link session: %w", err)
+	}
+	return dlg, nil
+}
+
+// OpenRolePlayAudio opens the stored audio of a partner message.
+func (s *Service) OpenRolePlayAudio(ctx context.Context, sessionID, messageID uuid.UUID) (AudioObject, error) {
+	session, err := s.rolePlay.GetSession(ctx, sessionID)
+	if err != nil {
+		return AudioObject{}, err
+	}
+	for _, msg := range session.Messages {
+		if msg.ID != messageID {
+			continue
+		}
+		if !msg.HasStoredAudio() {
+			return AudioObject{}, ErrAudioNotFound
+		}
+		return s.audio.Open(ctx, msg.AudioKey)
+	}
+	return AudioObject{}, ErrAudioNotFound
+}
+
+// RolePlayAudioKey returns the store key used for a partner message's audio.
+func RolePlayAudioKey(sessionID, messageID uuid.UUID) string {
+	return fmt.Sprintf("roleplay/%s/%s.mp3", sessionID, messageID)
+}
+
+// partnerReply asks the LLM for the partner's next line in session and
+// synthesizes it. The returned message still carries the correction of the
+// learner's last message for the caller to move.
+func (s *Service) partnerReply(ctx context.Context, session RolePlaySession) (RolePlayMessage, error) {
+	player, ok := s.llm.(RolePlayer)
+	if !ok {
+		return RolePlayMessage{}, ErrRolePlayUnsupported
+	}
+
+	reply, err := player.RolePlay(ctx, RolePlayParams{
+		InputLanguage:  session.InputLanguage,
+		DialogLanguage: session.DialogLanguage,
+		CEFRLevel:      session.CEFRLevel,
+		Scenario:       session.Scenario,
+		InputWords:     session.InputWords,
+		LearnerSpeaker: session.LearnerSpeaker,
+		PartnerSpeaker: session.PartnerSpeaker,
+		PartnerGender:  session.PartnerGender,
+		History:        session.Messages,
+	})
+	if err != nil {
+		return RolePlayMessage{}, fmt.Errorf("role-play reply: %w", err)
+	}
+	if strings.TrimSpace(reply.Text) == "" {
+		return RolePlayMessage{}, fmt.Errorf("role-play reply: no text returned")
+	}
+
+	msg := RolePlayMessage{
+		ID:         uuid.New(),
+		Position:   len(session.Messages),
+		Speaker:    session.PartnerSpeaker,
+		Text:       strings.TrimSpace(reply.Text),
+		Correction: strings.TrimSpace(reply.Correction),
+		Note:       strings.TrimSpace(reply.Note),
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	// A failed synthesis leaves the line without audio rather than losing it.
+	dlg := session.dialog()
+	dlg.Turns = []DialogTurn{{
+		ID:       msg.ID,
+		Speaker:  msg.Speaker,
+		Gender:   session.PartnerGender,
+		VoiceID:  session.PartnerVoiceID,
+		Text:     msg.Text,
+		Position: msg.Position,
+	}}
+	synthesized, err := s.synthesize(ctx, dlg, func(Event) {})
+	if err != nil {
+		return RolePlayMessage{}, fmt.Errorf("tts synthesize: %w", err)
+	}
+	turn := synthesized.Turns[0]
+	if len(turn.Audio) > 0 {
+		key := RolePlayAudioKey(session.ID, msg.ID)
+		if err := s.audio.Put(ctx, key, turn.Audio, audioContentType); err != nil {
+			return RolePlayMessage{}, fmt.Errorf("store audio: %w", err)
+		}
+		msg.AudioKey = key
+	} else {
+		msg.AudioURL = turn.AudioURL
+	}
+	return msg, nil
+}
+
+// addRolePlayMessages persists messages and appends them to session. Stored
+// audio of messages that could not be saved is removed again.
+func (s *Service) addRolePlayMessages(ctx context.Context, session *RolePlaySession, messages ...RolePlayMessage) error {
+	if err := s.rolePlay.AddMessages(ctx, session.ID, messages); err != nil {
+		for _, msg := range messages {
+			if msg.HasStoredAudio() {
+				_ = s.audio.Delete(ctx, msg.AudioKey)
+			}
+		}
+		return fmt.Errorf("persist messages: %w", err)
+	}
+	session.Messages = append(session.Messages, messages...)
+	return nil
+}
+
+// readAudio loads a stored audio blob into memory.
+func (s *Service) readAudio(ctx context.Context, key string) ([]byte, error) {
+	obj, err := s.audio.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Content.Close()
+	return io.ReadAll(obj.Content)
+}
+
+// dialog renders the session as a dialog with one turn per message, using
+// the corrected form of learner messages.
+func (s RolePlaySession) dialog() Dialog {
+	title := s.Scenario
+	if title == "" {
+		title = s.LearnerSpeaker + " & " + s.PartnerSpeaker
+	}
+	dlg := Dialog{
+		Title:          title,
+		InputLanguage:  s.InputLanguage,
+		DialogLanguage: s.DialogLanguage,
+		CEFRLevel:      s.CEFRLevel,
+		InputWords:     s.InputWords,
+		Translations:   make(map[string]string),
+	}
+	for _, msg := range s.Messages {
+		turn := DialogTurn{
+			ID:       uuid.New(),
+			Speaker:  msg.Speaker,
+			Text:     msg.Text,
+			Position: msg.Position,
+		}
+		if msg.Learner {
+			if msg.Correction != "" {
+				turn.Text = msg.Correction
+			}
+		} else {
+			turn.Gender = s.PartnerGender
+			turn.VoiceID = s.PartnerVoiceID
+		}
+		dlg.Turns = append(dlg.Turns, turn)
+	}
+	// Voice casting only sees speakers that have turns; make sure the
+	// partner is among them before the first message exists.
+	if len(dlg.Turns) == 0 {
+		dlg.Turns = []DialogTurn{
+			{Speaker: s.PartnerSpeaker, Gender: s.PartnerGender},
+			{Speaker: s.LearnerSpeaker},
+		}
+	}
+	return dlg
+}
+
+func (s RolePlaySession) hasLearnerMessage() bool {
+	for _, msg := range s.Messages {
+		if msg.Learner {
+			return true
+		}
+	}
+	return false
+}
+
+func validateRolePlayInput(input RolePlayInput) error {
+	if input.InputLanguage == "" || input.DialogLanguage == "" || levelIndex(input.CEFRLevel) < 0 {
+		return ErrInvalidInput
+	}
+	if input.Scenario == "" && len(input.InputWords) == 0 {
+		return fmt.Errorf("%w: a scenario or at least one word is required", ErrInvalidInput)
+	}
+	if input.LearnerSpeaker == "" || input.PartnerSpeaker == "" {
+		return fmt.Errorf("%w: both speakers need a name", ErrInvalidInput)
+	}
+	if strings.EqualFold(input.LearnerSpeaker, input.PartnerSpeaker) {
+		return fmt.Errorf("%w: the speakers need different names", ErrInvalidInput)
+	}
+	switch input.PartnerGender {
+	case "", "female", "male":
+	default:
+		return fmt.Errorf("%w: unknown partner gender %q", ErrInvalidInput, input.PartnerGender)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package dialogs_test
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+)
+
+// memoryRolePlay is an in-memory dialogs.RolePlayStore.
+type memoryRolePlay struct {
+	mu       sync.Mutex
+	sessions map[uuid.UUID]dialogs.RolePlaySession
+}
+
+func newMemoryRolePlay() *memoryRolePlay {
+	return &memoryRolePlay{sessions: map[uuid.UUID]dialogs.RolePlaySession{}}
+}
+
+func (m *memoryRolePlay) CreateSession(ctx context.Context, session dialogs.RolePlaySession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session.Messages = nil
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memoryRolePlay) GetSession(ctx context.Context, id uuid.UUID) (dialogs.RolePlaySession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[id]
+	if !ok {
+		return dialogs.RolePlaySession{}, dialogs.ErrNotFound
+	}
+	session.Messages = append([]dialogs.RolePlayMessage(nil), session.Messages...)
+	return session, nil
+}
+
+func (m *memoryRolePlay) ListSessions(ctx context.Context, limit int) ([]dialogs.RolePlaySession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sessions []dialogs.RolePlaySession
+	for _, session := range m.sessions {
+		session.Messages = nil
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.After(sessions[j].CreatedAt) })
+	return sessions[:min(limit, len(sessions))], nil
+}
+
+func (m *memoryRolePlay) AddMessages(ctx context.Context, sessionID uuid.UUID, messages []dialogs.RolePlayMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return dialogs.ErrNotFound
+	}
+	session.Messages = append(session.Messages, messages...)
+	m.sessions[sessionID] = session
+	return nil
+}
+
+func (m *memoryRolePlay) SetSessionDialog(ctx context.Context, sessionID, dialogID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session, ok := m.sessions[sessionID]
+	if !ok {
+		return dialogs.ErrNotFound
+	}
+	session.DialogID = uuid.NullUUID{UUID: dialogID, Valid: true}
+	m.sessions[sessionID] = session
+	return nil
+}
+
+var rolePlayInput = dialogs.RolePlayInput{
+	InputLanguage:  "ru",
+	DialogLanguage: "es",
+	CEFRLevel:      "A2",
+	Scenario:       "Comprar pescado en el mercado",
+	InputWords:     []string{"рыба"},
+	LearnerSpeaker: "Cliente",
+	PartnerSpeaker: "Vendedora",
+	PartnerGender:  "female",
+}
+
+func TestRolePlaySessionLifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo, audio, store := newMemoryRepo(), newMemoryAudio(), newMemoryRolePlay()
+	speech := &countingTTS{}
+	svc := dialogs.NewService(repo, llm.NewStubClient(testLogger()), speech, audio, &dialogs.ServiceOptions{RolePlay: store})
+
+	session, err := svc.StartRolePlay(ctx, rolePlayInput)
+	require.NoError(t, err)
+	require.Len(t, session.Messages, 1)
+	opening := session.Messages[0]
+	require.False(t, opening.Learner)
+	require.Equal(t, "Vendedora", opening.Speaker)
+	require.True(t, opening.HasStoredAudio())
+
+	session, err = svc.SendRolePlayMessage(ctx, session.ID, "Quiero pescado")
+	require.NoError(t, err)
+	require.Len(t, session.Messages, 3)
+	learner, answer := session.Messages[1], session.Messages[2]
+	require.True(t, learner.Learner)
+	require.Equal(t, "Quiero pescado.", learner.Correction)
+	require.NotEmpty(t, learner.Note)
+	require.Empty(t, answer.Correction)
+	require.Equal(t, 2, answer.Position)
+	require.True(t, answer.HasStoredAudio())
+
+	stored, err := svc.GetRolePlay(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Messages, 3)
+
+	speech.turns = 0
+	dlg, err := svc.ConvertRolePlay(ctx, session.ID)
+	require.NoError(t, err)
+	require.Len(t, dlg.Turns, 3)
+	require.Equal(t, "Quiero pescado.", dlg.Turns[1].Text)
+	// Only the learner's line needs new audio.
+	require.Equal(t, 1, speech.turns)
+	for _, turn := range dlg.Turns {
+		require.True(t, turn.HasStoredAudio())
+	}
+
+	again, err := svc.ConvertRolePlay(ctx, session.ID)
+	require.NoError(t, err)
+	require.Equal(t, dlg.ID, again.ID)
+
+	_, err = svc.SendRolePlayMessage(ctx, session.ID, "Gracias.")
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+}
+
+func TestStartRolePlayValidatesInput(t *testing.T) {
+	svc := dialogs.NewService(newMemoryRepo(), llm.NewStubClient(testLogger()), &countingTTS{}, newMemoryAudio(), &dialogs.ServiceOptions{RolePlay: newMemoryRolePlay()})
+
+	input := rolePlayInput
+	input.PartnerSpeaker = "cliente"
+	_, err := svc.StartRolePlay(context.Background(), input)
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+
+	input = rolePlayInput
+	input.Scenario, input.InputWords = "", nil
+	_, err = svc.StartRolePlay(context.Background(), input)
+	require.ErrorIs(t, err, dialogs.ErrInvalidInput)
+}
+
+func TestStartRolePlayNeedsCapableLLM(t *testing.T) {
+	svc := dialogs.NewService(newMemoryRepo(), &scriptedLLM{}, &countingTTS{}, newMemoryAudio(), &dialogs.ServiceOptions{RolePlay: newMemoryRolePlay()})
+	_, err := svc.StartRolePlay(context.Background(), rolePlayInput)
+	require.ErrorIs(t, err, dialogs.ErrRolePlayUnsupported)
+}
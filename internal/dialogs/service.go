@@ -3,40 +3,302 @@ package dialogs
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const (
+	audioContentType      = "audio/mpeg"
+	defaultJobMaxAttempts = 3
+	defaultTTSConcurrency = 4
+
+	// DefaultContinueTurns and MaxContinueTurns bound how many turns
+	// ContinueDialog appends at once.
+	DefaultContinueTurns = 4
+	MaxContinueTurns     = 10
+)
+
+// ServiceOptions configures optional Service collaborators.
+type ServiceOptions struct {
+	Logger         *slog.Logger // Best-effort failures are logged here; nil discards them
+	Jobs           JobQueue
+	JobMaxAttempts int
+	Events         *EventBus
+	Voices         VoiceCaster
+	TTSConcurrency int // Turns synthesized in parallel per dialog
+
+	// Stemmers match inflected vocabulary per dialog language; languages
+	// without one are matched word for word.
+	Stemmers map[string]Stemmer
+	// CoverageThreshold is the share of input words a dialog must use. Below
+	// it the dialog is regenerated up to CoverageRegenerations times and then
+	// flagged. Zero disables both.
+	CoverageThreshold     float64
+	CoverageRegenerations int
+
+	// LevelEstimator estimates the level of every generated dialog. A dialog
+	// estimated more than one level away from the request is regenerated up
+	// to LevelRegenerations times; the last estimate is stored either way.
+	LevelEstimator     LevelEstimator
+	LevelRegenerations int
+
+	// RolePlay persists role-play sessions.
+	RolePlay RolePlayStore
+	// Review persists the spaced-repetition schedule of dialog vocabulary.
+	// New dialogs are added to it when set.
+	Review ReviewStore
+	// Practice persists the learner's exercise attempts.
+	Practice PracticeStore
+}
+
 // Service orchestrates dialog generation, synthesis, and persistence.
 type Service struct {
-	repo Repository
-	llm  LLMClient
-	tts  TTSClient
+	logger *slog.Logger
+	repo   Repository
+	llm    LLMClient
+	tts    TTSClient
+	audio  AudioStore
+
+	jobs           JobQueue
+	jobMaxAttempts int
+	events         *EventBus
+	voices         VoiceCaster
+	ttsConcurrency int
+
+	stemmers              map[string]Stemmer
+	coverageThreshold     float64
+	coverageRegenerations int
+
+	levelEstimator     LevelEstimator
+	levelRegenerations int
+
+	rolePlay RolePlayStore
+	review   ReviewStore
+	practice PracticeStore
+
+	repairMu  sync.Mutex
+	repairRun AudioRepairRun
 }
 
 // NewService constructs a Service.
-func NewService(repo Repository, llm LLMClient, tts TTSClient) *Service {
+func NewService(repo Repository, llm LLMClient, tts TTSClient, audio AudioStore, opts *ServiceOptions) *Service {
+	if opts == nil {
+		opts = &ServiceOptions{}
+	}
+
+	jobMaxAttempts := opts.JobMaxAttempts
+	if jobMaxAttempts <= 0 {
+		jobMaxAttempts = defaultJobMaxAttempts
+	}
+
+	events := opts.Events
+	if events == nil {
+		events = NewEventBus()
+	}
+
+	ttsConcurrency := opts.TTSConcurrency
+	if ttsConcurrency <= 0 {
+		ttsConcurrency = defaultTTSConcurrency
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
 	return &Service{
-		repo: repo,
-		llm:  llm,
-		tts:  tts,
+		logger:         logger,
+		repo:           repo,
+		llm:            llm,
+		tts:            tts,
+		audio:          audio,
+		jobs:           opts.Jobs,
+		jobMaxAttempts: jobMaxAttempts,
+		events:         events,
+		voices:         opts.Voices,
+		ttsConcurrency: ttsConcurrency,
+
+		stemmers:              opts.Stemmers,
+		coverageThreshold:     opts.CoverageThreshold,
+		coverageRegenerations: opts.CoverageRegenerations,
+
+		levelEstimator:     opts.LevelEstimator,
+		levelRegenerations: opts.LevelRegenerations,
+
+		rolePlay: opts.RolePlay,
+		review:   opts.Review,
+		practice: opts.Practice,
 	}
 }
 
 // CreateDialog validates input, generates dialog content, synthesizes audio, and persists the result.
 func (s *Service) CreateDialog(ctx context.Context, input CreateDialogInput) (Dialog, error) {
+	return s.createDialog(ctx, input, func(Event) {})
+}
+
+// createDialog runs the full pipeline, reporting progress through emit.
+func (s *Service) createDialog(ctx context.Context, input CreateDialogInput, emit func(Event)) (Dialog, error) {
 	if err := validateCreateInput(input); err != nil {
 		return Dialog{}, fmt.Errorf("validate input: %w", err)
 	}
 
-	generated, err := s.llm.GenerateDialog(ctx, GenerateDialogParams{
+	return s.produceDialog(ctx, GenerateDialogParams{
 		InputLanguage:  input.InputLanguage,
 		DialogLanguage: input.DialogLanguage,
 		CEFRLevel:      input.CEFRLevel,
 		InputWords:     input.InputWords,
+	}, uuid.NullUUID{}, emit)
+}
+
+// RelevelDialog rewrites the dialog id at targetLevel, keeping its storyline,
+// speakers and vocabulary. The rewrite is stored as a new dialog whose
+// ParentID points at the source, with freshly synthesized audio.
+func (s *Service) RelevelDialog(ctx context.Context, id uuid.UUID, targetLevel string) (Dialog, error) {
+	return s.relevelDialog(ctx, id, targetLevel, func(Event) {})
+}
+
+// relevelDialog runs RelevelDialog, reporting progress through emit.
+func (s *Service) relevelDialog(ctx context.Context, id uuid.UUID, targetLevel string, emit func(Event)) (Dialog, error) {
+	source, targetLevel, err := s.relevelSource(ctx, id, targetLevel)
+	if err != nil {
+		return Dialog{}, err
+	}
+
+	return s.produceDialog(ctx, GenerateDialogParams{
+		InputLanguage:  source.InputLanguage,
+		DialogLanguage: source.DialogLanguage,
+		CEFRLevel:      targetLevel,
+		InputWords:     source.InputWords,
+		Source:         &source,
+	}, uuid.NullUUID{UUID: source.ID, Valid: true}, emit)
+}
+
+// relevelSource loads the dialog id to be rewritten at targetLevel, and
+// returns it with the normalized level once the rewrite makes sense.
+func (s *Service) relevelSource(ctx context.Context, id uuid.UUID, targetLevel string) (Dialog, string, error) {
+	targetLevel = strings.ToUpper(strings.TrimSpace(targetLevel))
+	if levelIndex(targetLevel) < 0 {
+		return Dialog{}, "", fmt.Errorf("%w: unknown CEFR level %q", ErrInvalidInput, targetLevel)
+	}
+
+	source, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Dialog{}, "", err
+	}
+	if strings.EqualFold(source.CEFRLevel, targetLevel) {
+		return Dialog{}, "", fmt.Errorf("%w: dialog is already at level %s", ErrInvalidInput, targetLevel)
+	}
+	return source, targetLevel, nil
+}
+
+// ContinueDialog asks the LLM to carry the conversation of dialog id on for
+// newTurns more turns, given the existing turns as context, and appends them.
+// Only the new turns are synthesized; coverage and the estimated level are
+// recomputed over the whole dialog. Zero newTurns means
+// DefaultContinueTurns.
+func (s *Service) ContinueDialog(ctx context.Context, id uuid.UUID, newTurns int) (Dialog, error) {
+	return s.continueDialog(ctx, id, newTurns, func(Event) {})
+}
+
+// continueDialog runs ContinueDialog, reporting progress through emit.
+func (s *Service) continueDialog(ctx context.Context, id uuid.UUID, newTurns int, emit func(Event)) (Dialog, error) {
+	newTurns, err := continueTurns(newTurns)
+	if err != nil {
+		return Dialog{}, err
+	}
+
+	dlg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Dialog{}, err
+	}
+
+	emit(Event{Type: EventPromptSent})
+	generated, err := s.generate(ctx, GenerateDialogParams{
+		InputLanguage:  dlg.InputLanguage,
+		DialogLanguage: dlg.DialogLanguage,
+		CEFRLevel:      dlg.CEFRLevel,
+		InputWords:     dlg.InputWords,
+		Prior:          dlg.Turns,
+		NewTurns:       newTurns,
+	}, emit)
+	if err != nil {
+		return Dialog{}, fmt.Errorf("generate continuation: %w", err)
+	}
+	if len(generated.Turns) == 0 {
+		return Dialog{}, fmt.Errorf("generate continuation: no turns returned")
+	}
+
+	first := len(dlg.Turns)
+	for i, turn := range generated.Turns {
+		turn.ID = uuid.New()
+		turn.Position = first + i
+		turn.VoiceID = ""
+		dlg.Turns = append(dlg.Turns, turn)
+	}
+	s.castVoices(&dlg)
+
+	coverage := s.VerifyCoverage(dlg)
+	for i := first; i < len(dlg.Turns); i++ {
+		dlg.Turns[i].Spans = coverage.Spans[i]
+	}
+	dlg.Coverage = coverage.Ratio()
+	dlg.CoverageFlagged = dlg.Coverage < s.coverageThreshold
+	dlg.EstimatedLevel = s.EstimateLevel(dlg)
+	emit(Event{Type: EventTurnsParsed, Title: dlg.Title, TurnCount: len(generated.Turns)})
+
+	added := dlg
+	added.Turns = dlg.Turns[first:]
+	added, err = s.synthesize(ctx, added, emit)
+	if err != nil {
+		return Dialog{}, fmt.Errorf("tts synthesize: %w", err)
+	}
+	if err := s.storeAudio(ctx, &added); err != nil {
+		return Dialog{}, fmt.Errorf("store audio: %w", err)
+	}
+	dlg.Turns = append(dlg.Turns[:first], added.Turns...)
+
+	if err := s.repo.AppendTurns(ctx, dlg, first); err != nil {
+		s.deleteAudio(ctx, added.Turns)
+		return Dialog{}, fmt.Errorf("persist continuation: %w", err)
+	}
+	emit(Event{Type: EventPersisted, DialogID: &dlg.ID})
+	return dlg, nil
+}
+
+// continueTurns checks the number of turns a continuation asks for, where
+// zero means DefaultContinueTurns.
+func continueTurns(newTurns int) (int, error) {
+	if newTurns == 0 {
+		newTurns = DefaultContinueTurns
+	}
+	if newTurns < 1 || newTurns > MaxContinueTurns {
+		return 0, fmt.Errorf("%w: between 1 and %d turns can be added at once", ErrInvalidInput, MaxContinueTurns)
+	}
+	return newTurns, nil
+}
+
+// ListDialogLevels returns the other versions of a dialog created with
+// RelevelDialog, whichever of them id is.
+func (s *Service) ListDialogLevels(ctx context.Context, id uuid.UUID) ([]Dialog, error) {
+	family, err := s.repo.ListFamily(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(family, func(i, j int) bool {
+		return levelIndex(family[i].CEFRLevel) < levelIndex(family[j].CEFRLevel)
 	})
+	return family, nil
+}
+
+// produceDialog generates, synthesizes and persists a dialog for params.
+func (s *Service) produceDialog(ctx context.Context, params GenerateDialogParams, parentID uuid.NullUUID, emit func(Event)) (Dialog, error) {
+	generated, coverage, err := s.generateChecked(ctx, params, emit)
 	if err != nil {
 		return Dialog{}, fmt.Errorf("generate dialog: %w", err)
 	}
@@ -45,13 +307,20 @@ func (s *Service) CreateDialog(ctx context.Context, input CreateDialogInput) (Di
 	dlg := Dialog{
 		ID:             uuid.New(),
 		Title:          generated.Title,
-		InputLanguage:  input.InputLanguage,
-		DialogLanguage: input.DialogLanguage,
-		CEFRLevel:      input.CEFRLevel,
-		InputWords:     input.InputWords,
+		InputLanguage:  params.InputLanguage,
+		DialogLanguage: params.DialogLanguage,
+		CEFRLevel:      params.CEFRLevel,
+		InputWords:     params.InputWords,
 		Translations:   generated.Translations,
 		Turns:          generated.Turns,
 		CreatedAt:      now,
+		RepairAttempts: generated.RepairAttempts,
+
+		Coverage:        coverage.Ratio(),
+		CoverageFlagged: coverage.Ratio() < s.coverageThreshold,
+
+		EstimatedLevel: generated.EstimatedLevel,
+		ParentID:       parentID,
 	}
 	if dlg.Translations == nil {
 		dlg.Translations = make(map[string]string)
@@ -62,21 +331,246 @@ func (s *Service) CreateDialog(ctx context.Context, input CreateDialogInput) (Di
 			dlg.Turns[i].ID = uuid.New()
 		}
 		dlg.Turns[i].Position = i
+		dlg.Turns[i].Spans = coverage.Spans[i]
 	}
+	s.castVoices(&dlg)
+	emit(Event{Type: EventTurnsParsed, Title: dlg.Title, TurnCount: len(dlg.Turns)})
 
-	withAudio, err := s.tts.SynthesizeDialog(ctx, dlg)
+	withAudio, err := s.synthesize(ctx, dlg, emit)
 	if err != nil {
 		// Log error but don't expose internal details to user
 		return Dialog{}, fmt.Errorf("tts synthesize: %w", err)
 	}
+	if allAudioPending(withAudio.Turns) {
+		return Dialog{}, fmt.Errorf("tts synthesize: %w", ErrSynthesisFailed)
+	}
+
+	if err := s.storeAudio(ctx, &withAudio); err != nil {
+		return Dialog{}, fmt.Errorf("store audio: %w", err)
+	}
 
 	if err := s.repo.Create(ctx, withAudio); err != nil {
+		s.deleteAudio(ctx, withAudio.Turns)
 		return Dialog{}, fmt.Errorf("persist dialog: %w", err)
 	}
+	s.seedReview(ctx, withAudio)
+	emit(Event{Type: EventPersisted, DialogID: &withAudio.ID})
 
 	return withAudio, nil
 }
 
+// generateChecked generates a dialog and verifies that it uses the input
+// words and is written near the requested level. It regenerates the dialog
+// while a check fails and that check still has regenerations left. The last
+// attempt is returned with its coverage report and EstimatedLevel either
+// way; the caller decides whether to flag it.
+func (s *Service) generateChecked(ctx context.Context, params GenerateDialogParams, emit func(Event)) (Dialog, CoverageReport, error) {
+	for attempt := 0; ; attempt++ {
+		emit(Event{Type: EventPromptSent})
+		generated, err := s.generate(ctx, params, emit)
+		if err != nil {
+			return Dialog{}, CoverageReport{}, err
+		}
+
+		checked := Dialog{
+			DialogLanguage: params.DialogLanguage,
+			InputWords:     params.InputWords,
+			Translations:   generated.Translations,
+			Turns:          generated.Turns,
+		}
+		coverage := s.VerifyCoverage(checked)
+		generated.EstimatedLevel = s.EstimateLevel(checked)
+
+		retryCoverage := coverage.Ratio() < s.coverageThreshold && attempt < s.coverageRegenerations
+		retryLevel := levelMissed(params.CEFRLevel, generated.EstimatedLevel) && attempt < s.levelRegenerations
+		if !retryCoverage && !retryLevel {
+			return generated, coverage, nil
+		}
+	}
+}
+
+// EstimateLevel returns the CEFR level the turns of dlg appear to be written
+// at, or "" without a LevelEstimator.
+func (s *Service) EstimateLevel(dlg Dialog) string {
+	if s.levelEstimator == nil {
+		return ""
+	}
+	texts := make([]string, len(dlg.Turns))
+	for i, turn := range dlg.Turns {
+		texts[i] = turn.Text
+	}
+	return s.levelEstimator.EstimateLevel(strings.ToLower(dlg.DialogLanguage), texts)
+}
+
+// VerifyCoverage checks which input words dlg uses, with the stemmer of its
+// dialog language. See VerifyCoverage.
+func (s *Service) VerifyCoverage(dlg Dialog) CoverageReport {
+	return VerifyCoverage(dlg, s.stemmers[strings.ToLower(dlg.DialogLanguage)])
+}
+
+// generate asks the LLM for a dialog and emits turn_generated for every turn,
+// while the response streams in when the client supports it.
+func (s *Service) generate(ctx context.Context, params GenerateDialogParams, emit func(Event)) (Dialog, error) {
+	reportTurn := func(turn DialogTurn) {
+		emit(Event{Type: EventTurnGenerated, Turn: eventTurn(turn)})
+	}
+
+	streaming, ok := s.llm.(StreamingLLMClient)
+	if ok {
+		return streaming.StreamDialog(ctx, params, reportTurn)
+	}
+
+	generated, err := s.llm.GenerateDialog(ctx, params)
+	if err != nil {
+		return Dialog{}, err
+	}
+	for _, turn := range generated.Turns {
+		reportTurn(turn)
+	}
+	return generated, nil
+}
+
+// synthesize adds audio to every turn. Clients implementing TurnSynthesizer are
+// driven turn by turn, up to ttsConcurrency at once, so progress can be
+// reported as each turn finishes. A turn whose synthesis fails is kept with
+// AudioPending set rather than failing the dialog, so the generated text is
+// not lost; only cancellation aborts the whole dialog.
+func (s *Service) synthesize(ctx context.Context, dlg Dialog, emit func(Event)) (Dialog, error) {
+	var emitMu sync.Mutex
+	reportTurn := func(turn DialogTurn) {
+		emitMu.Lock()
+		defer emitMu.Unlock()
+		emit(Event{Type: EventTurnSynthesized, Turn: eventTurn(turn), TurnCount: len(dlg.Turns)})
+	}
+
+	perTurn, ok := s.tts.(TurnSynthesizer)
+	if !ok {
+		withAudio, err := s.tts.SynthesizeDialog(ctx, dlg)
+		if err != nil {
+			return Dialog{}, err
+		}
+		for _, turn := range withAudio.Turns {
+			reportTurn(turn)
+		}
+		return withAudio, nil
+	}
+
+	turns := make([]DialogTurn, len(dlg.Turns))
+	sem := make(chan struct{}, s.ttsConcurrency)
+	var wg sync.WaitGroup
+	for i, turn := range dlg.Turns {
+		wg.Add(1)
+		go func(i int, turn DialogTurn) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				turns[i] = pendingTurn(turn)
+				return
+			}
+
+			// Clients log their own failures; here the turn is only marked.
+			synthesized, err := perTurn.SynthesizeTurn(ctx, dlg, turn)
+			if err != nil {
+				synthesized = pendingTurn(turn)
+			}
+			turns[i] = synthesized
+			reportTurn(synthesized)
+		}(i, turn)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return Dialog{}, err
+	}
+	dlg.Turns = turns
+	return dlg, nil
+}
+
+// pendingTurn marks a turn whose audio still has to be synthesized.
+func pendingTurn(turn DialogTurn) DialogTurn {
+	turn.Audio = nil
+	turn.AudioURL = ""
+	turn.AudioPending = true
+	return turn
+}
+
+// allAudioPending reports whether none of turns could be synthesized.
+func allAudioPending(turns []DialogTurn) bool {
+	for _, turn := range turns {
+		if !turn.AudioPending {
+			return false
+		}
+	}
+	return len(turns) > 0
+}
+
+// castVoices fills in the voice of every turn that has none yet. Turns that
+// already carry a voice keep it, so re-synthesis sounds the same.
+func (s *Service) castVoices(dlg *Dialog) {
+	if s.voices == nil {
+		return
+	}
+	cast := s.voices.CastVoices(*dlg)
+	for i := range dlg.Turns {
+		if dlg.Turns[i].VoiceID == "" {
+			dlg.Turns[i].VoiceID = cast[dlg.Turns[i].Speaker]
+		}
+	}
+}
+
+// Subscribe streams progress events of a generation job. See EventBus.Subscribe.
+func (s *Service) Subscribe(jobID uuid.UUID) ([]Event, <-chan Event, func()) {
+	return s.events.Subscribe(jobID)
+}
+
+// OpenTurnAudio opens the stored audio of a single turn for streaming.
+func (s *Service) OpenTurnAudio(ctx context.Context, turnID uuid.UUID) (AudioObject, error) {
+	turn, err := s.repo.GetTurn(ctx, turnID)
+	if err != nil {
+		return AudioObject{}, err
+	}
+	if !turn.HasStoredAudio() {
+		return AudioObject{}, ErrAudioNotFound
+	}
+	return s.audio.Open(ctx, turn.AudioKey)
+}
+
+// AudioKey returns the store key used for a turn's synthesized audio.
+func AudioKey(dialogID, turnID uuid.UUID) string {
+	return fmt.Sprintf("dialogs/%s/%s.mp3", dialogID, turnID)
+}
+
+// storeAudio moves synthesized bytes into the AudioStore and keeps only the key on each turn.
+func (s *Service) storeAudio(ctx context.Context, dlg *Dialog) error {
+	for i := range dlg.Turns {
+		turn := &dlg.Turns[i]
+		if len(turn.Audio) == 0 {
+			continue
+		}
+		key := AudioKey(dlg.ID, turn.ID)
+		if err := s.audio.Put(ctx, key, turn.Audio, audioContentType); err != nil {
+			s.deleteAudio(ctx, dlg.Turns[:i])
+			return fmt.Errorf("put turn %d: %w", turn.Position, err)
+		}
+		turn.AudioKey = key
+		turn.AudioURL = ""
+		turn.Audio = nil
+	}
+	return nil
+}
+
+// deleteAudio removes stored audio on a best-effort basis.
+func (s *Service) deleteAudio(ctx context.Context, turns []DialogTurn) {
+	for _, turn := range turns {
+		if !turn.HasStoredAudio() {
+			continue
+		}
+		_ = s.audio.Delete(ctx, turn.AudioKey)
+	}
+}
+
 // GetDialog fetches a single dialog by id.
 func (s *Service) GetDialog(ctx context.Context, id uuid.UUID) (Dialog, error) {
 	dlg, err := s.repo.GetByID(ctx, id)
@@ -94,9 +588,25 @@ func (s *Service) SearchDialogs(ctx context.Context, filter DialogFilter) ([]Dia
 	return s.repo.Search(ctx, filter)
 }
 
-// DeleteDialog removes a dialog by id.
+// DeleteDialog removes a dialog by id together with its stored audio,
+// including the audio only its revisions still referenced.
 func (s *Service) DeleteDialog(ctx context.Context, id uuid.UUID) error {
-	return s.repo.Delete(ctx, id)
+	dlg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	revisions, err := s.repo.ListRevisions(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.deleteAudio(ctx, dlg.Turns)
+	for _, rev := range revisions {
+		s.deleteAudio(ctx, rev.Turns)
+	}
+	return nil
 }
 
 func validateCreateInput(input CreateDialogInput) error {
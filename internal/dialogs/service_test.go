@@ -0,0 +1,474 @@
+package dialogs_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+	"leveltalk/internal/tts"
+	"leveltalk/internal/voices"
+)
+
+// memoryRepo is an in-memory dialogs.Repository.
+type memoryRepo struct {
+	mu        sync.Mutex
+	dialogs   map[uuid.UUID]dialogs.Dialog
+	revisions map[uuid.UUID][]dialogs.DialogRevision
+	quizzes   map[uuid.UUID]dialogs.Quiz
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{
+		dialogs:   map[uuid.UUID]dialogs.Dialog{},
+		revisions: map[uuid.UUID][]dialogs.DialogRevision{},
+		quizzes:   map[uuid.UUID]dialogs.Quiz{},
+	}
+}
+
+func (r *memoryRepo) Create(ctx context.Context, dlg dialogs.Dialog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dialogs[dlg.ID] = dlg
+	return nil
+}
+
+func (r *memoryRepo) GetByID(ctx context.Context, id uuid.UUID) (dialogs.Dialog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dlg, ok := r.dialogs[id]
+	if !ok {
+		return dialogs.Dialog{}, dialogs.ErrNotFound
+	}
+	// Copies keep callers from editing the stored dialog in place.
+	dlg.Translations = maps.Clone(dlg.Translations)
+	dlg.Turns = slices.Clone(dlg.Turns)
+	return dlg, nil
+}
+
+func (r *memoryRepo) Search(ctx context.Context, filter dialogs.DialogFilter) ([]dialogs.Dialog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []dialogs.Dialog
+	for _, dlg := range r.dialogs {
+		result = append(result, dlg)
+	}
+	return result, nil
+}
+
+func (r *memoryRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.dialogs[id]; !ok {
+		return dialogs.ErrNotFound
+	}
+	delete(r.dialogs, id)
+	return nil
+}
+
+func (r *memoryRepo) GetTurn(ctx context.Context, id uuid.UUID) (dialogs.DialogTurn, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, dlg := range r.dialogs {
+		for _, turn := range dlg.Turns {
+			if turn.ID == id {
+				return turn, nil
+			}
+		}
+	}
+	return dialogs.DialogTurn{}, dialogs.ErrNotFound
+}
+
+func (r *memoryRepo) ListFamily(ctx context.Context, id uuid.UUID) ([]dialogs.Dialog, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	root := id
+	for r.dialogs[root].ParentID.Valid {
+		root = r.dialogs[root].ParentID.UUID
+	}
+	inFamily := func(dlg dialogs.Dialog) bool {
+		for dlg.ParentID.Valid {
+			dlg = r.dialogs[dlg.ParentID.UUID]
+		}
+		return dlg.ID == root
+	}
+	var family []dialogs.Dialog
+	for _, dlg := range r.dialogs {
+		if dlg.ID != id && inFamily(dlg) {
+			family = append(family, dlg)
+		}
+	}
+	return family, nil
+}
+
+func (r *memoryRepo) AppendTurns(ctx context.Context, dlg dialogs.Dialog, from int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.dialogs[dlg.ID]
+	if !ok {
+		return dialogs.ErrNotFound
+	}
+	if len(stored.Turns) != from {
+		return dialogs.ErrConflict
+	}
+	r.dialogs[dlg.ID] = dlg
+	return nil
+}
+
+func (r *memoryRepo) Update(ctx context.Context, dlg dialogs.Dialog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev, ok := r.dialogs[dlg.ID]
+	if !ok {
+		return dialogs.ErrNotFound
+	}
+	// Like dialog_json, the snapshot keeps no spans.
+	snapshot := slices.Clone(prev.Turns)
+	for i := range snapshot {
+		snapshot[i].Spans = nil
+	}
+	r.revisions[dlg.ID] = append(r.revisions[dlg.ID], dialogs.DialogRevision{
+		DialogID:     dlg.ID,
+		Number:       len(r.revisions[dlg.ID]) + 1,
+		Title:        prev.Title,
+		Translations: prev.Translations,
+		Turns:        snapshot,
+		CreatedAt:    time.Now(),
+	})
+	r.dialogs[dlg.ID] = dlg
+	return nil
+}
+
+func (r *memoryRepo) ListRevisions(ctx context.Context, dialogID uuid.UUID) ([]dialogs.DialogRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revisions := slices.Clone(r.revisions[dialogID])
+	slices.Reverse(revisions)
+	return revisions, nil
+}
+
+func (r *memoryRepo) GetRevision(ctx context.Context, dialogID uuid.UUID, number int) (dialogs.DialogRevision, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	revisions := r.revisions[dialogID]
+	if number < 1 || number > len(revisions) {
+		return dialogs.DialogRevision{}, dialogs.ErrNotFound
+	}
+	return revisions[number-1], nil
+}
+
+func (r *memoryRepo) SetTurnAudio(ctx context.Context, dialogID uuid.UUID, turn dialogs.DialogTurn) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dlg, ok := r.dialogs[dialogID]
+	if !ok {
+		return dialogs.ErrNotFound
+	}
+	dlg.Turns = slices.Clone(dlg.Turns)
+	for i := range dlg.Turns {
+		if dlg.Turns[i].ID == turn.ID {
+			dlg.Turns[i].VoiceID = turn.VoiceID
+			dlg.Turns[i].AudioKey = turn.AudioKey
+			dlg.Turns[i].AudioURL = turn.AudioURL
+			dlg.Turns[i].AudioPending = turn.AudioPending
+		}
+	}
+	r.dialogs[dialogID] = dlg
+	return nil
+}
+
+func (r *memoryRepo) ListPlaceholderDialogs(ctx context.Context) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []uuid.UUID
+	for id, dlg := range r.dialogs {
+		if slices.ContainsFunc(dlg.Turns, dialogs.DialogTurn.HasPlaceholderAudio) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *memoryRepo) SaveQuiz(ctx context.Context, quiz dialogs.Quiz) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.quizzes[quiz.DialogID] = quiz
+	return nil
+}
+
+func (r *memoryRepo) GetQuiz(ctx context.Context, dialogID uuid.UUID) (dialogs.Quiz, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	quiz, ok := r.quizzes[dialogID]
+	if !ok {
+		return dialogs.Quiz{}, dialogs.ErrQuizNotFound
+	}
+	return quiz, nil
+}
+
+// memoryAudio is an in-memory dialogs.AudioStore.
+type memoryAudio struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func newMemoryAudio() *memoryAudio {
+	return &memoryAudio{blobs: map[string][]byte{}}
+}
+
+func (a *memoryAudio) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.blobs[key] = data
+	return nil
+}
+
+func (a *memoryAudio) Open(ctx context.Context, key string) (dialogs.AudioObject, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	data, ok := a.blobs[key]
+	if !ok {
+		return dialogs.AudioObject{}, dialogs.ErrAudioNotFound
+	}
+	return dialogs.AudioObject{
+		Content:     nopCloser{bytes.NewReader(data)},
+		Size:        int64(len(data)),
+		ContentType: "audio/mpeg",
+	}, nil
+}
+
+func (a *memoryAudio) Delete(ctx context.Context, key string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.blobs, key)
+	return nil
+}
+
+type nopCloser struct{ io.ReadSeeker }
+
+func (nopCloser) Close() error { return nil }
+
+// memoryJobs is an in-memory dialogs.JobQueue.
+type memoryJobs struct {
+	mu   sync.Mutex
+	jobs map[uuid.UUID]dialogs.Job
+}
+
+func newMemoryJobs() *memoryJobs {
+	return &memoryJobs{jobs: map[uuid.UUID]dialogs.Job{}}
+}
+
+func (q *memoryJobs) Enqueue(ctx context.Context, job dialogs.Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs[job.ID] = job
+	return nil
+}
+
+func (q *memoryJobs) Claim(ctx context.Context) (dialogs.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, job := range q.jobs {
+		if job.State == dialogs.JobQueued {
+			job.State = dialogs.JobGeneratingText
+			job.Attempts++
+			q.jobs[id] = job
+			return job, nil
+		}
+	}
+	return dialogs.Job{}, dialogs.ErrNoJob
+}
+
+func (q *memoryJobs) GetJob(ctx context.Context, id uuid.UUID) (dialogs.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return dialogs.Job{}, dialogs.ErrNotFound
+	}
+	return job, nil
+}
+
+func (q *memoryJobs) update(id uuid.UUID, fn func(*dialogs.Job)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return dialogs.ErrNotFound
+	}
+	fn(&job)
+	q.jobs[id] = job
+	return nil
+}
+
+func (q *memoryJobs) SetState(ctx context.Context, id uuid.UUID, state dialogs.JobState) error {
+	return q.update(id, func(j *dialogs.Job) { j.State = state })
+}
+
+func (q *memoryJobs) Complete(ctx context.Context, id, dialogID uuid.UUID) error {
+	return q.update(id, func(j *dialogs.Job) { j.State = dialogs.JobDone; j.DialogID = &dialogID })
+}
+
+func (q *memoryJobs) Retry(ctx context.Context, id uuid.UUID, lastError string, runAfter time.Time) error {
+	return q.update(id, func(j *dialogs.Job) { j.State = dialogs.JobQueued; j.LastError = lastError })
+}
+
+func (q *memoryJobs) Fail(ctx context.Context, id uuid.UUID, lastError string) error {
+	return q.update(id, func(j *dialogs.Job) { j.State = dialogs.JobFailed; j.LastError = lastError })
+}
+
+func (q *memoryJobs) Release(ctx context.Context, id uuid.UUID) error {
+	return q.update(id, func(j *dialogs.Job) { j.State = dialogs.JobQueued; j.Attempts-- })
+}
+
+func (q *memoryJobs) ReleaseStale(ctx context.Context, olderThan time.Time) (int, error) {
+	return 0, nil
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func newTestService(opts *dialogs.ServiceOptions) (*dialogs.Service, *memoryRepo, *memoryJobs) {
+	if opts == nil {
+		opts = &dialogs.ServiceOptions{}
+	}
+	repo := newMemoryRepo()
+	jobs := newMemoryJobs()
+	opts.Jobs = jobs
+	svc := dialogs.NewService(repo, llm.NewStubClient(testLogger()), tts.NewStubClient(), newMemoryAudio(), opts)
+	return svc, repo, jobs
+}
+
+var testInput = dialogs.CreateDialogInput{
+	InputLanguage:  "ru",
+	DialogLanguage: "es",
+	CEFRLevel:      "A2",
+	InputWords:     []string{"casa", "perro"},
+}
+
+func TestProcessNextJobPublishesProgress(t *testing.T) {
+	svc, repo, jobs := newTestService(nil)
+	ctx := context.Background()
+
+	job, err := svc.EnqueueDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	_, events, cancel := svc.Subscribe(job.ID)
+	defer cancel()
+
+	require.NoError(t, svc.ProcessNextJob(ctx))
+	require.ErrorIs(t, svc.ProcessNextJob(ctx), dialogs.ErrNoJob)
+
+	var types []dialogs.EventType
+	var generated, synthesized int
+	var last dialogs.Event
+	for ev := range events {
+		types = append(types, ev.Type)
+		switch ev.Type {
+		case dialogs.EventTurnGenerated:
+			require.Equal(t, generated, ev.Turn.Position)
+			generated++
+		case dialogs.EventTurnSynthesized:
+			synthesized++
+			require.NotNil(t, ev.Turn)
+			require.NotEmpty(t, ev.Turn.Text)
+		}
+		last = ev
+	}
+	require.Equal(t, dialogs.EventPromptSent, types[0])
+	// The stub streams, so turns arrive before the parsed summary.
+	require.Equal(t, dialogs.EventTurnGenerated, types[1])
+	require.Equal(t, dialogs.EventTurnsParsed, types[1+generated])
+	require.Equal(t, dialogs.EventPersisted, last.Type)
+	require.Equal(t, 4, generated)
+	require.Equal(t, 4, synthesized)
+
+	stored, err := jobs.GetJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, dialogs.JobDone, stored.State)
+	require.Equal(t, *last.DialogID, *stored.DialogID)
+
+	_, err = repo.GetByID(ctx, *stored.DialogID)
+	require.NoError(t, err)
+}
+
+func TestCreateDialogCastsVoicesPerSpeaker(t *testing.T) {
+	catalog, err := voices.NewCatalog([]voices.Voice{
+		{ID: "es-female", Language: "es", Gender: "female"},
+		{ID: "es-male", Language: "es", Gender: "male"},
+	}, "")
+	require.NoError(t, err)
+	svc, repo, _ := newTestService(&dialogs.ServiceOptions{Voices: catalog})
+	ctx := context.Background()
+
+	dlg, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	stored, err := repo.GetByID(ctx, dlg.ID)
+	require.NoError(t, err)
+	for _, turn := range stored.Turns {
+		switch turn.Speaker {
+		case "Ana":
+			require.Equal(t, "es-female", turn.VoiceID)
+		case "Luis":
+			require.Equal(t, "es-male", turn.VoiceID)
+		}
+	}
+}
+
+// flakyTTS fails to synthesize the turns listed in failing.
+type flakyTTS struct {
+	failing map[int]bool
+}
+
+func (f flakyTTS) SynthesizeDialog(ctx context.Context, dlg dialogs.Dialog) (dialogs.Dialog, error) {
+	return dlg, nil
+}
+
+func (f flakyTTS) SynthesizeTurn(ctx context.Context, dlg dialogs.Dialog, turn dialogs.DialogTurn) (dialogs.DialogTurn, error) {
+	if f.failing[turn.Position] {
+		return dialogs.DialogTurn{}, errors.New("elevenlabs error: status=503")
+	}
+	turn.Audio = []byte("ID3")
+	return turn, nil
+}
+
+func TestCreateDialogKeepsTurnsWhoseSynthesisFailed(t *testing.T) {
+	repo := newMemoryRepo()
+	audio := newMemoryAudio()
+	svc := dialogs.NewService(repo, llm.NewStubClient(testLogger()), flakyTTS{failing: map[int]bool{1: true}}, audio, &dialogs.ServiceOptions{TTSConcurrency: 2})
+	ctx := context.Background()
+
+	dlg, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	stored, err := repo.GetByID(ctx, dlg.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Turns, 4)
+	for _, turn := range stored.Turns {
+		require.Equal(t, turn.Position == 1, turn.AudioPending, "turn %d", turn.Position)
+		require.Equal(t, turn.Position != 1, turn.HasStoredAudio(), "turn %d", turn.Position)
+	}
+}
+
+func TestCreateDialogFailsWhenNoTurnIsSynthesized(t *testing.T) {
+	repo := newMemoryRepo()
+	failing := map[int]bool{0: true, 1: true, 2: true, 3: true}
+	svc := dialogs.NewService(repo, llm.NewStubClient(testLogger()), flakyTTS{failing: failing}, newMemoryAudio(), nil)
+
+	_, err := svc.CreateDialog(context.Background(), testInput)
+	require.ErrorIs(t, err, dialogs.ErrSynthesisFailed)
+	require.Empty(t, repo.dialogs)
+}
@@ -0,0 +1,260 @@
+package dialogs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AudioRepair summarizes a RepairPlaceholderAudio run.
+type AudioRepair struct {
+	Dialogs  int // Dialogs that had turns without real audio
+	Turns    int // Turns synthesized again
+	Repaired int // Turns that now have stored audio
+	Failed   int // Dialogs whose repair failed; they are logged and skipped
+}
+
+// AudioRepairRun is the state of the background run started by
+// StartAudioRepair.
+type AudioRepairRun struct {
+	Running    bool
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Report     AudioRepair // Updated after every dialog while running
+	Error      string      // Why the run stopped early, if it did
+}
+
+// RegenerateTurn asks the LLM for a new version of one turn that fits the
+// turns around it and synthesizes it. The speaker and voice stay the same;
+// the previous content is kept as a revision.
+func (s *Service) RegenerateTurn(ctx context.Context, dialogID, turnID uuid.UUID) (Dialog, error) {
+	dlg, err := s.repo.GetByID(ctx, dialogID)
+	if err != nil {
+		return Dialog{}, err
+	}
+	i := turnIndex(dlg.Turns, turnID)
+	if i < 0 {
+		return Dialog{}, fmt.Errorf("%w: unknown turn %s", ErrInvalidInput, turnID)
+	}
+
+	target := dlg.Turns[i]
+	generated, err := s.generate(ctx, GenerateDialogParams{
+		InputLanguage:  dlg.InputLanguage,
+		DialogLanguage: dlg.DialogLanguage,
+		CEFRLevel:      dlg.CEFRLevel,
+		InputWords:     dlg.InputWords,
+		Prior:          dlg.Turns,
+		NewTurns:       1,
+		Rewrite:        &target,
+	}, func(Event) {})
+	if err != nil {
+		return Dialog{}, fmt.Errorf("regenerate turn: %w", err)
+	}
+	if len(generated.Turns) == 0 || strings.TrimSpace(generated.Turns[0].Text) == "" {
+		return Dialog{}, fmt.Errorf("regenerate turn: no text returned")
+	}
+
+	turn := &dlg.Turns[i]
+	turn.Text = strings.TrimSpace(generated.Turns[0].Text)
+	turn.Translation = generated.Turns[0].Translation
+	turn.AudioKey = ""
+	turn.AudioURL = ""
+	turn.AudioPending = false
+	return s.saveRevised(ctx, dlg, []int{i})
+}
+
+// ResynthesizeTurn synthesizes the audio of one turn again, keeping its text
+// and voice. It does not create a revision.
+func (s *Service) ResynthesizeTurn(ctx context.Context, dialogID, turnID uuid.UUID) (DialogTurn, error) {
+	dlg, err := s.repo.GetByID(ctx, dialogID)
+	if err != nil {
+		return DialogTurn{}, err
+	}
+	i := turnIndex(dlg.Turns, turnID)
+	if i < 0 {
+		return DialogTurn{}, fmt.Errorf("%w: unknown turn %s", ErrInvalidInput, turnID)
+	}
+
+	if err := s.resynthesize(ctx, &dlg, []int{i}); err != nil {
+		return DialogTurn{}, err
+	}
+	return dlg.Turns[i], nil
+}
+
+// RepairPlaceholderAudio synthesizes again every turn that has placeholder
+// or pending audio, across all dialogs. Turns the TTS client still cannot
+// voice keep their placeholder and are not counted as repaired. A dialog
+// whose repair fails is logged and counted in Failed, and the run goes on;
+// only listing the dialogs or cancellation stops it.
+func (s *Service) RepairPlaceholderAudio(ctx context.Context) (AudioRepair, error) {
+	return s.repairPlaceholderAudio(ctx, func(AudioRepair) {})
+}
+
+// StartAudioRepair runs RepairPlaceholderAudio in the background and returns
+// its state. While a run is going on, it returns that run instead of
+// starting another. The run outlives ctx cancellation, like a claimed job.
+func (s *Service) StartAudioRepair(ctx context.Context) AudioRepairRun {
+	s.repairMu.Lock()
+	defer s.repairMu.Unlock()
+	if s.repairRun.Running {
+		return s.repairRun
+	}
+	s.repairRun = AudioRepairRun{Running: true, StartedAt: time.Now().UTC()}
+
+	go func() {
+		report, err := s.repairPlaceholderAudio(context.WithoutCancel(ctx), func(progress AudioRepair) {
+			s.repairMu.Lock()
+			defer s.repairMu.Unlock()
+			s.repairRun.Report = progress
+		})
+		if err != nil {
+			s.logger.Warn("repair placeholder audio", slog.String("error", err.Error()))
+		}
+
+		s.repairMu.Lock()
+		defer s.repairMu.Unlock()
+		s.repairRun.Running = false
+		s.repairRun.FinishedAt = time.Now().UTC()
+		s.repairRun.Report = report
+		if err != nil {
+			s.repairRun.Error = err.Error()
+		}
+	}()
+	return s.repairRun
+}
+
+// AudioRepairStatus returns the state of the latest StartAudioRepair run; the
+// zero value when there has been none.
+func (s *Service) AudioRepairStatus() AudioRepairRun {
+	s.repairMu.Lock()
+	defer s.repairMu.Unlock()
+	return s.repairRun
+}
+
+// repairPlaceholderAudio runs RepairPlaceholderAudio, passing the report to
+// progress after every dialog.
+func (s *Service) repairPlaceholderAudio(ctx context.Context, progress func(AudioRepair)) (AudioRepair, error) {
+	var report AudioRepair
+	ids, err := s.repo.ListPlaceholderDialogs(ctx)
+	if err != nil {
+		return report, err
+	}
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		repaired, turns, err := s.repairDialogAudio(ctx, id)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			// Deleted since it was listed.
+			continue
+		case err != nil:
+			s.logger.Warn("repair dialog audio",
+				slog.String("dialog_id", id.String()),
+				slog.String("error", err.Error()))
+			report.Failed++
+		case turns > 0:
+			report.Dialogs++
+			report.Turns += turns
+			report.Repaired += repaired
+		}
+		progress(report)
+	}
+	return report, nil
+}
+
+// repairDialogAudio synthesizes the placeholder turns of dialog id again and
+// returns how many of how many turns now have stored audio.
+func (s *Service) repairDialogAudio(ctx context.Context, id uuid.UUID) (int, int, error) {
+	dlg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return 0, 0, err
+	}
+	var indices []int
+	for i, turn := range dlg.Turns {
+		if turn.HasPlaceholderAudio() {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) == 0 {
+		return 0, 0, nil
+	}
+
+	if err := s.resynthesize(ctx, &dlg, indices); err != nil {
+		return 0, 0, err
+	}
+	repaired := 0
+	for _, i := range indices {
+		if dlg.Turns[i].HasStoredAudio() {
+			repaired++
+		}
+	}
+	return repaired, len(indices), nil
+}
+
+// resynthesize replaces the audio of the turns of dlg at indices and stores
+// each with Repository.SetTurnAudio. Replaced audio is deleted unless a
+// revision still plays it.
+func (s *Service) resynthesize(ctx context.Context, dlg *Dialog, indices []int) error {
+	replaced := make([]string, 0, len(indices))
+	for _, i := range indices {
+		turn := &dlg.Turns[i]
+		if turn.HasStoredAudio() {
+			replaced = append(replaced, turn.AudioKey)
+		}
+		turn.AudioKey = ""
+		turn.AudioURL = ""
+		turn.AudioPending = false
+	}
+
+	s.castVoices(dlg)
+	if err := s.synthesizeTurns(ctx, dlg, indices); err != nil {
+		return fmt.Errorf("tts synthesize: %w", err)
+	}
+	if _, err := s.storeRevisedAudio(ctx, dlg); err != nil {
+		return fmt.Errorf("store audio: %w", err)
+	}
+	for n, i := range indices {
+		if err := s.repo.SetTurnAudio(ctx, dlg.ID, dlg.Turns[i]); err != nil {
+			// Turns already saved keep their new audio.
+			unsaved := make([]DialogTurn, 0, len(indices)-n)
+			for _, j := range indices[n:] {
+				unsaved = append(unsaved, dlg.Turns[j])
+			}
+			s.deleteAudio(ctx, unsaved)
+			return fmt.Errorf("persist audio: %w", err)
+		}
+	}
+
+	s.releaseAudio(ctx, dlg.ID, replaced)
+	return nil
+}
+
+// releaseAudio deletes the audio at keys except what the revisions of dialog
+// id still reference. When the revisions cannot be read, everything is kept.
+func (s *Service) releaseAudio(ctx context.Context, id uuid.UUID, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	revisions, err := s.repo.ListRevisions(ctx, id)
+	if err != nil {
+		return
+	}
+	referenced := make(map[string]bool)
+	for _, rev := range revisions {
+		for _, turn := range rev.Turns {
+			referenced[turn.AudioKey] = true
+		}
+	}
+	for _, key := range keys {
+		if !referenced[key] {
+			_ = s.audio.Delete(ctx, key)
+		}
+	}
+}
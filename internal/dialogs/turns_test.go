@@ -0,0 +1,132 @@
+package dialogs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/llm"
+	"leveltalk/internal/tts"
+)
+
+func TestRegenerateTurnRewritesOneTurn(t *testing.T) {
+	ctx := context.Background()
+	speech := &countingTTS{}
+	llmClient := &recordingLLM{LLMClient: llm.NewStubClient(testLogger())}
+	svc := dialogs.NewService(newMemoryRepo(), llmClient, speech, newMemoryAudio(), nil)
+
+	source, err := svc.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	speech.turns = 0
+
+	target := source.Turns[1]
+	dlg, err := svc.RegenerateTurn(ctx, source.ID, target.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, speech.turns)
+
+	params := llmClient.params[len(llmClient.params)-1]
+	require.Equal(t, 1, params.NewTurns)
+	require.Equal(t, target.ID, params.Rewrite.ID)
+	require.Len(t, params.Prior, len(source.Turns))
+
+	require.Len(t, dlg.Turns, len(source.Turns))
+	turn := dlg.Turns[1]
+	require.Equal(t, target.ID, turn.ID)
+	require.Equal(t, target.Speaker, turn.Speaker)
+	require.NotEqual(t, target.Text, turn.Text)
+	require.NotEqual(t, target.AudioKey, turn.AudioKey)
+	require.True(t, turn.HasStoredAudio())
+	require.Equal(t, source.Turns[0], dlg.Turns[0])
+
+	revisions, err := svc.ListRevisions(ctx, source.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+}
+
+func TestRepairPlaceholderAudio(t *testing.T) {
+	ctx := context.Background()
+	repo, audio := newMemoryRepo(), newMemoryAudio()
+
+	// The stub TTS only hands out placeholder URLs.
+	silent := dialogs.NewService(repo, llm.NewStubClient(testLogger()), tts.NewStubClient(), audio, nil)
+	dlg, err := silent.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	require.True(t, dlg.Turns[0].HasPlaceholderAudio())
+
+	report, err := silent.RepairPlaceholderAudio(ctx)
+	require.NoError(t, err)
+	require.Equal(t, dialogs.AudioRepair{Dialogs: 1, Turns: len(dlg.Turns)}, report)
+
+	speech := &countingTTS{}
+	svc := dialogs.NewService(repo, llm.NewStubClient(testLogger()), speech, audio, nil)
+	turn, err := svc.ResynthesizeTurn(ctx, dlg.ID, dlg.Turns[0].ID)
+	require.NoError(t, err)
+	require.True(t, turn.HasStoredAudio())
+	require.Equal(t, 1, speech.turns)
+
+	report, err = svc.RepairPlaceholderAudio(ctx)
+	require.NoError(t, err)
+	require.Equal(t, dialogs.AudioRepair{Dialogs: 1, Turns: len(dlg.Turns) - 1, Repaired: len(dlg.Turns) - 1}, report)
+
+	stored, err := svc.GetDialog(ctx, dlg.ID)
+	require.NoError(t, err)
+	for _, turn := range stored.Turns {
+		require.False(t, turn.HasPlaceholderAudio())
+	}
+	revisions, err := svc.ListRevisions(ctx, dlg.ID)
+	require.NoError(t, err)
+	require.Empty(t, revisions)
+
+	// Replacing stored audio frees the old blob.
+	again, err := svc.ResynthesizeTurn(ctx, dlg.ID, turn.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, turn.AudioKey, again.AudioKey)
+	_, err = audio.Open(ctx, turn.AudioKey)
+	require.ErrorIs(t, err, dialogs.ErrAudioNotFound)
+}
+
+// brokenDialogRepo fails to store turn audio for one dialog.
+type brokenDialogRepo struct {
+	*memoryRepo
+	broken uuid.UUID
+}
+
+func (r brokenDialogRepo) SetTurnAudio(ctx context.Context, dialogID uuid.UUID, turn dialogs.DialogTurn) error {
+	if dialogID == r.broken {
+		return errors.New("connection reset")
+	}
+	return r.memoryRepo.SetTurnAudio(ctx, dialogID, turn)
+}
+
+func TestStartAudioRepairSkipsFailedDialogs(t *testing.T) {
+	ctx := context.Background()
+	repo, audio := newMemoryRepo(), newMemoryAudio()
+	silent := dialogs.NewService(repo, llm.NewStubClient(testLogger()), tts.NewStubClient(), audio, nil)
+	broken, err := silent.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+	fine, err := silent.CreateDialog(ctx, testInput)
+	require.NoError(t, err)
+
+	svc := dialogs.NewService(brokenDialogRepo{repo, broken.ID}, llm.NewStubClient(testLogger()), &countingTTS{}, audio, nil)
+	require.False(t, svc.AudioRepairStatus().Running)
+
+	run := svc.StartAudioRepair(ctx)
+	require.True(t, run.Running)
+	require.Eventually(t, func() bool { return !svc.AudioRepairStatus().Running }, time.Second, time.Millisecond)
+
+	run = svc.AudioRepairStatus()
+	require.Empty(t, run.Error)
+	require.False(t, run.FinishedAt.IsZero())
+	require.Equal(t, dialogs.AudioRepair{Dialogs: 1, Turns: len(fine.Turns), Repaired: len(fine.Turns), Failed: 1}, run.Report)
+
+	stored, err := repo.GetByID(ctx, fine.ID)
+	require.NoError(t, err)
+	for _, turn := range stored.Turns {
+		require.True(t, turn.HasStoredAudio())
+	}
+}
@@ -0,0 +1,158 @@
+// Package exercises turns stored dialogs into practice exercises and grades
+// what the learner types.
+package exercises
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/textutil"
+)
+
+// contentWordSteps blanks every Nth content word at the levels where the
+// vocabulary alone makes too easy an exercise.
+var contentWordSteps = map[string]int{"B2": 6, "C1": 5, "C2": 4}
+
+// fallbackStep is used for lower-level dialogs without vocabulary matches,
+// such as dialogs stored before matches were recorded.
+const fallbackStep = 7
+
+// Cloze is a dialog with some words replaced by blanks.
+type Cloze struct {
+	DialogID uuid.UUID
+	Turns    []ClozeTurn
+	Blanks   []Blank
+}
+
+// ClozeTurn is a turn split into plain text and blanks.
+type ClozeTurn struct {
+	TurnID  uuid.UUID
+	Speaker string
+	Parts   []ClozePart
+}
+
+// ClozePart is either plain text or, when Blank is not negative, the blank
+// with that index into Cloze.Blanks.
+type ClozePart struct {
+	Text  string
+	Blank int
+}
+
+// Blank is a word the learner has to type.
+type Blank struct {
+	TurnID uuid.UUID
+	Answer string
+	// Hint is the input word a vocabulary blank realizes, empty for other
+	// content words.
+	Hint string
+}
+
+// ClozeResult is a graded set of answers.
+type ClozeResult struct {
+	Blanks  []GradedBlank
+	Correct int // Accepted answers
+}
+
+// GradedBlank is the learner's answer to one blank.
+type GradedBlank struct {
+	Blank
+	Given   string
+	Verdict Verdict
+}
+
+// NewCloze blanks out words of dlg. Up to B1 the blanks are the input words
+// as the dialog uses them; from B2 on every Nth content word is blanked, more
+// often the higher the level.
+func NewCloze(dlg dialogs.Dialog) Cloze {
+	cloze := Cloze{DialogID: dlg.ID}
+	step := contentWordSteps[dlg.CEFRLevel]
+	if step == 0 && !hasSpans(dlg) {
+		step = fallbackStep
+	}
+
+	count := 0
+	for _, turn := range dlg.Turns {
+		var blanks []dialogs.VocabSpan
+		if step == 0 {
+			blanks = vocabularyBlanks(turn)
+		} else {
+			for _, w := range textutil.Words(turn.Text) {
+				if !textutil.IsContentWord(turn.Text[w.Start:w.End]) {
+					continue
+				}
+				count++
+				if count%step == 0 {
+					blanks = append(blanks, dialogs.VocabSpan{Start: w.Start, End: w.End})
+				}
+			}
+		}
+		cloze.Turns = append(cloze.Turns, cloze.split(turn, blanks))
+	}
+	return cloze
+}
+
+// split cuts turn at blanks, which are ordered and do not overlap, and
+// registers them with c.
+func (c *Cloze) split(turn dialogs.DialogTurn, blanks []dialogs.VocabSpan) ClozeTurn {
+	out := ClozeTurn{TurnID: turn.ID, Speaker: turn.Speaker}
+	pos := 0
+	for _, span := range blanks {
+		if span.Start > pos {
+			out.Parts = append(out.Parts, ClozePart{Text: turn.Text[pos:span.Start], Blank: -1})
+		}
+		out.Parts = append(out.Parts, ClozePart{Blank: len(c.Blanks)})
+		c.Blanks = append(c.Blanks, Blank{TurnID: turn.ID, Answer: turn.Text[span.Start:span.End], Hint: span.Word})
+		pos = span.End
+	}
+	if pos < len(turn.Text) {
+		out.Parts = append(out.Parts, ClozePart{Text: turn.Text[pos:], Blank: -1})
+	}
+	return out
+}
+
+// Grade compares answers, given in blank order, with the blanks of c.
+// Missing answers count as wrong.
+func (c Cloze) Grade(answers []string) ClozeResult {
+	result := ClozeResult{Blanks: make([]GradedBlank, len(c.Blanks))}
+	for i, blank := range c.Blanks {
+		graded := GradedBlank{Blank: blank}
+		if i < len(answers) {
+			graded.Given = strings.TrimSpace(answers[i])
+			graded.Verdict = Compare(graded.Given, blank.Answer)
+		}
+		if graded.Verdict.Accepted() {
+			result.Correct++
+		}
+		result.Blanks[i] = graded
+	}
+	return result
+}
+
+func hasSpans(dlg dialogs.Dialog) bool {
+	for _, turn := range dlg.Turns {
+		if len(turn.Spans) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// vocabularyBlanks returns the spans of turn in order, dropping any that
+// overlap an earlier one.
+func vocabularyBlanks(turn dialogs.DialogTurn) []dialogs.VocabSpan {
+	spans := append([]dialogs.VocabSpan(nil), turn.Spans...)
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start < spans[j].Start })
+	var blanks []dialogs.VocabSpan
+	end := 0
+	for _, span := range spans {
+		if span.Start < end || span.Start < 0 || span.End > len(turn.Text) {
+			continue
+		}
+		blanks = append(blanks, span)
+		end = span.End
+	}
+	return blanks
+}
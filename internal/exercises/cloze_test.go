@@ -0,0 +1,85 @@
+package exercises
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+// text joins the parts of turn, writing blanks as "_".
+func text(turn ClozeTurn) string {
+	var b strings.Builder
+	for _, part := range turn.Parts {
+		if part.Blank >= 0 {
+			b.WriteString("_")
+			continue
+		}
+		b.WriteString(part.Text)
+	}
+	return b.String()
+}
+
+func TestNewClozeBlanksVocabularyAtLowerLevels(t *testing.T) {
+	dlg := dialogs.Dialog{
+		CEFRLevel: "A2",
+		Turns: []dialogs.DialogTurn{
+			{ID: uuid.New(), Speaker: "Ana", Text: "Mi casa tiene un perro.", Spans: []dialogs.VocabSpan{
+				{Start: 17, End: 22, Word: "собака"},
+				{Start: 3, End: 7, Word: "дом"},
+				{Start: 3, End: 7, Word: "дом"},
+			}},
+			{ID: uuid.New(), Speaker: "Luis", Text: "¡Qué bonito!"},
+		},
+	}
+
+	cloze := NewCloze(dlg)
+	require.Len(t, cloze.Turns, 2)
+	require.Equal(t, "Mi _ tiene un _.", text(cloze.Turns[0]))
+	require.Equal(t, "¡Qué bonito!", text(cloze.Turns[1]))
+	require.Equal(t, []Blank{
+		{TurnID: dlg.Turns[0].ID, Answer: "casa", Hint: "дом"},
+		{TurnID: dlg.Turns[0].ID, Answer: "perro", Hint: "собака"},
+	}, cloze.Blanks)
+
+	result := cloze.Grade([]string{"Casa", "pero"})
+	require.Equal(t, 2, result.Correct)
+	require.Equal(t, Exact, result.Blanks[0].Verdict)
+	require.Equal(t, Typo, result.Blanks[1].Verdict)
+
+	result = cloze.Grade([]string{"mesa"})
+	require.Zero(t, result.Correct)
+	require.Equal(t, Wrong, result.Blanks[1].Verdict)
+}
+
+func TestNewClozeBlanksContentWordsAtHigherLevels(t *testing.T) {
+	dlg := dialogs.Dialog{
+		CEFRLevel: "C2",
+		Turns: []dialogs.DialogTurn{
+			{Speaker: "Ana", Text: "Ayer estuvimos paseando por el parque municipal, ¿verdad?", Spans: []dialogs.VocabSpan{{Start: 31, End: 37, Word: "парк"}}},
+			{Speaker: "Luis", Text: "Sí, y después comimos helados."},
+		},
+	}
+
+	// Ayer(1) estuvimos(2) paseando(3) parque(4) municipal(5) verdad(6)
+	// después(7) comimos(8) helados(9): every fourth content word.
+	cloze := NewCloze(dlg)
+	require.Equal(t, "Ayer estuvimos paseando por el _ municipal, ¿verdad?", text(cloze.Turns[0]))
+	require.Equal(t, "Sí, y después _ helados.", text(cloze.Turns[1]))
+	require.Equal(t, "parque", cloze.Blanks[0].Answer)
+	require.Empty(t, cloze.Blanks[0].Hint)
+	require.Equal(t, "comimos", cloze.Blanks[1].Answer)
+}
+
+func TestNewClozeFallsBackWithoutVocabularyMatches(t *testing.T) {
+	dlg := dialogs.Dialog{
+		CEFRLevel: "A1",
+		Turns:     []dialogs.DialogTurn{{Speaker: "Ana", Text: "Hola amigo, quiero comprar pan, leche, queso y también fruta fresca."}},
+	}
+	cloze := NewCloze(dlg)
+	require.Len(t, cloze.Blanks, 1)
+	require.Equal(t, "también", cloze.Blanks[0].Answer)
+}
@@ -0,0 +1,161 @@
+package exercises
+
+import (
+	"golang.org/x/text/unicode/norm"
+
+	"leveltalk/internal/textutil"
+)
+
+// WordStatus is how a word of a dictation compares to the transcript.
+type WordStatus int
+
+const (
+	WordCorrect    WordStatus = iota
+	WordMissing               // In the transcript but not typed
+	WordExtra                 // Typed but not in the transcript
+	WordMisspelled            // Typed within the typo allowance of the transcript word
+	WordAccent                // Typed right apart from diacritics
+)
+
+// String returns the lower-case name of s, as used in templates.
+func (s WordStatus) String() string {
+	switch s {
+	case WordCorrect:
+		return "correct"
+	case WordMissing:
+		return "missing"
+	case WordExtra:
+		return "extra"
+	case WordMisspelled:
+		return "misspelled"
+	default:
+		return "accent"
+	}
+}
+
+// DictationWord is one word of a graded dictation. Expected is empty for
+// extra words and Given for missing ones.
+type DictationWord struct {
+	Status   WordStatus
+	Expected string
+	Given    string
+}
+
+// Dictation is a typed transcript compared word by word with the turn text.
+type Dictation struct {
+	Words   []DictationWord
+	Correct int // Words typed exactly
+	// Total counts the words of the transcript and the extra words typed,
+	// so padding an answer does not pay off.
+	Total int
+}
+
+// Accuracy is the share of Total typed exactly.
+func (d Dictation) Accuracy() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(d.Correct) / float64(d.Total)
+}
+
+// Costs of the word alignment. Substituting a word the learner got wrong
+// entirely is not allowed; it shows up as a missing and an extra word.
+const (
+	costAccent = 1
+	costTypo   = 2
+	costGap    = 2
+	costNever  = 1 << 30
+)
+
+// DiffDictation aligns the words given with those of expected and classifies
+// each one. Both texts are compared in NFC, so precomposed and decomposed
+// accents match, and case and punctuation are ignored.
+func DiffDictation(given, expected string) Dictation {
+	given, expected = norm.NFC.String(given), norm.NFC.String(expected)
+	want := wordTexts(expected)
+	got := wordTexts(given)
+
+	// cost[i][j] is the cheapest alignment of want[i:] with got[j:].
+	verdicts := make([][]Verdict, len(want))
+	cost := make([][]int, len(want)+1)
+	for i := range cost {
+		cost[i] = make([]int, len(got)+1)
+	}
+	for i := len(want); i >= 0; i-- {
+		if i < len(want) {
+			verdicts[i] = make([]Verdict, len(got))
+		}
+		for j := len(got); j >= 0; j-- {
+			switch {
+			case i == len(want):
+				cost[i][j] = (len(got) - j) * costGap
+			case j == len(got):
+				cost[i][j] = (len(want) - i) * costGap
+			default:
+				verdicts[i][j] = Compare(got[j], want[i])
+				cost[i][j] = min(
+					substitutionCost(verdicts[i][j])+cost[i+1][j+1],
+					costGap+cost[i+1][j],
+					costGap+cost[i][j+1],
+				)
+			}
+		}
+	}
+
+	var d Dictation
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		var word DictationWord
+		switch {
+		case i < len(want) && j < len(got) && cost[i][j] == substitutionCost(verdicts[i][j])+cost[i+1][j+1]:
+			word = DictationWord{Status: wordStatus(verdicts[i][j]), Expected: want[i], Given: got[j]}
+			i++
+			j++
+		case i < len(want) && (j == len(got) || cost[i][j] == costGap+cost[i+1][j]):
+			word = DictationWord{Status: WordMissing, Expected: want[i]}
+			i++
+		default:
+			word = DictationWord{Status: WordExtra, Given: got[j]}
+			j++
+		}
+		if word.Status == WordCorrect {
+			d.Correct++
+		}
+		d.Words = append(d.Words, word)
+	}
+	d.Total = len(d.Words)
+	return d
+}
+
+func substitutionCost(v Verdict) int {
+	switch v {
+	case Exact:
+		return 0
+	case AccentOnly:
+		return costAccent
+	case Typo:
+		return costTypo
+	default:
+		return costNever
+	}
+}
+
+func wordStatus(v Verdict) WordStatus {
+	switch v {
+	case Exact:
+		return WordCorrect
+	case AccentOnly:
+		return WordAccent
+	default:
+		return WordMisspelled
+	}
+}
+
+func wordTexts(text string) []string {
+	spans := textutil.Words(text)
+	out := make([]string, len(spans))
+	for i, span := range spans {
+		out[i] = text[span.Start:span.End]
+	}
+	return out
+}
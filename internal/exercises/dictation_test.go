@@ -0,0 +1,42 @@
+package exercises
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffDictation(t *testing.T) {
+	d := DiffDictation("el nino tiene un gato muy grandee hoy", "El niño tiene un perro muy grande.")
+	require.Equal(t, []DictationWord{
+		{Status: WordCorrect, Expected: "El", Given: "el"},
+		{Status: WordAccent, Expected: "niño", Given: "nino"},
+		{Status: WordCorrect, Expected: "tiene", Given: "tiene"},
+		{Status: WordCorrect, Expected: "un", Given: "un"},
+		{Status: WordMissing, Expected: "perro"},
+		{Status: WordExtra, Given: "gato"},
+		{Status: WordCorrect, Expected: "muy", Given: "muy"},
+		{Status: WordMisspelled, Expected: "grande", Given: "grandee"},
+		{Status: WordExtra, Given: "hoy"},
+	}, d.Words)
+	require.Equal(t, 4, d.Correct)
+	require.Equal(t, 9, d.Total)
+}
+
+func TestDiffDictationNormalizesUnicode(t *testing.T) {
+	// A decomposed é matches the composed one; case and spaces do not matter.
+	d := DiffDictation("¿Que\u0301   hora ES?", "¿Qu\u00e9 hora es?")
+	require.Equal(t, 3, d.Correct)
+	require.Equal(t, 3, d.Total)
+	require.Equal(t, 1.0, d.Accuracy())
+}
+
+func TestDiffDictationMissingAndEmpty(t *testing.T) {
+	d := DiffDictation("Buenos", "Buenos días")
+	require.Equal(t, WordMissing, d.Words[1].Status)
+	require.Equal(t, 0.5, d.Accuracy())
+
+	d = DiffDictation("", "Hola")
+	require.Equal(t, []DictationWord{{Status: WordMissing, Expected: "Hola"}}, d.Words)
+	require.Zero(t, d.Accuracy())
+}
@@ -0,0 +1,116 @@
+package exercises
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Verdict is how a typed answer compares to the expected one.
+type Verdict int
+
+const (
+	Wrong Verdict = iota
+	Exact
+	AccentOnly // Right apart from diacritics ("cafe" for "café")
+	Typo       // Within the typo allowance of the expected answer
+)
+
+// String returns the lower-case name of v, as used in templates.
+func (v Verdict) String() string {
+	switch v {
+	case Exact:
+		return "exact"
+	case AccentOnly:
+		return "accent"
+	case Typo:
+		return "typo"
+	default:
+		return "wrong"
+	}
+}
+
+// Accepted reports whether the answer counts as correct.
+func (v Verdict) Accepted() bool {
+	return v != Wrong
+}
+
+// Compare grades given against expected. Case, surrounding punctuation and
+// repeated spaces never matter. Answers that differ only in diacritics are
+// AccentOnly; answers within a small edit distance of the expected one,
+// diacritics ignored, are Typo.
+func Compare(given, expected string) Verdict {
+	given, expected = normalize(given), normalize(expected)
+	if given == "" {
+		return Wrong
+	}
+	if given == expected {
+		return Exact
+	}
+	foldedGiven, foldedExpected := StripDiacritics(given), StripDiacritics(expected)
+	if foldedGiven == foldedExpected {
+		return AccentOnly
+	}
+	if EditDistance(foldedGiven, foldedExpected) <= typoAllowance(foldedExpected) {
+		return Typo
+	}
+	return Wrong
+}
+
+// typoAllowance is the edit distance tolerated for an answer: none for short
+// words, where one letter often makes another word, and more for long ones.
+func typoAllowance(expected string) int {
+	switch n := utf8.RuneCountInString(expected); {
+	case n <= 3:
+		return 0
+	case n <= 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// normalize puts s in NFC, lower-cases it, trims punctuation and spaces from
+// both ends and collapses inner runs of spaces.
+func normalize(s string) string {
+	s = strings.ToLower(norm.NFC.String(s))
+	s = strings.TrimFunc(s, func(r rune) bool { return unicode.IsPunct(r) || unicode.IsSpace(r) })
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// StripDiacritics removes combining marks, so "Ñandú" becomes "Nandu". It
+// leaves letters that are not decomposable, such as "ø" or "ß", alone.
+func StripDiacritics(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			b.WriteRune(r)
+		}
+	}
+	return norm.NFC.String(b.String())
+}
+
+// EditDistance returns the Levenshtein distance between a and b, counted in
+// runes.
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,44 @@
+package exercises
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		given, expected string
+		want            Verdict
+	}{
+		{"Café", "café", Exact},
+		{"  café. ", "café", Exact},
+		{"cafe", "café", AccentOnly},
+		{"nino", "niño", AccentOnly},
+		// A decomposed é equals the composed one.
+		{"cafe\u0301", "café", Exact},
+		{"biblioteka", "biblioteca", Typo},
+		{"bibliotek", "biblioteca", Typo},
+		{"perro", "pero", Typo},
+		{"sol", "sal", Wrong},
+		{"", "casa", Wrong},
+		{"mesa", "casa", Wrong},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.want, Compare(c.given, c.expected), "%q vs %q", c.given, c.expected)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	require.Equal(t, 0, EditDistance("", ""))
+	require.Equal(t, 4, EditDistance("kitten", "sit"))
+	require.Equal(t, 3, EditDistance("kitten", "sitting"))
+	require.Equal(t, 1, EditDistance("дом", "дым"))
+}
+
+func TestStripDiacritics(t *testing.T) {
+	require.Equal(t, "Nandu", StripDiacritics("Ñandú"))
+	require.Equal(t, "Ørsted, Straße", StripDiacritics("Ørsted, Straße"))
+	// й decomposes into и and a breve, so it folds like any accent.
+	require.Equal(t, "мои", StripDiacritics("мой"))
+}
@@ -0,0 +1,49 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"leveltalk/internal/anki"
+	"leveltalk/internal/dialogs"
+)
+
+// handleDownloadAnki exports the selected dialogs as an Anki deck with one
+// note per input word. sentences=1 adds a card for every turn.
+func (s *Server) handleDownloadAnki(w http.ResponseWriter, r *http.Request) {
+	dialogsList, ok := s.selectedDialogs(w, r)
+	if !ok {
+		return
+	}
+
+	// A turn whose audio cannot be loaded is exported without it, like in the
+	// audio download.
+	loadAudio := func(ctx context.Context, turn dialogs.DialogTurn) ([]byte, error) {
+		data, err := s.turnAudio(ctx, turn)
+		if err != nil {
+			s.logger.Warn("failed to load turn audio",
+				slog.String("turn_id", turn.ID.String()),
+				slog.String("error", err.Error()),
+			)
+			return nil, nil
+		}
+		return data, nil
+	}
+
+	now := time.Now()
+	var buf bytes.Buffer
+	opts := &anki.Options{Sentences: r.URL.Query().Get("sentences") == "1", Now: now}
+	if err := anki.Export(r.Context(), &buf, dialogsList, loadAudio, opts); err != nil {
+		s.serverError(w, fmt.Errorf("export anki deck: %w", err))
+		return
+	}
+
+	filename := fmt.Sprintf("leveltalk-deck-%s.apkg", now.Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,150 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/exercises"
+)
+
+const (
+	// dictationHardTurns is how many of the hardest turns the dictation page
+	// offers for replay.
+	dictationHardTurns = 3
+
+	// maxDictationLength bounds what the learner may type for one turn.
+	maxDictationLength = 1000
+)
+
+// handleDictation renders the dictation exercise of a dialog: every turn
+// with audio, its text hidden, and the turns the learner found hardest.
+func (s *Server) handleDictation(w http.ResponseWriter, r *http.Request) {
+	lang := s.getLanguage(r)
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+
+	dlg, err := s.dialogs.GetDialog(r.Context(), dialogID)
+	if err != nil {
+		s.dialogError(w, err)
+		return
+	}
+	hard, err := s.dialogs.HardestTurns(r.Context(), s.learnerID(w, r), dialogID, dictationHardTurns)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	var items []map[string]any
+	for _, turn := range dlg.Turns {
+		if !turn.AudioPending {
+			items = append(items, s.dictationTurnPayload(r, dlg, turn, nil))
+		}
+	}
+	s.renderPage(w, lang, "LevelTalk — dictation", "dictation.html", map[string]any{
+		"Dialog":   dlg,
+		"Items":    items,
+		"Hard":     hard,
+		"Lang":     lang,
+		"BasePath": s.basePath,
+	})
+}
+
+// handleDictationTurn renders one turn of the exercise afresh, for another
+// try.
+func (s *Server) handleDictationTurn(w http.ResponseWriter, r *http.Request) {
+	dlg, turn, ok := s.dictationTurn(w, r)
+	if !ok {
+		return
+	}
+	s.renderPartial(w, "dictation_turn.html", s.dictationTurnPayload(r, dlg, turn, nil))
+}
+
+// handleGradeDictation compares what the learner typed for a turn with its
+// text, records the accuracy and answers with the word diff and the updated
+// hardest turns.
+func (s *Server) handleGradeDictation(w http.ResponseWriter, r *http.Request) {
+	dlg, turn, ok := s.dictationTurn(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+	given := strings.TrimSpace(r.PostForm.Get("text"))
+	if utf8.RuneCountInString(given) > maxDictationLength {
+		s.clientError(w, http.StatusBadRequest, "answer is too long")
+		return
+	}
+
+	result := exercises.DiffDictation(given, turn.Text)
+	learnerID := s.learnerID(w, r)
+	if _, err := s.dialogs.RecordDictationAttempt(r.Context(), dialogs.DictationAttempt{
+		LearnerID: learnerID,
+		DialogID:  dlg.ID,
+		TurnID:    turn.ID,
+		Given:     given,
+		Correct:   result.Correct,
+		Total:     result.Total,
+	}); err != nil {
+		s.dialogError(w, err)
+		return
+	}
+	hard, err := s.dialogs.HardestTurns(r.Context(), learnerID, dlg.ID, dictationHardTurns)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	payload := s.dictationTurnPayload(r, dlg, turn, &result)
+	payload["Hard"] = hard
+	s.renderPartial(w, "dictation_turn.html", payload)
+}
+
+// dictationTurn loads the dialog and turn named in the URL, answering the
+// request itself when it cannot.
+func (s *Server) dictationTurn(w http.ResponseWriter, r *http.Request) (dialogs.Dialog, dialogs.DialogTurn, bool) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return dialogs.Dialog{}, dialogs.DialogTurn{}, false
+	}
+	turnID, err := uuid.Parse(chi.URLParam(r, "turnID"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid turn id")
+		return dialogs.Dialog{}, dialogs.DialogTurn{}, false
+	}
+
+	dlg, err := s.dialogs.GetDialog(r.Context(), dialogID)
+	if err != nil {
+		s.dialogError(w, err)
+		return dialogs.Dialog{}, dialogs.DialogTurn{}, false
+	}
+	for _, turn := range dlg.Turns {
+		if turn.ID == turnID {
+			return dlg, turn, true
+		}
+	}
+	s.clientError(w, http.StatusNotFound, "turn not found")
+	return dialogs.Dialog{}, dialogs.DialogTurn{}, false
+}
+
+func (s *Server) dictationTurnPayload(r *http.Request, dlg dialogs.Dialog, turn dialogs.DialogTurn, result *exercises.Dictation) map[string]any {
+	return map[string]any{
+		"DialogID":       dlg.ID,
+		"DialogLanguage": dlg.DialogLanguage,
+		"Turn":           turn,
+		"Result":         result,
+		"MaxLength":      maxDictationLength,
+		"Lang":           s.getLanguage(r),
+		"BasePath":       s.basePath,
+	}
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/exercises"
+)
+
+const (
+	// learnerCookie keeps the anonymous id that practice attempts are
+	// recorded under.
+	learnerCookie = "learner"
+
+	// practiceAttempts is how many earlier attempts an exercise lists.
+	practiceAttempts = 5
+)
+
+func (s *Server) handleCloze(w http.ResponseWriter, r *http.Request) {
+	lang := s.getLanguage(r)
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+
+	dlg, err := s.dialogs.GetDialog(r.Context(), dialogID)
+	if err != nil {
+		s.dialogError(w, err)
+		return
+	}
+	attempts, err := s.dialogs.ClozeAttempts(r.Context(), s.learnerID(w, r), dialogID, practiceAttempts)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.renderPage(w, lang, "LevelTalk — cloze", "cloze.html", map[string]any{
+		"Dialog":   dlg,
+		"Cloze":    exercises.NewCloze(dlg),
+		"Result":   nil,
+		"Attempts": attempts,
+		"Lang":     lang,
+		"BasePath": s.basePath,
+	})
+}
+
+// handleGradeCloze grades a cloze submission, records it for the learner and
+// answers htmx requests with the graded exercise. Fields are named
+// blank:<index>.
+func (s *Server) handleGradeCloze(w http.ResponseWriter, r *http.Request) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+
+	dlg, err := s.dialogs.GetDialog(r.Context(), dialogID)
+	if err != nil {
+		s.dialogError(w, err)
+		return
+	}
+	cloze := exercises.NewCloze(dlg)
+	if len(cloze.Blanks) == 0 {
+		s.clientError(w, http.StatusBadRequest, "dialog has nothing to practise")
+		return
+	}
+
+	answers := make([]string, len(cloze.Blanks))
+	for key := range r.PostForm {
+		name, field, _ := strings.Cut(key, ":")
+		if name != "blank" {
+			continue
+		}
+		i, err := strconv.Atoi(field)
+		if err != nil || i < 0 || i >= len(answers) {
+			s.clientError(w, http.StatusBadRequest, "invalid blank")
+			return
+		}
+		answers[i] = r.PostForm.Get(key)
+	}
+	result := cloze.Grade(answers)
+
+	learnerID := s.learnerID(w, r)
+	attempt := dialogs.ClozeAttempt{LearnerID: learnerID, DialogID: dialogID}
+	for _, blank := range result.Blanks {
+		attempt.Answers = append(attempt.Answers, dialogs.ClozeAnswer{
+			TurnID:   blank.TurnID,
+			Expected: blank.Answer,
+			Given:    blank.Given,
+			Correct:  blank.Verdict.Accepted(),
+		})
+	}
+	if _, err := s.dialogs.RecordClozeAttempt(r.Context(), attempt); err != nil {
+		s.dialogError(w, err)
+		return
+	}
+	attempts, err := s.dialogs.ClozeAttempts(r.Context(), learnerID, dialogID, practiceAttempts)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.renderPartial(w, "cloze_exercise.html", map[string]any{
+		"Dialog":   dlg,
+		"Cloze":    cloze,
+		"Result":   &result,
+		"Attempts": attempts,
+		"Lang":     s.getLanguage(r),
+		"BasePath": s.basePath,
+	})
+}
+
+// learnerID returns the anonymous learner id of the browser, setting a new
+// one on first use.
+func (s *Server) learnerID(w http.ResponseWriter, r *http.Request) uuid.UUID {
+	if cookie, err := r.Cookie(learnerCookie); err == nil {
+		if id, err := uuid.Parse(cookie.Value); err == nil {
+			return id
+		}
+	}
+	id := uuid.New()
+	http.SetCookie(w, &http.Cookie{
+		Name:     learnerCookie,
+		Value:    id.String(),
+		Path:     "/",
+		MaxAge:   365 * 24 * 60 * 60, // 1 year
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
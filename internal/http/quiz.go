@@ -0,0 +1,119 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/i18n"
+)
+
+// handleQuiz renders the quiz tab of a dialog, offering to generate a quiz
+// when there is none yet.
+func (s *Server) handleQuiz(w http.ResponseWriter, r *http.Request) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+
+	quiz, err := s.dialogs.GetQuiz(r.Context(), dialogID)
+	if err != nil && !errors.Is(err, dialogs.ErrQuizNotFound) {
+		s.quizError(w, err)
+		return
+	}
+	var current *dialogs.Quiz
+	if err == nil {
+		current = &quiz
+	}
+	s.renderQuiz(w, r, dialogID, current, nil)
+}
+
+// handleGenerateQuiz asks the LLM for a new quiz, replacing the earlier one.
+func (s *Server) handleGenerateQuiz(w http.ResponseWriter, r *http.Request) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+
+	quiz, err := s.dialogs.GenerateQuiz(r.Context(), dialogID)
+	if err != nil {
+		s.quizError(w, err)
+		return
+	}
+	s.renderQuiz(w, r, dialogID, &quiz, nil)
+}
+
+// handleScoreQuiz scores the answers to the stored quiz of a dialog. Fields
+// are named q:<index> and hold the index of the chosen answer.
+func (s *Server) handleScoreQuiz(w http.ResponseWriter, r *http.Request) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+
+	quiz, err := s.dialogs.GetQuiz(r.Context(), dialogID)
+	if err != nil {
+		s.quizError(w, err)
+		return
+	}
+
+	answers := make([]int, len(quiz.Questions))
+	for i := range answers {
+		answers[i] = -1
+	}
+	for key := range r.PostForm {
+		name, field, _ := strings.Cut(key, ":")
+		if name != "q" {
+			continue
+		}
+		i, err := strconv.Atoi(field)
+		if err != nil || i < 0 || i >= len(answers) {
+			s.clientError(w, http.StatusBadRequest, "invalid question")
+			return
+		}
+		answer, err := strconv.Atoi(r.PostForm.Get(key))
+		if err != nil {
+			s.clientError(w, http.StatusBadRequest, "invalid answer")
+			return
+		}
+		answers[i] = answer
+	}
+	score := quiz.Score(answers)
+	s.renderQuiz(w, r, dialogID, &quiz, &score)
+}
+
+func (s *Server) renderQuiz(w http.ResponseWriter, r *http.Request, dialogID uuid.UUID, quiz *dialogs.Quiz, score *dialogs.QuizScore) {
+	lang := s.getLanguage(r)
+	s.renderPartial(w, "dialog_quiz.html", map[string]any{
+		"DialogID": dialogID,
+		"Quiz":     quiz,
+		"Score":    score,
+		// True/false questions are answered like two-option questions.
+		"TrueFalse": []string{i18n.Get(lang, "quiz_true"), i18n.Get(lang, "quiz_false")},
+		"Lang":      lang,
+		"BasePath":  s.basePath,
+	})
+}
+
+func (s *Server) quizError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, dialogs.ErrQuizNotFound):
+		s.clientError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, dialogs.ErrQuizUnsupported):
+		s.clientError(w, http.StatusNotImplemented, err.Error())
+	default:
+		s.dialogError(w, err)
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// reviewForecastDays is how many days of due counts the index page shows.
+const reviewForecastDays = 7
+
+var reviewGrades = map[string]dialogs.ReviewGrade{
+	"again": dialogs.ReviewAgain,
+	"hard":  dialogs.ReviewHard,
+	"good":  dialogs.ReviewGood,
+	"easy":  dialogs.ReviewEasy,
+}
+
+func (s *Server) handleReview(w http.ResponseWriter, r *http.Request) {
+	lang := s.getLanguage(r)
+	payload, err := s.reviewCardPayload(r)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	s.renderPage(w, lang, "LevelTalk — review", "review.html", payload)
+}
+
+// handleGradeReview grades a review item and answers htmx requests with the
+// next due card.
+func (s *Server) handleGradeReview(w http.ResponseWriter, r *http.Request) {
+	itemID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid review item id")
+		return
+	}
+	grade, ok := reviewGrades[r.FormValue("grade")]
+	if !ok {
+		s.clientError(w, http.StatusBadRequest, "invalid grade")
+		return
+	}
+
+	if _, err := s.dialogs.GradeReview(r.Context(), itemID, grade); err != nil {
+		if errors.Is(err, dialogs.ErrNotFound) {
+			s.clientError(w, http.StatusNotFound, "review item not found")
+			return
+		}
+		s.serverError(w, err)
+		return
+	}
+
+	payload, err := s.reviewCardPayload(r)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	s.renderPartial(w, "review_card.html", payload)
+}
+
+// reviewCardPayload loads the next due card; Card is nil when nothing is due.
+func (s *Server) reviewCardPayload(r *http.Request) (map[string]any, error) {
+	payload := map[string]any{
+		"Card":     nil,
+		"Lang":     s.getLanguage(r),
+		"BasePath": s.basePath,
+	}
+	card, err := s.dialogs.NextReview(r.Context())
+	if errors.Is(err, dialogs.ErrNoReviewDue) {
+		return payload, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	payload["Card"] = &card
+	return payload, nil
+}
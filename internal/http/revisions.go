@@ -0,0 +1,177 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// handleEditForm answers htmx requests with the inline editor for a dialog.
+func (s *Server) handleEditForm(w http.ResponseWriter, r *http.Request) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+
+	dlg, err := s.dialogs.GetDialog(r.Context(), dialogID)
+	if err != nil {
+		s.dialogError(w, err)
+		return
+	}
+
+	s.renderPartial(w, "dialog_edit.html", map[string]any{
+		"Dialog":   dlg,
+		"Lang":     s.getLanguage(r),
+		"BasePath": s.basePath,
+	})
+}
+
+// handleEdit saves the inline editor. Fields are named title,
+// speaker:<name>, turn:<turn id> and translation:<word>.
+func (s *Server) handleEdit(w http.ResponseWriter, r *http.Request) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+
+	edit := dialogs.DialogEdit{
+		Speakers:     make(map[string]string),
+		Turns:        make(map[uuid.UUID]string),
+		Translations: make(map[string]string),
+	}
+	for key := range r.PostForm {
+		value := r.PostForm.Get(key)
+		name, field, _ := strings.Cut(key, ":")
+		switch name {
+		case "title":
+			edit.Title = &value
+		case "speaker":
+			edit.Speakers[field] = value
+		case "translation":
+			edit.Translations[field] = value
+		case "turn":
+			turnID, err := uuid.Parse(field)
+			if err != nil {
+				s.clientError(w, http.StatusBadRequest, "invalid turn id")
+				return
+			}
+			edit.Turns[turnID] = value
+		}
+	}
+
+	if _, err := s.dialogs.EditDialog(r.Context(), dialogID, edit); err != nil {
+		s.dialogError(w, err)
+		return
+	}
+
+	s.redirectToDialog(w, r, dialogID)
+}
+
+func (s *Server) handleRevision(w http.ResponseWriter, r *http.Request) {
+	lang := s.getLanguage(r)
+	dialogID, number, ok := s.revisionParams(w, r)
+	if !ok {
+		return
+	}
+
+	dlg, err := s.dialogs.GetDialog(r.Context(), dialogID)
+	if err != nil {
+		s.dialogError(w, err)
+		return
+	}
+	rev, err := s.dialogs.GetRevision(r.Context(), dialogID, number)
+	if err != nil {
+		s.dialogError(w, err)
+		return
+	}
+
+	s.renderPage(w, lang, "LevelTalk — revision", "dialog_revision.html", map[string]any{
+		"Dialog":   dlg,
+		"Revision": rev,
+		"Lang":     lang,
+		"BasePath": s.basePath,
+	})
+}
+
+func (s *Server) handleRestoreRevision(w http.ResponseWriter, r *http.Request) {
+	dialogID, number, ok := s.revisionParams(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := s.dialogs.RestoreRevision(r.Context(), dialogID, number); err != nil {
+		s.dialogError(w, err)
+		return
+	}
+
+	s.redirectToDialog(w, r, dialogID)
+}
+
+func (s *Server) handleRevisionAudio(w http.ResponseWriter, r *http.Request) {
+	dialogID, number, ok := s.revisionParams(w, r)
+	if !ok {
+		return
+	}
+	turnID, err := uuid.Parse(chi.URLParam(r, "turnID"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid turn id")
+		return
+	}
+
+	audio, err := s.dialogs.OpenRevisionAudio(r.Context(), dialogID, number, turnID)
+	if err != nil {
+		if errors.Is(err, dialogs.ErrNotFound) || errors.Is(err, dialogs.ErrAudioNotFound) {
+			s.clientError(w, http.StatusNotFound, "audio not found")
+			return
+		}
+		s.serverError(w, err)
+		return
+	}
+	defer audio.Content.Close()
+
+	w.Header().Set("Content-Type", audio.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	if audio.ETag != "" {
+		w.Header().Set("ETag", audio.ETag)
+	}
+	http.ServeContent(w, r, "", audio.ModTime, audio.Content)
+}
+
+// revisionParams parses the dialog id and revision number of the request,
+// answering with 400 when either is malformed.
+func (s *Server) revisionParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, int, bool) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return uuid.Nil, 0, false
+	}
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil || number < 1 {
+		s.clientError(w, http.StatusBadRequest, "invalid revision number")
+		return uuid.Nil, 0, false
+	}
+	return dialogID, number, true
+}
+
+func (s *Server) dialogError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, dialogs.ErrNotFound):
+		s.clientError(w, http.StatusNotFound, "dialog not found")
+	case errors.Is(err, dialogs.ErrInvalidInput):
+		s.clientError(w, http.StatusBadRequest, err.Error())
+	default:
+		s.serverError(w, err)
+	}
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+func (s *Server) handleRolePlayIndex(w http.ResponseWriter, r *http.Request) {
+	lang := s.getLanguage(r)
+	sessions, err := s.dialogs.ListRolePlays(r.Context(), 10)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
+	s.renderPage(w, lang, "LevelTalk — role-play", "roleplay.html", map[string]any{
+		"Languages":  s.languages,
+		"CEFRLevels": s.cefrLevels,
+		"Sessions":   sessions,
+		"Lang":       lang,
+		"BasePath":   s.basePath,
+	})
+}
+
+func (s *Server) handleStartRolePlay(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+
+	session, err := s.dialogs.StartRolePlay(r.Context(), dialogs.RolePlayInput{
+		InputLanguage:  r.FormValue("input_language"),
+		DialogLanguage: r.FormValue("dialog_language"),
+		CEFRLevel:      r.FormValue("cefr_level"),
+		Scenario:       r.FormValue("scenario"),
+		InputWords:     parseWords(r.FormValue("input_words")),
+		LearnerSpeaker: r.FormValue("learner_speaker"),
+		PartnerSpeaker: r.FormValue("partner_speaker"),
+		PartnerGender:  r.FormValue("partner_gender"),
+	})
+	if err != nil {
+		s.rolePlayError(w, err)
+		return
+	}
+
+	s.redirect(w, r, "/roleplay/"+session.ID.String())
+}
+
+func (s *Server) handleRolePlay(w http.ResponseWriter, r *http.Request) {
+	lang := s.getLanguage(r)
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid session id")
+		return
+	}
+
+	session, err := s.dialogs.GetRolePlay(r.Context(), sessionID)
+	if err != nil {
+		s.rolePlayError(w, err)
+		return
+	}
+
+	s.renderPage(w, lang, "LevelTalk — role-play", "roleplay_session.html", map[string]any{
+		"Session":   session,
+		"Messages":  session.Messages,
+		"MaxLength": dialogs.MaxRolePlayMessageLength,
+		"Lang":      lang,
+		"BasePath":  s.basePath,
+	})
+}
+
+// handleRolePlayMessage answers htmx requests with just the learner message
+// and the partner's reply, to be appended to the transcript.
+func (s *Server) handleRolePlayMessage(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid session id")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+
+	session, err := s.dialogs.SendRolePlayMessage(r.Context(), sessionID, r.FormValue("text"))
+	if err != nil {
+		s.rolePlayError(w, err)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		s.redirect(w, r, "/roleplay/"+session.ID.String())
+		return
+	}
+	s.renderPartial(w, "roleplay_messages.html", map[string]any{
+		"Session":  session,
+		"Messages": session.Messages[len(session.Messages)-2:],
+		"Autoplay": true,
+		"Lang":     s.getLanguage(r),
+		"BasePath": s.basePath,
+	})
+}
+
+func (s *Server) handleConvertRolePlay(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid session id")
+		return
+	}
+
+	dlg, err := s.dialogs.ConvertRolePlay(r.Context(), sessionID)
+	if err != nil {
+		s.rolePlayError(w, err)
+		return
+	}
+
+	s.redirectToDialog(w, r, dlg.ID)
+}
+
+func (s *Server) handleRolePlayAudio(w http.ResponseWriter, r *http.Request) {
+	sessionID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid session id")
+		return
+	}
+	messageID, err := uuid.Parse(chi.URLParam(r, "messageID"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid message id")
+		return
+	}
+
+	audio, err := s.dialogs.OpenRolePlayAudio(r.Context(), sessionID, messageID)
+	if err != nil {
+		if errors.Is(err, dialogs.ErrNotFound) || errors.Is(err, dialogs.ErrAudioNotFound) {
+			s.clientError(w, http.StatusNotFound, "audio not found")
+			return
+		}
+		s.serverError(w, err)
+		return
+	}
+	defer audio.Content.Close()
+
+	w.Header().Set("Content-Type", audio.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	if audio.ETag != "" {
+		w.Header().Set("ETag", audio.ETag)
+	}
+	http.ServeContent(w, r, "", audio.ModTime, audio.Content)
+}
+
+func (s *Server) rolePlayError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, dialogs.ErrNotFound):
+		s.clientError(w, http.StatusNotFound, "role-play session not found")
+	case errors.Is(err, dialogs.ErrInvalidInput):
+		s.clientError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, dialogs.ErrRolePlayUnsupported):
+		s.clientError(w, http.StatusNotImplemented, err.Error())
+	default:
+		s.serverError(w, err)
+	}
+}
@@ -3,19 +3,23 @@ package http
 import (
 	"archive/zip"
 	"bytes"
-	"encoding/base64"
+	"context"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 
+	"leveltalk/internal/audiostore"
 	"leveltalk/internal/dialogs"
 	"leveltalk/internal/i18n"
 )
@@ -39,7 +43,7 @@ func NewServer(logger *slog.Logger, service *dialogs.Service, templates *templat
 		templates:  templates,
 		staticFS:   staticFS,
 		languages:  []string{"ru", "en", "es", "fi", "de", "fr"},
-		cefrLevels: []string{"A1", "A2", "B1", "B2", "C1", "C2"},
+		cefrLevels: dialogs.CEFRLevels,
 		basePath:   basePath,
 	}
 
@@ -53,11 +57,43 @@ func NewServer(logger *slog.Logger, service *dialogs.Service, templates *templat
 
 	r.Get("/", srv.handleIndex)
 	r.Post("/dialogs", srv.handleCreateDialog)
+	r.Get("/jobs/{id}", srv.handleJob)
+	r.Get("/jobs/{id}/events", srv.handleJobEvents)
 	r.Get("/dialogs/search", srv.handleSearch)
 	r.Get("/dialogs/{id}", srv.handleDetail)
 	r.Delete("/dialogs/{id}", srv.handleDelete)
+	r.Post("/dialogs/{id}/relevel", srv.handleRelevel)
+	r.Post("/dialogs/{id}/continue", srv.handleContinue)
+	r.Get("/dialogs/{id}/edit", srv.handleEditForm)
+	r.Put("/dialogs/{id}", srv.handleEdit)
+	r.Get("/dialogs/{id}/revisions/{number}", srv.handleRevision)
+	r.Post("/dialogs/{id}/revisions/{number}/restore", srv.handleRestoreRevision)
+	r.Get("/dialogs/{id}/revisions/{number}/audio/{turnID}", srv.handleRevisionAudio)
+	r.Post("/dialogs/{id}/turns/{turnID}/regenerate", srv.handleRegenerateTurn)
+	r.Post("/dialogs/{id}/turns/{turnID}/audio", srv.handleResynthesizeTurn)
+	r.Get("/dialogs/{id}/practice/cloze", srv.handleCloze)
+	r.Post("/dialogs/{id}/practice/cloze", srv.handleGradeCloze)
+	r.Get("/dialogs/{id}/practice/dictation", srv.handleDictation)
+	r.Get("/dialogs/{id}/practice/dictation/{turnID}", srv.handleDictationTurn)
+	r.Post("/dialogs/{id}/practice/dictation/{turnID}", srv.handleGradeDictation)
+	r.Get("/dialogs/{id}/quiz", srv.handleQuiz)
+	r.Post("/dialogs/{id}/quiz", srv.handleGenerateQuiz)
+	r.Post("/dialogs/{id}/quiz/answers", srv.handleScoreQuiz)
 	r.Get("/dialogs/download/text", srv.handleDownloadText)
 	r.Get("/dialogs/download/audio", srv.handleDownloadAudio)
+	r.Get("/dialogs/download/anki", srv.handleDownloadAnki)
+	r.Get("/audio/{turnID}", srv.handleAudio)
+	r.Get("/review", srv.handleReview)
+	r.Post("/review/{id}", srv.handleGradeReview)
+	r.Get("/roleplay", srv.handleRolePlayIndex)
+	r.Post("/roleplay", srv.handleStartRolePlay)
+	r.Get("/roleplay/{id}", srv.handleRolePlay)
+	r.Post("/roleplay/{id}/messages", srv.handleRolePlayMessage)
+	r.Post("/roleplay/{id}/convert", srv.handleConvertRolePlay)
+	r.Get("/roleplay/{id}/audio/{messageID}", srv.handleRolePlayAudio)
+	r.Get("/admin", srv.handleAdmin)
+	r.Get("/admin/repair-audio", srv.handleRepairAudioStatus)
+	r.Post("/admin/repair-audio", srv.handleRepairAudio)
 	r.Get("/lang/{lang}", srv.handleSetLanguage)
 
 	return r
@@ -72,6 +108,12 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	forecast, err := s.dialogs.ReviewForecast(ctx, reviewForecastDays)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
 	// Build query params for download links (empty for initial page)
 	queryParams := ""
 
@@ -79,6 +121,7 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		"Languages":   s.languages,
 		"CEFRLevels":  s.cefrLevels,
 		"Dialogs":     dialogsList,
+		"Forecast":    forecast,
 		"QueryParams":  queryParams,
 		"Lang":        lang,
 		"UILanguages": s.getUILanguages(),
@@ -100,12 +143,58 @@ func (s *Server) handleCreateDialog(w http.ResponseWriter, r *http.Request) {
 		InputWords:     parseWords(r.FormValue("input_words")),
 	}
 
-	if _, err := s.dialogs.CreateDialog(r.Context(), input); err != nil {
+	job, err := s.dialogs.EnqueueDialog(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, dialogs.ErrInvalidInput) {
+			s.clientError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		s.serverError(w, err)
 		return
 	}
 
-	s.renderDialogList(w, r)
+	s.renderJobCard(w, r, job)
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+
+	job, err := s.dialogs.GetJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, dialogs.ErrNotFound) {
+			s.clientError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		s.serverError(w, err)
+		return
+	}
+
+	if job.State == dialogs.JobDone {
+		// Lets the dialog list refresh itself once the new dialog exists.
+		w.Header().Set("HX-Trigger", "dialogCreated")
+	}
+	s.renderJobCard(w, r, job)
+}
+
+func (s *Server) renderJobCard(w http.ResponseWriter, r *http.Request, job dialogs.Job) {
+	lang := s.getLanguage(r)
+	s.renderPartial(w, "job_card.html", map[string]any{
+		"Job": job,
+		"Status": jobStatusView{
+			State:       job.State,
+			DialogID:    job.DialogID,
+			Retrying:    job.State == dialogs.JobQueued && job.LastError != "",
+			RefreshList: job.Kind == dialogs.JobCreate,
+			Lang:        lang,
+			BasePath:    s.basePath,
+		},
+		"Lang":     lang,
+		"BasePath": s.basePath,
+	})
 }
 
 func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
@@ -166,14 +255,108 @@ func (s *Server) handleDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	levels, err := s.dialogs.ListDialogLevels(r.Context(), dialogID)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+	revisions, err := s.dialogs.ListRevisions(r.Context(), dialogID)
+	if err != nil {
+		s.serverError(w, err)
+		return
+	}
+
 	s.renderPage(w, lang, "LevelTalk — dialog detail", "dialog_detail.html", map[string]any{
 		"Dialog":      dlg,
 		"Lang":        lang,
 		"UILanguages": s.getUILanguages(),
 		"BasePath":    s.basePath,
+		"CEFRLevels":  s.cefrLevels,
+		"OtherLevels": levels,
+		"Revisions":   revisions,
+		"Turns":       dlg.Turns,
+
+		"ContinueTurns":   []int{2, dialogs.DefaultContinueTurns, 6},
+		"ContinueDefault": dialogs.DefaultContinueTurns,
 	})
 }
 
+func (s *Server) handleRelevel(w http.ResponseWriter, r *http.Request) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+
+	job, err := s.dialogs.EnqueueRelevel(r.Context(), dialogID, r.FormValue("cefr_level"))
+	if err != nil {
+		switch {
+		case errors.Is(err, dialogs.ErrNotFound):
+			s.clientError(w, http.StatusNotFound, "dialog not found")
+		case errors.Is(err, dialogs.ErrInvalidInput):
+			s.clientError(w, http.StatusBadRequest, err.Error())
+		default:
+			s.serverError(w, err)
+		}
+		return
+	}
+
+	s.renderJobCard(w, r, job)
+}
+
+func (s *Server) handleContinue(w http.ResponseWriter, r *http.Request) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid form data")
+		return
+	}
+	turns, err := strconv.Atoi(r.FormValue("turns"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid turn count")
+		return
+	}
+
+	job, err := s.dialogs.EnqueueContinue(r.Context(), dialogID, turns)
+	if err != nil {
+		switch {
+		case errors.Is(err, dialogs.ErrNotFound):
+			s.clientError(w, http.StatusNotFound, "dialog not found")
+		case errors.Is(err, dialogs.ErrInvalidInput):
+			s.clientError(w, http.StatusBadRequest, err.Error())
+		default:
+			s.serverError(w, err)
+		}
+		return
+	}
+
+	s.renderJobCard(w, r, job)
+}
+
+// redirectToDialog sends the browser to a dialog's detail page.
+func (s *Server) redirectToDialog(w http.ResponseWriter, r *http.Request, id uuid.UUID) {
+	s.redirect(w, r, "/dialogs/"+id.String())
+}
+
+// redirect sends the browser to path below the base path, through
+// HX-Redirect for htmx requests so the whole page is loaded.
+func (s *Server) redirect(w http.ResponseWriter, r *http.Request, path string) {
+	target := s.basePath + path
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", target)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
+
 func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
 	idParam := chi.URLParam(r, "id")
 	dialogID, err := uuid.Parse(idParam)
@@ -195,6 +378,33 @@ func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
 	s.renderDialogList(w, r)
 }
 
+func (s *Server) handleAudio(w http.ResponseWriter, r *http.Request) {
+	turnID, err := uuid.Parse(chi.URLParam(r, "turnID"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid turn id")
+		return
+	}
+
+	audio, err := s.dialogs.OpenTurnAudio(r.Context(), turnID)
+	if err != nil {
+		if errors.Is(err, dialogs.ErrNotFound) || errors.Is(err, dialogs.ErrAudioNotFound) {
+			s.clientError(w, http.StatusNotFound, "audio not found")
+			return
+		}
+		s.serverError(w, err)
+		return
+	}
+	defer audio.Content.Close()
+
+	w.Header().Set("Content-Type", audio.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	if audio.ETag != "" {
+		w.Header().Set("ETag", audio.ETag)
+	}
+	// ServeContent handles Range, If-None-Match and If-Modified-Since for us.
+	http.ServeContent(w, r, "", audio.ModTime, audio.Content)
+}
+
 type pageView struct {
 	Title       string
 	Body        template.HTML
@@ -247,12 +457,15 @@ func (s *Server) clientError(w http.ResponseWriter, status int, msg string) {
 	http.Error(w, msg, status)
 }
 
-func (s *Server) handleDownloadText(w http.ResponseWriter, r *http.Request) {
+// selectedDialogs returns the dialogs a download covers: those named by id
+// query params or, without any, those matching the list filter. It answers
+// the request itself when there is nothing to download.
+func (s *Server) selectedDialogs(w http.ResponseWriter, r *http.Request) ([]dialogs.Dialog, bool) {
 	ctx := r.Context()
-	
+
 	var dialogsList []dialogs.Dialog
 	var err error
-	
+
 	// Check if specific IDs are provided
 	selectedIDs := r.URL.Query()["id"]
 	if len(selectedIDs) > 0 {
@@ -277,12 +490,20 @@ func (s *Server) handleDownloadText(w http.ResponseWriter, r *http.Request) {
 		dialogsList, err = s.dialogs.SearchDialogs(ctx, filter)
 		if err != nil {
 			s.serverError(w, err)
-			return
+			return nil, false
 		}
 	}
 
 	if len(dialogsList) == 0 {
 		s.clientError(w, http.StatusNotFound, "no dialogs found")
+		return nil, false
+	}
+	return dialogsList, true
+}
+
+func (s *Server) handleDownloadText(w http.ResponseWriter, r *http.Request) {
+	dialogsList, ok := s.selectedDialogs(w, r)
+	if !ok {
 		return
 	}
 
@@ -331,7 +552,7 @@ func (s *Server) handleDownloadText(w http.ResponseWriter, r *http.Request) {
 
 		buf.WriteString("\nDialog:\n")
 		for _, turn := range dlg.Turns {
-			buf.WriteString(fmt.Sprintf("%s: %s\n", turn.Speaker, turn.Text))
+			writeInterlinearTurn(&buf, turn)
 		}
 		buf.WriteString("\n\n")
 	}
@@ -342,42 +563,21 @@ func (s *Server) handleDownloadText(w http.ResponseWriter, r *http.Request) {
 	w.Write(buf.Bytes())
 }
 
+// writeInterlinearTurn writes a turn and, when present, its translation on
+// the next line, aligned under the text.
+func writeInterlinearTurn(buf *bytes.Buffer, turn dialogs.DialogTurn) {
+	prefix := turn.Speaker + ": "
+	buf.WriteString(prefix + turn.Text + "\n")
+	if turn.Translation != "" {
+		buf.WriteString(strings.Repeat(" ", utf8.RuneCountInString(prefix)) + turn.Translation + "\n")
+	}
+}
+
 func (s *Server) handleDownloadAudio(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	
-	var dialogsList []dialogs.Dialog
-	var err error
-	
-	// Check if specific IDs are provided
-	selectedIDs := r.URL.Query()["id"]
-	if len(selectedIDs) > 0 {
-		// Fetch specific dialogs by ID
-		dialogsList = make([]dialogs.Dialog, 0, len(selectedIDs))
-		for _, idStr := range selectedIDs {
-			id, parseErr := uuid.Parse(idStr)
-			if parseErr != nil {
-				s.logger.Warn("invalid dialog id in download request", slog.String("id", idStr), slog.String("error", parseErr.Error()))
-				continue
-			}
-			dlg, fetchErr := s.dialogs.GetDialog(ctx, id)
-			if fetchErr != nil {
-				s.logger.Warn("failed to fetch dialog", slog.String("id", idStr), slog.String("error", fetchErr.Error()))
-				continue
-			}
-			dialogsList = append(dialogsList, dlg)
-		}
-	} else {
-		// Use filter-based search
-		filter := s.buildFilterFromRequest(r)
-		dialogsList, err = s.dialogs.SearchDialogs(ctx, filter)
-		if err != nil {
-			s.serverError(w, err)
-			return
-		}
-	}
 
-	if len(dialogsList) == 0 {
-		s.clientError(w, http.StatusNotFound, "no dialogs found")
+	dialogsList, ok := s.selectedDialogs(w, r)
+	if !ok {
 		return
 	}
 
@@ -387,29 +587,13 @@ func (s *Server) handleDownloadAudio(w http.ResponseWriter, r *http.Request) {
 	audioCount := 0
 	for _, dlg := range dialogsList {
 		for _, turn := range dlg.Turns {
-			if turn.AudioURL == "" {
-				continue
-			}
-
-			// Extract base64 data from data URL
-			var audioData []byte
-			if strings.HasPrefix(turn.AudioURL, "data:audio/") {
-				// Format: data:audio/mpeg;base64,<data>
-				parts := strings.SplitN(turn.AudioURL, ",", 2)
-				if len(parts) == 2 {
-					var err error
-					audioData, err = base64.StdEncoding.DecodeString(parts[1])
-					if err != nil {
-						s.logger.Warn("failed to decode audio data",
-							slog.String("dialog_id", dlg.ID.String()),
-							slog.String("turn_id", turn.ID.String()),
-							slog.String("error", err.Error()),
-						)
-						continue
-					}
-				}
-			} else {
-				// Skip non-data URLs (like placeholder.mp3)
+			audioData, err := s.turnAudio(ctx, turn)
+			if err != nil {
+				s.logger.Warn("failed to load turn audio",
+					slog.String("dialog_id", dlg.ID.String()),
+					slog.String("turn_id", turn.ID.String()),
+					slog.String("error", err.Error()),
+				)
 				continue
 			}
 
@@ -474,6 +658,24 @@ func (s *Server) handleDownloadAudio(w http.ResponseWriter, r *http.Request) {
 	w.Write(zipBuf.Bytes())
 }
 
+// turnAudio loads a turn's audio bytes from the store, falling back to legacy
+// inline data URLs. Placeholder URLs yield no data.
+func (s *Server) turnAudio(ctx context.Context, turn dialogs.DialogTurn) ([]byte, error) {
+	if turn.HasStoredAudio() {
+		audio, err := s.dialogs.OpenTurnAudio(ctx, turn.ID)
+		if err != nil {
+			return nil, err
+		}
+		defer audio.Content.Close()
+		return io.ReadAll(audio.Content)
+	}
+	if strings.HasPrefix(turn.AudioURL, "data:audio/") {
+		data, _, err := audiostore.DecodeDataURL(turn.AudioURL)
+		return data, err
+	}
+	return nil, nil
+}
+
 func (s *Server) buildQueryParams(r *http.Request) string {
 	var params []string
 	if v := strings.TrimSpace(r.FormValue("input_language")); v != "" {
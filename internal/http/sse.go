@@ -0,0 +1,233 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+const sseHeartbeat = 15 * time.Second
+
+// jobStatusView feeds the job_status.html partial.
+type jobStatusView struct {
+	JobID       uuid.UUID
+	State       dialogs.JobState
+	Title       string
+	Generated   int
+	Synthesized int
+	TurnCount   int
+	DialogID    *uuid.UUID
+	Retrying    bool
+	RefreshList bool // Reload the dialog list once the dialog exists
+	ClearTurns  bool // Empty the streamed turns when a new attempt starts
+	Lang        string
+	BasePath    string
+}
+
+// handleJobEvents streams generation progress as Server-Sent Events. The
+// default stream carries JSON-encoded dialogs.Event values named after their
+// type; ?view=html streams rendered partials for the htmx SSE extension.
+func (s *Server) handleJobEvents(w http.ResponseWriter, r *http.Request) {
+	jobID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid job id")
+		return
+	}
+
+	job, err := s.dialogs.GetJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, dialogs.ErrNotFound) {
+			s.clientError(w, http.StatusNotFound, "job not found")
+			return
+		}
+		s.serverError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.serverError(w, errors.New("streaming unsupported"))
+		return
+	}
+
+	// EventSource resends the last seen id when it reconnects.
+	lastSeq, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+
+	history, events, cancel := s.dialogs.Subscribe(jobID)
+	defer cancel()
+
+	var pending []dialogs.Event
+	for _, ev := range history {
+		if ev.Seq > lastSeq {
+			pending = append(pending, ev)
+		}
+	}
+	if len(pending) == 0 && job.State.Finished() {
+		if lastSeq > 0 {
+			// The client already saw the end; 204 tells EventSource to stop reconnecting.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		// The job finished before anyone listened and its history has expired.
+		pending = append(pending, finalEvent(job, lastSeq+1))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	html := r.URL.Query().Get("view") == "html"
+	status := jobStatusView{
+		JobID:       job.ID,
+		State:       job.State,
+		RefreshList: job.Kind == dialogs.JobCreate,
+		Lang:        s.getLanguage(r),
+		BasePath:    s.basePath,
+	}
+	send := func(ev dialogs.Event) bool {
+		lastSeq = max(lastSeq, ev.Seq)
+		var err error
+		if html {
+			err = s.writeHTMLEvent(w, ev, &status)
+		} else {
+			err = writeJSONEvent(w, ev)
+		}
+		if err != nil {
+			s.logger.Warn("write sse event", slog.String("error", err.Error()))
+			return false
+		}
+		flusher.Flush()
+		return !ev.Type.Terminal() || ev.Retrying
+	}
+
+	for _, ev := range pending {
+		if !send(ev) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			// Jobs run by another instance never publish here; the row still tells us when they end.
+			if current, err := s.dialogs.GetJob(r.Context(), jobID); err == nil && current.State.Finished() {
+				send(finalEvent(current, lastSeq+1))
+				return
+			}
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if !send(ev) {
+				return
+			}
+		}
+	}
+}
+
+// finalEvent reconstructs the terminal event of a finished job from its row.
+// It carries seq as its id, which must be non-zero: an EventSource that
+// reconnects after it then sends Last-Event-ID and is told to stop with 204.
+func finalEvent(job dialogs.Job, seq int) dialogs.Event {
+	if job.State == dialogs.JobDone {
+		return dialogs.Event{Type: dialogs.EventPersisted, JobID: job.ID, Seq: seq, At: job.UpdatedAt, DialogID: job.DialogID}
+	}
+	return dialogs.Event{Type: dialogs.EventFailed, JobID: job.ID, Seq: seq, At: job.UpdatedAt, Error: job.LastError}
+}
+
+func writeJSONEvent(w http.ResponseWriter, ev dialogs.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	return writeSSE(w, ev.Seq, string(ev.Type), data)
+}
+
+// writeHTMLEvent renders ev into the partials the job card swaps in. status
+// accumulates state across events so every status swap is complete.
+func (s *Server) writeHTMLEvent(w http.ResponseWriter, ev dialogs.Event, status *jobStatusView) error {
+	switch ev.Type {
+	case dialogs.EventPromptSent:
+		status.State = dialogs.JobGeneratingText
+		status.Generated = 0
+		status.Synthesized = 0
+		status.Retrying = false
+		// A regeneration or retry streams its turns afresh.
+		status.ClearTurns = true
+		defer func() { status.ClearTurns = false }()
+	case dialogs.EventTurnGenerated:
+		status.Generated++
+		if err := s.writeRenderedEvent(w, ev.Seq, "turn", "job_turn.html", map[string]any{
+			"Turn": ev.Turn,
+			"Lang": status.Lang,
+		}); err != nil {
+			return err
+		}
+	case dialogs.EventTurnsParsed:
+		status.State = dialogs.JobSynthesizing
+		status.Title = ev.Title
+		status.TurnCount = ev.TurnCount
+	case dialogs.EventTurnSynthesized:
+		status.State = dialogs.JobSynthesizing
+		status.Synthesized++
+		status.TurnCount = ev.TurnCount
+	case dialogs.EventPersisted:
+		status.State = dialogs.JobDone
+		status.DialogID = ev.DialogID
+	case dialogs.EventFailed:
+		status.Retrying = ev.Retrying
+		if ev.Retrying {
+			status.State = dialogs.JobQueued
+		} else {
+			status.State = dialogs.JobFailed
+		}
+	}
+	return s.writeRenderedEvent(w, ev.Seq, "status", "job_status.html", status)
+}
+
+func (s *Server) writeRenderedEvent(w http.ResponseWriter, seq int, name, tmpl string, data any) error {
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, tmpl, data); err != nil {
+		return fmt.Errorf("render %s: %w", tmpl, err)
+	}
+	return writeSSE(w, seq, name, buf.Bytes())
+}
+
+// writeSSE writes one event, prefixing every payload line with "data:".
+func writeSSE(w http.ResponseWriter, seq int, name string, data []byte) error {
+	var buf bytes.Buffer
+	if seq > 0 {
+		fmt.Fprintf(&buf, "id: %d\n", seq)
+	}
+	fmt.Fprintf(&buf, "event: %s\n", name)
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		buf.WriteString("data: ")
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// handleRegenerateTurn rewrites one turn. The coverage and revision list
+// change with it, so the whole page is reloaded.
+func (s *Server) handleRegenerateTurn(w http.ResponseWriter, r *http.Request) {
+	dialogID, turnID, ok := s.turnParams(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := s.dialogs.RegenerateTurn(r.Context(), dialogID, turnID); err != nil {
+		s.dialogError(w, err)
+		return
+	}
+
+	s.redirectToDialog(w, r, dialogID)
+}
+
+// handleResynthesizeTurn answers htmx requests with the turn and its new
+// audio, to replace the old markup in place.
+func (s *Server) handleResynthesizeTurn(w http.ResponseWriter, r *http.Request) {
+	dialogID, turnID, ok := s.turnParams(w, r)
+	if !ok {
+		return
+	}
+
+	turn, err := s.dialogs.ResynthesizeTurn(r.Context(), dialogID, turnID)
+	if err != nil {
+		s.dialogError(w, err)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "true" {
+		s.redirectToDialog(w, r, dialogID)
+		return
+	}
+	dlg, err := s.dialogs.GetDialog(r.Context(), dialogID)
+	if err != nil {
+		s.dialogError(w, err)
+		return
+	}
+	s.renderPartial(w, "dialog_turns.html", map[string]any{
+		"Dialog":   dlg,
+		"Turns":    []dialogs.DialogTurn{turn},
+		"Lang":     s.getLanguage(r),
+		"BasePath": s.basePath,
+	})
+}
+
+func (s *Server) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	lang := s.getLanguage(r)
+	payload := map[string]any{
+		"Lang":     lang,
+		"BasePath": s.basePath,
+	}
+	// A repair started earlier, perhaps still running, is shown as it is.
+	if run := s.dialogs.AudioRepairStatus(); !run.StartedAt.IsZero() {
+		payload["Repair"] = s.repairPayload(r, run)
+	}
+	s.renderPage(w, lang, "LevelTalk — admin", "admin.html", payload)
+}
+
+// handleRepairAudio starts synthesizing every placeholder turn again in the
+// background and answers with the state of the run.
+func (s *Server) handleRepairAudio(w http.ResponseWriter, r *http.Request) {
+	s.renderRepairStatus(w, r, s.dialogs.StartAudioRepair(r.Context()))
+}
+
+// handleRepairAudioStatus answers the polling of a running repair.
+func (s *Server) handleRepairAudioStatus(w http.ResponseWriter, r *http.Request) {
+	s.renderRepairStatus(w, r, s.dialogs.AudioRepairStatus())
+}
+
+func (s *Server) renderRepairStatus(w http.ResponseWriter, r *http.Request, run dialogs.AudioRepairRun) {
+	s.renderPartial(w, "admin_repair.html", s.repairPayload(r, run))
+}
+
+func (s *Server) repairPayload(r *http.Request, run dialogs.AudioRepairRun) map[string]any {
+	return map[string]any{
+		"Run":      run,
+		"Lang":     s.getLanguage(r),
+		"BasePath": s.basePath,
+	}
+}
+
+// turnParams parses the dialog and turn ids of the request, answering with
+// 400 when either is malformed.
+func (s *Server) turnParams(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	dialogID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid dialog id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	turnID, err := uuid.Parse(chi.URLParam(r, "turnID"))
+	if err != nil {
+		s.clientError(w, http.StatusBadRequest, "invalid turn id")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return dialogID, turnID, true
+}
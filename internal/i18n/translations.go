@@ -94,6 +94,101 @@ var translations = Translations{
 		"tagline":             "Make your vocabulary speak — in any language, at any level.",
 		"delete":              "Delete",
 		"confirm_delete":       "Are you sure you want to delete this dialog? This action cannot be undone.",
+		"job_queued": "Queued",
+		"job_generating_text": "Writing the dialog…",
+		"job_synthesizing": "Recording audio…",
+		"job_done": "Dialog ready",
+		"job_failed": "Generation failed",
+		"job_retrying": "Retrying after an error",
+		"job_failed_hint": "We could not generate this dialog. Please try again.",
+		"audio_pending": "Audio for this turn is not available yet.",
+		"repair_attempts": "LLM repairs",
+		"coverage_flagged": "Some vocabulary is missing from this dialog. Words used:",
+		"coverage_badge": "Vocabulary missing",
+		"translation": "Translation",
+		"show_translations": "Show all translations",
+		"hide_translations": "Hide all translations",
+		"estimated_level": "Estimated level",
+		"level_mismatch": "differs from the requested level",
+		"other_levels": "Other levels of this dialog",
+		"rewrite_at_level": "Rewrite at level",
+		"rewrite": "Rewrite",
+		"continue_dialog": "Continue",
+		"continue_turns": "New turns",
+		"roleplay": "Role-play",
+		"roleplay_intro": "Play one speaker yourself and talk with a partner who answers at your level.",
+		"start_roleplay": "Start role-play",
+		"scenario": "Scenario",
+		"your_speaker": "Your role",
+		"partner_speaker": "Partner's role",
+		"partner_gender": "Partner's voice",
+		"gender_female": "Female",
+		"gender_male": "Male",
+		"your_message": "Your message",
+		"send": "Send",
+		"correction": "Better:",
+		"convert_to_dialog": "Save as dialog",
+		"roleplay_converted": "This role-play was saved as a dialog.",
+		"recent_roleplays": "Recent role-plays",
+		"no_roleplays": "No role-plays yet.",
+		"edit": "Edit",
+		"title": "Title",
+		"speakers": "Speakers",
+		"turns": "Turns",
+		"save": "Save",
+		"cancel": "Cancel",
+		"edit_audio_hint": "Only turns whose text changes get new audio.",
+		"revisions": "Earlier versions",
+		"revision": "Version",
+		"replaced": "Replaced",
+		"restore": "Restore this version",
+		"regenerate_text": "Regenerate text",
+		"resynthesize_audio": "Re-synthesize audio",
+		"admin": "Admin",
+		"repair_audio": "Repair all placeholder audio",
+		"repair_audio_intro": "Synthesizes again every turn whose audio is missing or a placeholder, in all dialogs.",
+		"turns_synthesized": "Turns synthesized",
+		"turns_repaired": "Turns repaired",
+		"download_selected_anki": "Download Anki deck",
+		"anki_sentences": "with sentence cards",
+		"review": "Review",
+		"start_review": "Start review",
+		"today": "Today",
+		"review_due_now": "Due now",
+		"show_answer": "Show answer",
+		"grade_again": "Again",
+		"grade_hard": "Hard",
+		"grade_good": "Good",
+		"grade_easy": "Easy",
+		"review_nothing_due": "Nothing is due. Come back later or generate a new dialog.",
+		"cloze_exercise": "Fill in the blanks",
+		"cloze_intro": "Type the missing words. Case and missing accents do not count against you, and small typos are accepted.",
+		"cloze_no_blanks": "This dialog has no words to practise yet.",
+		"score": "Score",
+		"cloze_legend": "Wavy underlines mark answers accepted despite a missing accent or a typo.",
+		"try_again": "Try again",
+		"check_answers": "Check answers",
+		"your_attempts": "Your attempts",
+		"dialog_tab": "Dialog",
+		"quiz": "Quiz",
+		"quiz_intro": "Check your understanding with comprehension questions about this dialog.",
+		"generate_quiz": "Generate quiz",
+		"new_quiz": "New questions",
+		"true_or_false": "True or false?",
+		"quiz_true": "True",
+		"quiz_false": "False",
+		"dictation": "Dictation",
+		"dictation_intro": "Listen to each turn and type what you hear. The text appears once you check your answer.",
+		"dictation_no_audio": "This dialog has no audio yet.",
+		"dictation_placeholder": "Type what you hear…",
+		"hardest_turns": "Hardest turns",
+		"dictation_missing": "Missing word",
+		"dictation_extra": "Extra word",
+		"dictation_misspelled": "Misspelled",
+		"dictation_accent": "Diacritics only",
+		"repair_running": "Repair running…",
+		"repair_stopped": "The repair stopped early:",
+		"dialogs_failed": "Dialogs failed",
 	},
 	LangFI: {
 		"app_name":           "LevelTalk",
@@ -128,6 +223,101 @@ var translations = Translations{
 		"tagline":             "Anna sanastollesi ääni — millä tahansa kielellä, millä tahansa tasolla.",
 		"delete":              "Poista",
 		"confirm_delete":       "Haluatko varmasti poistaa tämän vuoropuhelun? Tätä toimintoa ei voi perua.",
+		"job_queued": "Jonossa",
+		"job_generating_text": "Kirjoitetaan vuoropuhelua…",
+		"job_synthesizing": "Äänitetään…",
+		"job_done": "Vuoropuhelu valmis",
+		"job_failed": "Luonti epäonnistui",
+		"job_retrying": "Yritetään uudelleen virheen jälkeen",
+		"job_failed_hint": "Vuoropuhelua ei voitu luoda. Yritä uudelleen.",
+		"audio_pending": "Tämän repliikin ääni ei ole vielä saatavilla.",
+		"repair_attempts": "LLM-korjauksia",
+		"coverage_flagged": "Osa sanastosta puuttuu vuoropuhelusta. Käytetyt sanat:",
+		"coverage_badge": "Sanastoa puuttuu",
+		"translation": "Käännös",
+		"show_translations": "Näytä kaikki käännökset",
+		"hide_translations": "Piilota kaikki käännökset",
+		"estimated_level": "Arvioitu taso",
+		"level_mismatch": "poikkeaa pyydetystä tasosta",
+		"other_levels": "Tämän vuoropuhelun muut tasot",
+		"rewrite_at_level": "Kirjoita uudelleen tasolle",
+		"rewrite": "Kirjoita uudelleen",
+		"continue_dialog": "Jatka",
+		"continue_turns": "Uusia repliikkejä",
+		"roleplay": "Roolileikki",
+		"roleplay_intro": "Ole itse toinen puhujista ja keskustele kumppanin kanssa, joka vastaa tasollasi.",
+		"start_roleplay": "Aloita roolileikki",
+		"scenario": "Tilanne",
+		"your_speaker": "Sinun roolisi",
+		"partner_speaker": "Kumppanin rooli",
+		"partner_gender": "Kumppanin ääni",
+		"gender_female": "Nainen",
+		"gender_male": "Mies",
+		"your_message": "Viestisi",
+		"send": "Lähetä",
+		"correction": "Paremmin:",
+		"convert_to_dialog": "Tallenna vuoropuheluna",
+		"roleplay_converted": "Tämä roolileikki tallennettiin vuoropuheluna.",
+		"recent_roleplays": "Viimeisimmät roolileikit",
+		"no_roleplays": "Ei vielä roolileikkejä.",
+		"edit": "Muokkaa",
+		"title": "Otsikko",
+		"speakers": "Puhujat",
+		"turns": "Repliikit",
+		"save": "Tallenna",
+		"cancel": "Peruuta",
+		"edit_audio_hint": "Vain muutetuille repliikeille luodaan uusi ääni.",
+		"revisions": "Aiemmat versiot",
+		"revision": "Versio",
+		"replaced": "Korvattu",
+		"restore": "Palauta tämä versio",
+		"regenerate_text": "Luo teksti uudelleen",
+		"resynthesize_audio": "Luo ääni uudelleen",
+		"admin": "Ylläpito",
+		"repair_audio": "Korjaa kaikki paikkamerkkiäänet",
+		"repair_audio_intro": "Luo äänen uudelleen kaikkien dialogien repliikeille, joilta ääni puuttuu tai on paikkamerkki.",
+		"turns_synthesized": "Luodut repliikit",
+		"turns_repaired": "Korjatut repliikit",
+		"download_selected_anki": "Lataa Anki-pakka",
+		"anki_sentences": "lausekorttien kanssa",
+		"review": "Kertaus",
+		"start_review": "Aloita kertaus",
+		"today": "Tänään",
+		"review_due_now": "Erääntyneitä nyt",
+		"show_answer": "Näytä vastaus",
+		"grade_again": "Uudelleen",
+		"grade_hard": "Vaikea",
+		"grade_good": "Hyvä",
+		"grade_easy": "Helppo",
+		"review_nothing_due": "Ei kerrattavaa juuri nyt. Palaa myöhemmin tai luo uusi dialogi.",
+		"cloze_exercise": "Täydennä aukot",
+		"cloze_intro": "Kirjoita puuttuvat sanat. Isot kirjaimet ja puuttuvat tarkkeet eivät ole virheitä, ja pienet kirjoitusvirheet hyväksytään.",
+		"cloze_no_blanks": "Tässä dialogissa ei ole vielä harjoiteltavia sanoja.",
+		"score": "Pisteet",
+		"cloze_legend": "Aaltoviiva merkitsee vastaukset, jotka hyväksyttiin puuttuvasta tarkkeesta tai kirjoitusvirheestä huolimatta.",
+		"try_again": "Yritä uudelleen",
+		"check_answers": "Tarkista vastaukset",
+		"your_attempts": "Yrityksesi",
+		"dialog_tab": "Dialogi",
+		"quiz": "Tietovisa",
+		"quiz_intro": "Testaa ymmärrystäsi tätä dialogia koskevilla kysymyksillä.",
+		"generate_quiz": "Luo tietovisa",
+		"new_quiz": "Uudet kysymykset",
+		"true_or_false": "Totta vai tarua?",
+		"quiz_true": "Totta",
+		"quiz_false": "Tarua",
+		"dictation": "Sanelu",
+		"dictation_intro": "Kuuntele jokainen repliikki ja kirjoita, mitä kuulet. Teksti näytetään, kun tarkistat vastauksesi.",
+		"dictation_no_audio": "Tällä dialogilla ei ole vielä ääntä.",
+		"dictation_placeholder": "Kirjoita, mitä kuulet…",
+		"hardest_turns": "Vaikeimmat repliikit",
+		"dictation_missing": "Puuttuva sana",
+		"dictation_extra": "Ylimääräinen sana",
+		"dictation_misspelled": "Kirjoitusvirhe",
+		"dictation_accent": "Vain tarkkeet",
+		"repair_running": "Korjaus käynnissä…",
+		"repair_stopped": "Korjaus keskeytyi:",
+		"dialogs_failed": "Epäonnistuneet dialogit",
 	},
 	LangSV: {
 		"app_name":           "LevelTalk",
@@ -162,6 +352,101 @@ var translations = Translations{
 		"tagline":             "Låt ditt ordförråd tala — på vilket språk som helst, på vilken nivå som helst.",
 		"delete":              "Radera",
 		"confirm_delete":       "Är du säker på att du vill radera denna dialog? Denna åtgärd kan inte ångras.",
+		"job_queued": "I kö",
+		"job_generating_text": "Skriver dialogen…",
+		"job_synthesizing": "Spelar in ljud…",
+		"job_done": "Dialogen är klar",
+		"job_failed": "Genereringen misslyckades",
+		"job_retrying": "Försöker igen efter ett fel",
+		"job_failed_hint": "Dialogen kunde inte skapas. Försök igen.",
+		"audio_pending": "Ljudet för den här repliken är inte tillgängligt ännu.",
+		"repair_attempts": "LLM-reparationer",
+		"coverage_flagged": "En del av ordförrådet saknas i dialogen. Använda ord:",
+		"coverage_badge": "Ord saknas",
+		"translation": "Översättning",
+		"show_translations": "Visa alla översättningar",
+		"hide_translations": "Dölj alla översättningar",
+		"estimated_level": "Uppskattad nivå",
+		"level_mismatch": "skiljer sig från den begärda nivån",
+		"other_levels": "Andra nivåer av den här dialogen",
+		"rewrite_at_level": "Skriv om på nivå",
+		"rewrite": "Skriv om",
+		"continue_dialog": "Fortsätt",
+		"continue_turns": "Nya repliker",
+		"roleplay": "Rollspel",
+		"roleplay_intro": "Spela en av talarna själv och prata med en partner som svarar på din nivå.",
+		"start_roleplay": "Starta rollspel",
+		"scenario": "Scenario",
+		"your_speaker": "Din roll",
+		"partner_speaker": "Partnerns roll",
+		"partner_gender": "Partnerns röst",
+		"gender_female": "Kvinna",
+		"gender_male": "Man",
+		"your_message": "Ditt meddelande",
+		"send": "Skicka",
+		"correction": "Bättre:",
+		"convert_to_dialog": "Spara som dialog",
+		"roleplay_converted": "Detta rollspel sparades som en dialog.",
+		"recent_roleplays": "Senaste rollspel",
+		"no_roleplays": "Inga rollspel ännu.",
+		"edit": "Redigera",
+		"title": "Titel",
+		"speakers": "Talare",
+		"turns": "Repliker",
+		"save": "Spara",
+		"cancel": "Avbryt",
+		"edit_audio_hint": "Endast repliker vars text ändras får nytt ljud.",
+		"revisions": "Tidigare versioner",
+		"revision": "Version",
+		"replaced": "Ersatt",
+		"restore": "Återställ den här versionen",
+		"regenerate_text": "Generera om texten",
+		"resynthesize_audio": "Skapa om ljudet",
+		"admin": "Administration",
+		"repair_audio": "Reparera allt platshållarljud",
+		"repair_audio_intro": "Skapar om ljudet för alla repliker i alla dialoger där ljudet saknas eller är en platshållare.",
+		"turns_synthesized": "Syntetiserade repliker",
+		"turns_repaired": "Reparerade repliker",
+		"download_selected_anki": "Ladda ner Anki-kortlek",
+		"anki_sentences": "med meningskort",
+		"review": "Repetition",
+		"start_review": "Börja repetera",
+		"today": "Idag",
+		"review_due_now": "Att repetera nu",
+		"show_answer": "Visa svar",
+		"grade_again": "Igen",
+		"grade_hard": "Svår",
+		"grade_good": "Bra",
+		"grade_easy": "Lätt",
+		"review_nothing_due": "Inget att repetera just nu. Kom tillbaka senare eller skapa en ny dialog.",
+		"cloze_exercise": "Fyll i luckorna",
+		"cloze_intro": "Skriv de saknade orden. Stora bokstäver och saknade accenter räknas inte som fel, och små stavfel godtas.",
+		"cloze_no_blanks": "Den här dialogen har inga ord att öva på ännu.",
+		"score": "Poäng",
+		"cloze_legend": "Vågiga understrykningar markerar svar som godtogs trots en saknad accent eller ett stavfel.",
+		"try_again": "Försök igen",
+		"check_answers": "Rätta svaren",
+		"your_attempts": "Dina försök",
+		"dialog_tab": "Dialog",
+		"quiz": "Quiz",
+		"quiz_intro": "Testa din förståelse med frågor om den här dialogen.",
+		"generate_quiz": "Skapa quiz",
+		"new_quiz": "Nya frågor",
+		"true_or_false": "Sant eller falskt?",
+		"quiz_true": "Sant",
+		"quiz_false": "Falskt",
+		"dictation": "Diktamen",
+		"dictation_intro": "Lyssna på varje replik och skriv det du hör. Texten visas när du rättar ditt svar.",
+		"dictation_no_audio": "Den här dialogen har inget ljud än.",
+		"dictation_placeholder": "Skriv det du hör…",
+		"hardest_turns": "Svåraste replikerna",
+		"dictation_missing": "Saknat ord",
+		"dictation_extra": "Extra ord",
+		"dictation_misspelled": "Felstavat",
+		"dictation_accent": "Endast diakritiska tecken",
+		"repair_running": "Reparation pågår…",
+		"repair_stopped": "Reparationen avbröts:",
+		"dialogs_failed": "Misslyckade dialoger",
 	},
 	LangRU: {
 		"app_name":           "LevelTalk",
@@ -196,6 +481,101 @@ var translations = Translations{
 		"tagline":             "Заставьте свой словарный запас говорить — на любом языке, на любом уровне.",
 		"delete":              "Удалить",
 		"confirm_delete":       "Вы уверены, что хотите удалить этот диалог? Это действие нельзя отменить.",
+		"job_queued": "В очереди",
+		"job_generating_text": "Пишем диалог…",
+		"job_synthesizing": "Записываем аудио…",
+		"job_done": "Диалог готов",
+		"job_failed": "Не удалось создать",
+		"job_retrying": "Повторная попытка после ошибки",
+		"job_failed_hint": "Не удалось создать диалог. Попробуйте ещё раз.",
+		"audio_pending": "Аудио для этой реплики пока недоступно.",
+		"repair_attempts": "Исправлений LLM",
+		"coverage_flagged": "В диалоге использована не вся лексика. Использовано слов:",
+		"coverage_badge": "Не вся лексика",
+		"translation": "Перевод",
+		"show_translations": "Показать все переводы",
+		"hide_translations": "Скрыть все переводы",
+		"estimated_level": "Оценённый уровень",
+		"level_mismatch": "не совпадает с запрошенным",
+		"other_levels": "Другие уровни этого диалога",
+		"rewrite_at_level": "Переписать на уровне",
+		"rewrite": "Переписать",
+		"continue_dialog": "Продолжить",
+		"continue_turns": "Новых реплик",
+		"roleplay": "Ролевая игра",
+		"roleplay_intro": "Сыграйте одного из собеседников сами и поговорите с партнёром, который отвечает на вашем уровне.",
+		"start_roleplay": "Начать ролевую игру",
+		"scenario": "Сценарий",
+		"your_speaker": "Ваша роль",
+		"partner_speaker": "Роль партнёра",
+		"partner_gender": "Голос партнёра",
+		"gender_female": "Женский",
+		"gender_male": "Мужской",
+		"your_message": "Ваше сообщение",
+		"send": "Отправить",
+		"correction": "Лучше:",
+		"convert_to_dialog": "Сохранить как диалог",
+		"roleplay_converted": "Эта ролевая игра сохранена как диалог.",
+		"recent_roleplays": "Недавние ролевые игры",
+		"no_roleplays": "Ролевых игр пока нет.",
+		"edit": "Редактировать",
+		"title": "Название",
+		"speakers": "Собеседники",
+		"turns": "Реплики",
+		"save": "Сохранить",
+		"cancel": "Отмена",
+		"edit_audio_hint": "Новое аудио создаётся только для изменённых реплик.",
+		"revisions": "Предыдущие версии",
+		"revision": "Версия",
+		"replaced": "Заменена",
+		"restore": "Восстановить эту версию",
+		"regenerate_text": "Переписать реплику",
+		"resynthesize_audio": "Заново озвучить",
+		"admin": "Администрирование",
+		"repair_audio": "Исправить все аудио-заглушки",
+		"repair_audio_intro": "Заново озвучивает во всех диалогах реплики без аудио или с заглушкой.",
+		"turns_synthesized": "Озвучено реплик",
+		"turns_repaired": "Исправлено реплик",
+		"download_selected_anki": "Скачать колоду Anki",
+		"anki_sentences": "с карточками предложений",
+		"review": "Повторение",
+		"start_review": "Начать повторение",
+		"today": "Сегодня",
+		"review_due_now": "К повторению сейчас",
+		"show_answer": "Показать ответ",
+		"grade_again": "Снова",
+		"grade_hard": "Трудно",
+		"grade_good": "Хорошо",
+		"grade_easy": "Легко",
+		"review_nothing_due": "Сейчас повторять нечего. Вернитесь позже или создайте новый диалог.",
+		"cloze_exercise": "Заполните пропуски",
+		"cloze_intro": "Впишите пропущенные слова. Регистр и пропущенные диакритические знаки не считаются ошибкой, небольшие опечатки допускаются.",
+		"cloze_no_blanks": "В этом диалоге пока нет слов для упражнения.",
+		"score": "Результат",
+		"cloze_legend": "Волнистым подчёркиванием отмечены ответы, засчитанные несмотря на пропущенный диакритический знак или опечатку.",
+		"try_again": "Попробовать снова",
+		"check_answers": "Проверить ответы",
+		"your_attempts": "Ваши попытки",
+		"dialog_tab": "Диалог",
+		"quiz": "Тест",
+		"quiz_intro": "Проверьте, как вы поняли диалог, ответив на вопросы.",
+		"generate_quiz": "Создать тест",
+		"new_quiz": "Новые вопросы",
+		"true_or_false": "Верно или нет?",
+		"quiz_true": "Верно",
+		"quiz_false": "Неверно",
+		"dictation": "Диктант",
+		"dictation_intro": "Прослушайте каждую реплику и запишите, что услышали. Текст появится после проверки.",
+		"dictation_no_audio": "У этого диалога пока нет аудио.",
+		"dictation_placeholder": "Напишите, что слышите…",
+		"hardest_turns": "Самые трудные реплики",
+		"dictation_missing": "Пропущенное слово",
+		"dictation_extra": "Лишнее слово",
+		"dictation_misspelled": "Ошибка в написании",
+		"dictation_accent": "Только диакритика",
+		"repair_running": "Идёт исправление…",
+		"repair_stopped": "Исправление прервано:",
+		"dialogs_failed": "Диалогов с ошибкой",
 	},
 	LangES: {
 		"app_name":           "LevelTalk",
@@ -230,6 +610,101 @@ var translations = Translations{
 		"tagline":             "Haz que tu vocabulario hable — en cualquier idioma, en cualquier nivel.",
 		"delete":              "Eliminar",
 		"confirm_delete":       "¿Estás seguro de que quieres eliminar este diálogo? Esta acción no se puede deshacer.",
+		"job_queued": "En cola",
+		"job_generating_text": "Escribiendo el diálogo…",
+		"job_synthesizing": "Grabando audio…",
+		"job_done": "Diálogo listo",
+		"job_failed": "Error al generar",
+		"job_retrying": "Reintentando tras un error",
+		"job_failed_hint": "No pudimos generar este diálogo. Inténtalo de nuevo.",
+		"audio_pending": "El audio de esta intervención aún no está disponible.",
+		"repair_attempts": "Reparaciones del LLM",
+		"coverage_flagged": "Falta parte del vocabulario en este diálogo. Palabras usadas:",
+		"coverage_badge": "Falta vocabulario",
+		"translation": "Traducción",
+		"show_translations": "Mostrar todas las traducciones",
+		"hide_translations": "Ocultar todas las traducciones",
+		"estimated_level": "Nivel estimado",
+		"level_mismatch": "no coincide con el nivel solicitado",
+		"other_levels": "Otros niveles de este diálogo",
+		"rewrite_at_level": "Reescribir en el nivel",
+		"rewrite": "Reescribir",
+		"continue_dialog": "Continuar",
+		"continue_turns": "Nuevas intervenciones",
+		"roleplay": "Juego de rol",
+		"roleplay_intro": "Interpreta tú a uno de los hablantes y conversa con un compañero que responde a tu nivel.",
+		"start_roleplay": "Empezar juego de rol",
+		"scenario": "Escenario",
+		"your_speaker": "Tu papel",
+		"partner_speaker": "Papel del compañero",
+		"partner_gender": "Voz del compañero",
+		"gender_female": "Femenina",
+		"gender_male": "Masculina",
+		"your_message": "Tu mensaje",
+		"send": "Enviar",
+		"correction": "Mejor:",
+		"convert_to_dialog": "Guardar como diálogo",
+		"roleplay_converted": "Este juego de rol se guardó como diálogo.",
+		"recent_roleplays": "Juegos de rol recientes",
+		"no_roleplays": "Todavía no hay juegos de rol.",
+		"edit": "Editar",
+		"title": "Título",
+		"speakers": "Hablantes",
+		"turns": "Turnos",
+		"save": "Guardar",
+		"cancel": "Cancelar",
+		"edit_audio_hint": "Solo los turnos cuyo texto cambia reciben audio nuevo.",
+		"revisions": "Versiones anteriores",
+		"revision": "Versión",
+		"replaced": "Reemplazada",
+		"restore": "Restaurar esta versión",
+		"regenerate_text": "Regenerar texto",
+		"resynthesize_audio": "Volver a sintetizar el audio",
+		"admin": "Administración",
+		"repair_audio": "Reparar todo el audio provisional",
+		"repair_audio_intro": "Vuelve a sintetizar en todos los diálogos los turnos sin audio o con audio provisional.",
+		"turns_synthesized": "Turnos sintetizados",
+		"turns_repaired": "Turnos reparados",
+		"download_selected_anki": "Descargar mazo de Anki",
+		"anki_sentences": "con tarjetas de frases",
+		"review": "Repaso",
+		"start_review": "Empezar repaso",
+		"today": "Hoy",
+		"review_due_now": "Pendientes ahora",
+		"show_answer": "Mostrar respuesta",
+		"grade_again": "Otra vez",
+		"grade_hard": "Difícil",
+		"grade_good": "Bien",
+		"grade_easy": "Fácil",
+		"review_nothing_due": "No hay nada pendiente. Vuelve más tarde o genera un diálogo nuevo.",
+		"cloze_exercise": "Completa los huecos",
+		"cloze_intro": "Escribe las palabras que faltan. Las mayúsculas y las tildes que falten no cuentan como error, y se aceptan pequeñas erratas.",
+		"cloze_no_blanks": "Este diálogo todavía no tiene palabras para practicar.",
+		"score": "Puntuación",
+		"cloze_legend": "El subrayado ondulado marca respuestas aceptadas a pesar de una tilde que falta o una errata.",
+		"try_again": "Intentar de nuevo",
+		"check_answers": "Comprobar respuestas",
+		"your_attempts": "Tus intentos",
+		"dialog_tab": "Diálogo",
+		"quiz": "Cuestionario",
+		"quiz_intro": "Comprueba tu comprensión con preguntas sobre este diálogo.",
+		"generate_quiz": "Generar cuestionario",
+		"new_quiz": "Nuevas preguntas",
+		"true_or_false": "¿Verdadero o falso?",
+		"quiz_true": "Verdadero",
+		"quiz_false": "Falso",
+		"dictation": "Dictado",
+		"dictation_intro": "Escucha cada intervención y escribe lo que oyes. El texto aparece al comprobar tu respuesta.",
+		"dictation_no_audio": "Este diálogo aún no tiene audio.",
+		"dictation_placeholder": "Escribe lo que oyes…",
+		"hardest_turns": "Intervenciones más difíciles",
+		"dictation_missing": "Palabra que falta",
+		"dictation_extra": "Palabra de más",
+		"dictation_misspelled": "Mal escrita",
+		"dictation_accent": "Solo tildes",
+		"repair_running": "Reparación en curso…",
+		"repair_stopped": "La reparación se detuvo antes de tiempo:",
+		"dialogs_failed": "Diálogos con error",
 	},
 	LangJA: {
 		"app_name":           "LevelTalk",
@@ -264,6 +739,101 @@ var translations = Translations{
 		"tagline":             "語彙に声を与える — あらゆる言語で、あらゆるレベルで。",
 		"delete":              "削除",
 		"confirm_delete":       "この対話を削除してもよろしいですか？この操作は元に戻せません。",
+		"job_queued": "待機中",
+		"job_generating_text": "対話を作成中…",
+		"job_synthesizing": "音声を録音中…",
+		"job_done": "対話の準備ができました",
+		"job_failed": "生成に失敗しました",
+		"job_retrying": "エラー後に再試行中",
+		"job_failed_hint": "この対話を生成できませんでした。もう一度お試しください。",
+		"audio_pending": "この発話の音声はまだ利用できません。",
+		"repair_attempts": "LLM修正回数",
+		"coverage_flagged": "この会話には使われていない語彙があります。使用率:",
+		"coverage_badge": "語彙不足",
+		"translation": "翻訳",
+		"show_translations": "すべての翻訳を表示",
+		"hide_translations": "すべての翻訳を隠す",
+		"estimated_level": "推定レベル",
+		"level_mismatch": "指定レベルと異なります",
+		"other_levels": "この対話の他のレベル",
+		"rewrite_at_level": "レベルを変えて書き直す",
+		"rewrite": "書き直す",
+		"continue_dialog": "続ける",
+		"continue_turns": "追加する発話数",
+		"roleplay": "ロールプレイ",
+		"roleplay_intro": "自分で話者の一人を演じ、あなたのレベルで答える相手と会話しましょう。",
+		"start_roleplay": "ロールプレイを始める",
+		"scenario": "シナリオ",
+		"your_speaker": "あなたの役",
+		"partner_speaker": "相手の役",
+		"partner_gender": "相手の声",
+		"gender_female": "女性",
+		"gender_male": "男性",
+		"your_message": "あなたのメッセージ",
+		"send": "送信",
+		"correction": "より自然な表現:",
+		"convert_to_dialog": "対話として保存",
+		"roleplay_converted": "このロールプレイは対話として保存されました。",
+		"recent_roleplays": "最近のロールプレイ",
+		"no_roleplays": "まだロールプレイはありません。",
+		"edit": "編集",
+		"title": "タイトル",
+		"speakers": "話者",
+		"turns": "発話",
+		"save": "保存",
+		"cancel": "キャンセル",
+		"edit_audio_hint": "テキストを変更した発話だけ音声が作り直されます。",
+		"revisions": "以前のバージョン",
+		"revision": "バージョン",
+		"replaced": "置き換え日時",
+		"restore": "このバージョンに戻す",
+		"regenerate_text": "テキストを再生成",
+		"resynthesize_audio": "音声を再合成",
+		"admin": "管理",
+		"repair_audio": "仮の音声をすべて修復",
+		"repair_audio_intro": "すべてのダイアログで、音声がない、または仮の音声の発話を再合成します。",
+		"turns_synthesized": "合成した発話",
+		"turns_repaired": "修復した発話",
+		"download_selected_anki": "Ankiデッキをダウンロード",
+		"anki_sentences": "文カードを含める",
+		"review": "復習",
+		"start_review": "復習を始める",
+		"today": "今日",
+		"review_due_now": "今の復習",
+		"show_answer": "答えを表示",
+		"grade_again": "もう一度",
+		"grade_hard": "難しい",
+		"grade_good": "普通",
+		"grade_easy": "簡単",
+		"review_nothing_due": "今復習するものはありません。後で戻るか、新しい会話を作成してください。",
+		"cloze_exercise": "穴埋め練習",
+		"cloze_intro": "抜けている単語を入力してください。大文字・小文字やアクセント記号の抜けは誤りになりません。小さな打ち間違いも正解として扱われます。",
+		"cloze_no_blanks": "この会話にはまだ練習できる単語がありません。",
+		"score": "スコア",
+		"cloze_legend": "波線はアクセント記号の抜けや打ち間違いがあっても正解とした答えを示します。",
+		"try_again": "もう一度",
+		"check_answers": "答え合わせ",
+		"your_attempts": "これまでの結果",
+		"dialog_tab": "会話",
+		"quiz": "クイズ",
+		"quiz_intro": "この会話についての質問で理解度を確認しましょう。",
+		"generate_quiz": "クイズを作成",
+		"new_quiz": "新しい質問",
+		"true_or_false": "正しい？間違い？",
+		"quiz_true": "正しい",
+		"quiz_false": "間違い",
+		"dictation": "ディクテーション",
+		"dictation_intro": "各セリフを聞いて、聞こえたとおりに入力してください。答え合わせをすると本文が表示されます。",
+		"dictation_no_audio": "この会話にはまだ音声がありません。",
+		"dictation_placeholder": "聞こえたとおりに入力…",
+		"hardest_turns": "特に難しいセリフ",
+		"dictation_missing": "抜けている単語",
+		"dictation_extra": "余分な単語",
+		"dictation_misspelled": "つづりの誤り",
+		"dictation_accent": "アクセント記号のみ",
+		"repair_running": "修復中…",
+		"repair_stopped": "修復が途中で停止しました:",
+		"dialogs_failed": "失敗したダイアログ",
 	},
 	LangDE: {
 		"app_name":           "LevelTalk",
@@ -298,6 +868,101 @@ var translations = Translations{
 		"tagline":             "Lassen Sie Ihren Wortschatz sprechen — in jeder Sprache, auf jedem Niveau.",
 		"delete":              "Löschen",
 		"confirm_delete":       "Sind Sie sicher, dass Sie diesen Dialog löschen möchten? Diese Aktion kann nicht rückgängig gemacht werden.",
+		"job_queued": "In der Warteschlange",
+		"job_generating_text": "Dialog wird geschrieben…",
+		"job_synthesizing": "Audio wird aufgenommen…",
+		"job_done": "Dialog fertig",
+		"job_failed": "Generierung fehlgeschlagen",
+		"job_retrying": "Neuer Versuch nach einem Fehler",
+		"job_failed_hint": "Der Dialog konnte nicht erstellt werden. Bitte erneut versuchen.",
+		"audio_pending": "Das Audio für diesen Redebeitrag ist noch nicht verfügbar.",
+		"repair_attempts": "LLM-Korrekturen",
+		"coverage_flagged": "Im Dialog fehlt ein Teil des Wortschatzes. Verwendete Wörter:",
+		"coverage_badge": "Wortschatz fehlt",
+		"translation": "Übersetzung",
+		"show_translations": "Alle Übersetzungen anzeigen",
+		"hide_translations": "Alle Übersetzungen ausblenden",
+		"estimated_level": "Geschätztes Niveau",
+		"level_mismatch": "weicht vom gewünschten Niveau ab",
+		"other_levels": "Andere Niveaus dieses Dialogs",
+		"rewrite_at_level": "Neu schreiben auf Niveau",
+		"rewrite": "Neu schreiben",
+		"continue_dialog": "Fortsetzen",
+		"continue_turns": "Neue Redebeiträge",
+		"roleplay": "Rollenspiel",
+		"roleplay_intro": "Spielen Sie selbst eine der beiden Personen und sprechen Sie mit einem Partner, der auf Ihrem Niveau antwortet.",
+		"start_roleplay": "Rollenspiel starten",
+		"scenario": "Szenario",
+		"your_speaker": "Ihre Rolle",
+		"partner_speaker": "Rolle des Partners",
+		"partner_gender": "Stimme des Partners",
+		"gender_female": "Weiblich",
+		"gender_male": "Männlich",
+		"your_message": "Ihre Nachricht",
+		"send": "Senden",
+		"correction": "Besser:",
+		"convert_to_dialog": "Als Dialog speichern",
+		"roleplay_converted": "Dieses Rollenspiel wurde als Dialog gespeichert.",
+		"recent_roleplays": "Letzte Rollenspiele",
+		"no_roleplays": "Noch keine Rollenspiele.",
+		"edit": "Bearbeiten",
+		"title": "Titel",
+		"speakers": "Sprecher",
+		"turns": "Redebeiträge",
+		"save": "Speichern",
+		"cancel": "Abbrechen",
+		"edit_audio_hint": "Nur Redebeiträge mit geändertem Text erhalten neues Audio.",
+		"revisions": "Frühere Versionen",
+		"revision": "Version",
+		"replaced": "Ersetzt",
+		"restore": "Diese Version wiederherstellen",
+		"regenerate_text": "Text neu erzeugen",
+		"resynthesize_audio": "Audio neu erzeugen",
+		"admin": "Verwaltung",
+		"repair_audio": "Alle Platzhalter-Audios reparieren",
+		"repair_audio_intro": "Erzeugt in allen Dialogen das Audio für Redebeiträge neu, deren Audio fehlt oder ein Platzhalter ist.",
+		"turns_synthesized": "Erzeugte Redebeiträge",
+		"turns_repaired": "Reparierte Redebeiträge",
+		"download_selected_anki": "Anki-Stapel herunterladen",
+		"anki_sentences": "mit Satzkarten",
+		"review": "Wiederholung",
+		"start_review": "Wiederholung starten",
+		"today": "Heute",
+		"review_due_now": "Jetzt fällig",
+		"show_answer": "Antwort zeigen",
+		"grade_again": "Nochmal",
+		"grade_hard": "Schwer",
+		"grade_good": "Gut",
+		"grade_easy": "Leicht",
+		"review_nothing_due": "Nichts fällig. Komm später wieder oder erstelle einen neuen Dialog.",
+		"cloze_exercise": "Lückentext",
+		"cloze_intro": "Tippe die fehlenden Wörter ein. Groß- und Kleinschreibung sowie fehlende Akzente zählen nicht als Fehler, kleine Tippfehler werden akzeptiert.",
+		"cloze_no_blanks": "Dieser Dialog hat noch keine Wörter zum Üben.",
+		"score": "Punktzahl",
+		"cloze_legend": "Wellenlinien markieren Antworten, die trotz fehlendem Akzent oder Tippfehler akzeptiert wurden.",
+		"try_again": "Nochmal versuchen",
+		"check_answers": "Antworten prüfen",
+		"your_attempts": "Deine Versuche",
+		"dialog_tab": "Dialog",
+		"quiz": "Quiz",
+		"quiz_intro": "Prüfe dein Verständnis mit Fragen zu diesem Dialog.",
+		"generate_quiz": "Quiz erstellen",
+		"new_quiz": "Neue Fragen",
+		"true_or_false": "Richtig oder falsch?",
+		"quiz_true": "Richtig",
+		"quiz_false": "Falsch",
+		"dictation": "Diktat",
+		"dictation_intro": "Hör dir jeden Redebeitrag an und schreib, was du hörst. Der Text erscheint, sobald du deine Antwort prüfst.",
+		"dictation_no_audio": "Dieser Dialog hat noch kein Audio.",
+		"dictation_placeholder": "Schreib, was du hörst…",
+		"hardest_turns": "Schwierigste Redebeiträge",
+		"dictation_missing": "Fehlendes Wort",
+		"dictation_extra": "Überflüssiges Wort",
+		"dictation_misspelled": "Falsch geschrieben",
+		"dictation_accent": "Nur diakritische Zeichen",
+		"repair_running": "Reparatur läuft…",
+		"repair_stopped": "Die Reparatur wurde vorzeitig beendet:",
+		"dialogs_failed": "Fehlgeschlagene Dialoge",
 	},
 }
 
@@ -0,0 +1,221 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"leveltalk/internal/dialogs"
+)
+
+const (
+	defaultAnthropicEndpoint = "https://api.anthropic.com/v1/messages"
+	defaultAnthropicVersion  = "2023-06-01"
+
+	// dialogToolName is the tool the model is forced to call; its input is
+	// the dialog in the dialogJSON shape.
+	dialogToolName = "emit_dialog"
+)
+
+// AnthropicOptions allows overriding HTTP behavior.
+type AnthropicOptions struct {
+	BaseURL     string // Full messages endpoint
+	HTTPClient  *http.Client
+	Temperature float64
+	MaxTokens   int
+	Version     string // anthropic-version header
+	MaxRepairs  int    // Repair round-trips for invalid dialogs; see OpenAIOptions
+}
+
+// AnthropicClient implements LLMClient against Anthropic's Messages API. The
+// model is forced to answer through a tool whose input schema is the dialog
+// JSON, so the reply is structured without relying on prose instructions.
+type AnthropicClient struct {
+	logger      *slog.Logger
+	apiKey      string
+	model       string
+	endpoint    string
+	version     string
+	httpClient  *http.Client
+	temperature float64
+	maxTokens   int
+	maxRepairs  int
+}
+
+// NewAnthropicClient constructs a new AnthropicClient.
+func NewAnthropicClient(logger *slog.Logger, apiKey, model string, opts *AnthropicOptions) *AnthropicClient {
+	if opts == nil {
+		opts = &AnthropicOptions{}
+	}
+
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 45 * time.Second,
+		}
+	}
+
+	endpoint := opts.BaseURL
+	if endpoint == "" {
+		endpoint = defaultAnthropicEndpoint
+	}
+
+	version := opts.Version
+	if version == "" {
+		version = defaultAnthropicVersion
+	}
+
+	temperature := opts.Temperature
+	if temperature == 0 {
+		temperature = defaultTemperature
+	}
+
+	maxTokens := opts.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultMaxTokens
+	}
+
+	maxRepairs := opts.MaxRepairs
+	if maxRepairs == 0 {
+		maxRepairs = defaultMaxRepairs
+	}
+
+	return &AnthropicClient{
+		logger:      logger,
+		apiKey:      apiKey,
+		model:       model,
+		endpoint:    endpoint,
+		version:     version,
+		httpClient:  httpClient,
+		temperature: temperature,
+		maxTokens:   maxTokens,
+		maxRepairs:  max(maxRepairs, 0),
+	}
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type messagesRequest struct {
+	Model       string               `json:"model"`
+	System      string               `json:"system,omitempty"`
+	Messages    []chatMessage        `json:"messages"`
+	MaxTokens   int                  `json:"max_tokens"`
+	Temperature float64              `json:"temperature,omitempty"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type messagesResponse struct {
+	Content []struct {
+		Type  string          `json:"type"`
+		Text  string          `json:"text,omitempty"`
+		Name  string          `json:"name,omitempty"`
+		Input json.RawMessage `json:"input,omitempty"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	apiError
+}
+
+// GenerateDialog sends the prompt to Anthropic and parses the tool input into
+// a Dialog, asking for repairs while it violates the dialog contract.
+func (c *AnthropicClient) GenerateDialog(ctx context.Context, params dialogs.GenerateDialogParams) (dialogs.Dialog, error) {
+	messages := dialogMessages(params)
+	content, err := c.complete(ctx, dialogSchemaFor(params), messages)
+	if err != nil {
+		return dialogs.Dialog{}, err
+	}
+	return generateWithRepairs(ctx, c.logger, "anthropic", c.maxRepairs, params, messages, content, c.complete)
+}
+
+// complete sends the conversation and returns the dialog JSON the model produced.
+func (c *AnthropicClient) complete(ctx context.Context, schema map[string]any, messages []chatMessage) (string, error) {
+	return c.completeWith(ctx, systemPrompt, anthropicTool{
+		Name:        dialogToolName,
+		Description: "Return the generated dialog.",
+		InputSchema: schema,
+	}, messages)
+}
+
+// completeWith sends the conversation under the given system prompt, forces
+// the model to call tool and returns the tool input it produced.
+func (c *AnthropicClient) completeWith(ctx context.Context, system string, tool anthropicTool, messages []chatMessage) (string, error) {
+	reqPayload := messagesRequest{
+		Model:       c.model,
+		System:      system,
+		MaxTokens:   c.maxTokens,
+		Temperature: c.temperature,
+		Messages:    messages,
+		Tools:       []anthropicTool{tool},
+		ToolChoice:  &anthropicToolChoice{Type: "tool", Name: tool.Name},
+	}
+
+	body, err := json.Marshal(reqPayload)
+	if err != nil {
+		return "", fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", c.version)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("call anthropic: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return "", fmt.Errorf("anthropic error: status=%d body=%s", resp.StatusCode, errorBody(respBody))
+	}
+
+	var message messagesResponse
+	if err := json.Unmarshal(respBody, &message); err != nil {
+		return "", fmt.Errorf("decode response: %w body=%s", err, truncate(respBody, 256))
+	}
+
+	if msg := message.message(); msg != "" {
+		return "", fmt.Errorf("anthropic error: %s", msg)
+	}
+
+	// Prefer the forced tool call; fall back to text blocks in case the
+	// model answered with plain JSON anyway.
+	var text strings.Builder
+	for _, block := range message.Content {
+		switch block.Type {
+		case "tool_use":
+			if block.Name == tool.Name && len(block.Input) > 0 {
+				return string(block.Input), nil
+			}
+		case "text":
+			text.WriteString(block.Text)
+		}
+	}
+	if text.Len() == 0 {
+		return "", fmt.Errorf("anthropic returned no %s call (stop_reason=%s)", tool.Name, message.StopReason)
+	}
+	return text.String(), nil
+}
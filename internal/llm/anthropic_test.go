@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/config"
+)
+
+func TestAnthropicClientGenerateDialogUsesTool(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/messages", r.URL.Path)
+		require.Equal(t, "key", r.Header.Get("x-api-key"))
+		require.Equal(t, defaultAnthropicVersion, r.Header.Get("anthropic-version"))
+
+		var req messagesRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "claude-test", req.Model)
+		require.Equal(t, systemPrompt, req.System)
+		require.Len(t, req.Tools, 1)
+		require.Equal(t, dialogToolName, req.ToolChoice.Name)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"type": "message",
+			"content": []map[string]any{{
+				"type":  "tool_use",
+				"name":  dialogToolName,
+				"input": json.RawMessage(validDialogContent),
+			}},
+			"stop_reason": "tool_use",
+		})
+	}))
+	defer srv.Close()
+
+	client, err := FromConfig(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Config{
+		LLMProvider: "anthropic",
+		LLMAPIKey:   "key",
+		LLMModel:    "claude-test",
+		LLMBaseURL:  srv.URL + "/v1",
+	})
+	require.NoError(t, err)
+
+	dlg, err := client.GenerateDialog(context.Background(), registryParams)
+	require.NoError(t, err)
+	require.Equal(t, "En casa", dlg.Title)
+	require.Equal(t, "female", dlg.Turns[0].Gender)
+	require.Equal(t, "casa", dlg.Translations["дом"])
+}
+
+func TestAnthropicClientReportsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+	}))
+	defer srv.Close()
+
+	client := NewAnthropicClient(slog.New(slog.NewTextHandler(io.Discard, nil)), "bad", "claude-test", &AnthropicOptions{BaseURL: srv.URL})
+	_, err := client.GenerateDialog(context.Background(), registryParams)
+	require.ErrorContains(t, err, "invalid x-api-key (authentication_error)")
+}
+
+func TestFromConfigFallsBackToSecondaryProvider(t *testing.T) {
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(completionHandler(t, func(r *http.Request, req map[string]any) {
+		require.Equal(t, "llama3.1:8b", req["model"])
+	}))
+	defer secondary.Close()
+
+	client, err := FromConfig(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Config{
+		LLMProvider: "anthropic",
+		LLMAPIKey:   "key",
+		LLMModel:    "claude-test",
+		LLMBaseURL:  primary.URL + "/v1",
+		LLMFallback: config.LLMFallbackConfig{
+			Provider: "ollama",
+			Model:    "llama3.1:8b",
+			BaseURL:  secondary.URL + "/v1",
+		},
+	})
+	require.NoError(t, err)
+
+	dlg, err := client.GenerateDialog(context.Background(), registryParams)
+	require.NoError(t, err)
+	require.Equal(t, "En casa", dlg.Title)
+}
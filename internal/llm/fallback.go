@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"leveltalk/internal/dialogs"
+)
+
+// FallbackClient tries its clients in order and returns the first dialog that
+// was generated without error.
+//
+// It deliberately does not implement dialogs.StreamingLLMClient: turns that a
+// failing primary had already streamed could not be taken back, so the job
+// card would show them next to the secondary's turns.
+type FallbackClient struct {
+	logger  *slog.Logger
+	names   []string
+	clients []dialogs.LLMClient
+}
+
+// NewFallbackClient chains clients; names label them in logs and errors.
+func NewFallbackClient(logger *slog.Logger, names []string, clients []dialogs.LLMClient) *FallbackClient {
+	return &FallbackClient{logger: logger, names: names, clients: clients}
+}
+
+// GenerateDialog asks each client in turn until one succeeds. A cancelled
+// context stops the chain instead of moving on to the next client.
+func (f *FallbackClient) GenerateDialog(ctx context.Context, params dialogs.GenerateDialogParams) (dialogs.Dialog, error) {
+	var errs []error
+	for i, client := range f.clients {
+		dlg, err := client.GenerateDialog(ctx, params)
+		if err == nil {
+			return dlg, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", f.names[i], err))
+		if ctx.Err() != nil {
+			break
+		}
+		if i < len(f.clients)-1 {
+			f.logger.Warn("LLM provider failed, trying next",
+				slog.String("provider", f.names[i]),
+				slog.String("next", f.names[i+1]),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return dialogs.Dialog{}, errors.Join(errs...)
+}
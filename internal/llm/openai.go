@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -17,19 +18,42 @@ import (
 const (
 	defaultOpenAIEndpoint = "https://api.openai.com/v1/chat/completions"
 	defaultTemperature    = 0.6
-	defaultMaxTokens      = 800
+	defaultMaxTokens      = 2000 // Room for 10 turns with translations and the vocabulary map
 )
 
 // OpenAIOptions allows overriding HTTP behavior.
 type OpenAIOptions struct {
-	BaseURL     string
+	BaseURL     string // Full chat completions endpoint
 	HTTPClient  *http.Client
 	Temperature float64
 	MaxTokens   int
+
+	// Name identifies the provider in logs and errors; defaults to "openai".
+	Name   string
+	Quirks Quirks
+
+	// MaxRepairs bounds the follow-up requests sent when the answer is not
+	// valid dialog JSON; zero uses the default and a negative value disables
+	// repairs.
+	MaxRepairs int
+}
+
+// Quirks describes where an OpenAI-compatible server deviates from OpenAI.
+type Quirks struct {
+	// JSONMode sends response_format {"type":"json_object"}. Many
+	// self-hosted servers reject or ignore the field.
+	JSONMode bool
+	// JSONSchema sends response_format {"type":"json_schema"} with the
+	// dialog schema. It takes precedence over JSONMode.
+	JSONSchema bool
+	// NoStreaming makes StreamDialog fall back to a single completion for
+	// servers whose streaming is missing or unreliable.
+	NoStreaming bool
 }
 
 // OpenAIClient implements LLMClient against OpenAI's Chat Completions API.
 type OpenAIClient struct {
+	name        string
 	logger      *slog.Logger
 	apiKey      string
 	model       string
@@ -37,6 +61,8 @@ type OpenAIClient struct {
 	httpClient  *http.Client
 	temperature float64
 	maxTokens   int
+	quirks      Quirks
+	maxRepairs  int
 }
 
 // NewOpenAIClient constructs a new OpenAIClient.
@@ -67,7 +93,18 @@ func NewOpenAIClient(logger *slog.Logger, apiKey, model string, opts *OpenAIOpti
 		maxTokens = defaultMaxTokens
 	}
 
+	name := opts.Name
+	if name == "" {
+		name = "openai"
+	}
+
+	maxRepairs := opts.MaxRepairs
+	if maxRepairs == 0 {
+		maxRepairs = defaultMaxRepairs
+	}
+
 	return &OpenAIClient{
+		name:        name,
 		logger:      logger,
 		apiKey:      apiKey,
 		model:       model,
@@ -75,19 +112,43 @@ func NewOpenAIClient(logger *slog.Logger, apiKey, model string, opts *OpenAIOpti
 		httpClient:  httpClient,
 		temperature: temperature,
 		maxTokens:   maxTokens,
+		quirks:      opts.Quirks,
+		maxRepairs:  max(maxRepairs, 0),
 	}
 }
 
+const systemPrompt = "You are an expert language tutor. Produce monolingual dialogs entirely in the target language in valid JSON. " +
+	"Both speakers must speak ONLY in the dialog language. " +
+	"IMPORTANT: You must FIRST translate all provided words/phrases from the input language into the target language, " +
+	"then use ONLY the translated versions in the dialog. Never include words from the input language in the dialog. " +
+	"Always respond ONLY with JSON matching this exact schema: {\"title\":\"descriptive_title\",\"turns\":[{\"speaker\":\"string\",\"gender\":\"female|male\",\"text\":\"string\",\"translation\":\"string\"}],\"translations\":{\"exact_input_word\":\"translated_word\"}}. " +
+	"The \"title\" field is REQUIRED and must be a concise, descriptive title (3-8 words) that expresses the main idea or topic of the dialog in the dialog language. " +
+	"The \"gender\" field is the speaker's gender, used to choose a matching voice; keep it the same for every turn of a speaker. " +
+	"The \"translation\" field renders the turn's text in the learner's input language as a study gloss; it is the only place where the input language may appear. " +
+	"The translations object is REQUIRED and must contain an entry for EVERY input word/phrase provided, using the EXACT same spelling and casing as provided. Do not add commentary."
+
 type chatMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
 }
 
 type completionRequest struct {
-	Model       string        `json:"model"`
-	Messages    []chatMessage `json:"messages"`
-	Temperature float64       `json:"temperature,omitempty"`
-	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Model          string          `json:"model"`
+	Messages       []chatMessage   `json:"messages"`
+	Temperature    float64         `json:"temperature,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	Stream         bool            `json:"stream,omitempty"`
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
 }
 
 type completionResponse struct {
@@ -96,110 +157,270 @@ type completionResponse struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
-	Error *struct {
-		Message string `json:"message"`
-		Type    string `json:"type"`
-	} `json:"error"`
+	apiError
+}
+
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	apiError
+}
+
+// apiError captures the error shapes of OpenAI-compatible servers:
+// {"error":{"message":..,"type":..}} from OpenAI and llama.cpp,
+// {"error":"..."} from Ollama, and {"object":"error","message":..} from vLLM.
+type apiError struct {
+	Error   json.RawMessage `json:"error,omitempty"`
+	Object  string          `json:"object,omitempty"`
+	Message string          `json:"message,omitempty"`
+}
+
+// message returns the error description, or "" when the payload is no error.
+func (e apiError) message() string {
+	if len(e.Error) > 0 && string(e.Error) != "null" {
+		var text string
+		if err := json.Unmarshal(e.Error, &text); err == nil {
+			return text
+		}
+		var obj struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		}
+		if err := json.Unmarshal(e.Error, &obj); err == nil && obj.Message != "" {
+			if obj.Type != "" {
+				return fmt.Sprintf("%s (%s)", obj.Message, obj.Type)
+			}
+			return obj.Message
+		}
+		return string(e.Error)
+	}
+	if e.Object == "error" {
+		return e.Message
+	}
+	return ""
+}
+
+// errorBody summarizes an HTTP error body, preferring the server's message.
+func errorBody(body []byte) string {
+	var e apiError
+	if err := json.Unmarshal(body, &e); err == nil {
+		if msg := e.message(); msg != "" {
+			return msg
+		}
+	}
+	return truncate(body, 512)
 }
 
 type dialogJSON struct {
 	Title        string            `json:"title"`
-	Turns        []struct {
-		Speaker string `json:"speaker"`
-		Text    string `json:"text"`
-	} `json:"turns"`
+	Turns        []turnJSON        `json:"turns"`
 	Translations map[string]string `json:"translations,omitempty"`
 }
 
-// GenerateDialog sends a prompt to OpenAI and parses the JSON payload into Dialogs.
+// GenerateDialog sends a prompt to OpenAI and parses the JSON payload into
+// Dialogs, asking for repairs while it violates the dialog contract.
 func (c *OpenAIClient) GenerateDialog(ctx context.Context, params dialogs.GenerateDialogParams) (dialogs.Dialog, error) {
-	reqPayload := completionRequest{
-		Model:       c.model,
-		Temperature: c.temperature,
-		MaxTokens:   c.maxTokens,
-		Messages: []chatMessage{
-			{
-				Role: "system",
-				Content: "You are an expert language tutor. Produce monolingual dialogs entirely in the target language in valid JSON. " +
-					"Both speakers must speak ONLY in the dialog language. " +
-					"IMPORTANT: You must FIRST translate all provided words/phrases from the input language into the target language, " +
-					"then use ONLY the translated versions in the dialog. Never include words from the input language in the dialog. " +
-					"Always respond ONLY with JSON matching this exact schema: {\"title\":\"descriptive_title\",\"turns\":[{\"speaker\":\"string\",\"text\":\"string\"}],\"translations\":{\"exact_input_word\":\"translated_word\"}}. " +
-					"The \"title\" field is REQUIRED and must be a concise, descriptive title (3-8 words) that expresses the main idea or topic of the dialog in the dialog language. " +
-					"The translations object is REQUIRED and must contain an entry for EVERY input word/phrase provided, using the EXACT same spelling and casing as provided. Do not add commentary.",
-			},
-			{
-				Role:    "user",
-				Content: buildUserPrompt(params),
-			},
-		},
-	}
-
-	body, err := json.Marshal(reqPayload)
+	messages := dialogMessages(params)
+	content, err := c.complete(ctx, dialogSchemaFor(params), messages)
 	if err != nil {
-		return dialogs.Dialog{}, fmt.Errorf("marshal request: %w", err)
+		return dialogs.Dialog{}, err
 	}
+	return generateWithRepairs(ctx, c.logger, c.name, c.maxRepairs, params, messages, content, c.complete)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+// complete sends a non-streamed dialog completion and returns the answer text.
+func (c *OpenAIClient) complete(ctx context.Context, schema map[string]any, messages []chatMessage) (string, error) {
+	return c.completeWith(ctx, systemPrompt, jsonSchema{Name: "dialog", Schema: schema}, messages)
+}
+
+// completeWith sends a non-streamed completion under the given system prompt
+// and answer schema and returns the answer text.
+func (c *OpenAIClient) completeWith(ctx context.Context, system string, schema jsonSchema, messages []chatMessage) (string, error) {
+	req, err := c.newCompletionRequest(ctx, system, schema, messages, false)
 	if err != nil {
-		return dialogs.Dialog{}, fmt.Errorf("build request: %w", err)
+		return "", err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return dialogs.Dialog{}, fmt.Errorf("call openai: %w", err)
+		return "", fmt.Errorf("call %s: %w", c.name, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return dialogs.Dialog{}, fmt.Errorf("read response: %w", err)
+		return "", fmt.Errorf("read response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
-		return dialogs.Dialog{}, fmt.Errorf("openai error: status=%d body=%s", resp.StatusCode, truncate(respBody, 512))
+		return "", fmt.Errorf("%s error: status=%d body=%s", c.name, resp.StatusCode, errorBody(respBody))
 	}
 
 	var completion completionResponse
 	if err := json.Unmarshal(respBody, &completion); err != nil {
-		return dialogs.Dialog{}, fmt.Errorf("decode response: %w body=%s", err, truncate(respBody, 256))
+		return "", fmt.Errorf("decode response: %w body=%s", err, truncate(respBody, 256))
 	}
 
-	if completion.Error != nil {
-		return dialogs.Dialog{}, fmt.Errorf("openai error: %s (%s)", completion.Error.Message, completion.Error.Type)
+	if msg := completion.message(); msg != "" {
+		return "", fmt.Errorf("%s error: %s", c.name, msg)
 	}
 
 	if len(completion.Choices) == 0 {
-		return dialogs.Dialog{}, fmt.Errorf("openai returned no choices")
+		return "", fmt.Errorf("%s returned no choices", c.name)
+	}
+
+	return completion.Choices[0].Message.Content, nil
+}
+
+// StreamDialog requests a streamed completion and reports each turn through
+// onTurn as soon as its JSON object is complete. The returned Dialog is parsed
+// from the full response exactly like GenerateDialog; repairs are requested
+// without streaming, so their turns are not reported through onTurn.
+func (c *OpenAIClient) StreamDialog(ctx context.Context, params dialogs.GenerateDialogParams, onTurn func(dialogs.DialogTurn)) (dialogs.Dialog, error) {
+	if c.quirks.NoStreaming {
+		dlg, err := c.GenerateDialog(ctx, params)
+		if err != nil {
+			return dialogs.Dialog{}, err
+		}
+		for _, turn := range dlg.Turns {
+			onTurn(turn)
+		}
+		return dlg, nil
+	}
+
+	messages := dialogMessages(params)
+	req, err := c.newCompletionRequest(ctx, systemPrompt, jsonSchema{Name: "dialog", Schema: dialogSchemaFor(params)}, messages, true)
+	if err != nil {
+		return dialogs.Dialog{}, err
 	}
+	req.Header.Set("Accept", "text/event-stream")
 
-	content := strings.TrimSpace(completion.Choices[0].Message.Content)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return dialogs.Dialog{}, fmt.Errorf("call %s: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return dialogs.Dialog{}, fmt.Errorf("%s error: status=%d body=%s", c.name, resp.StatusCode, errorBody(respBody))
+	}
+
+	parser := newTurnStreamParser()
+	position := 0
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return dialogs.Dialog{}, fmt.Errorf("decode stream chunk: %w chunk=%s", err, truncate([]byte(data), 256))
+		}
+		if msg := chunk.message(); msg != "" {
+			return dialogs.Dialog{}, fmt.Errorf("%s error: %s", c.name, msg)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		for _, turn := range parser.Write(chunk.Choices[0].Delta.Content) {
+			speaker := strings.TrimSpace(turn.Speaker)
+			text := strings.TrimSpace(turn.Text)
+			if speaker == "" || text == "" {
+				continue
+			}
+			onTurn(dialogs.DialogTurn{
+				Speaker:     speaker,
+				Gender:      normalizeGender(turn.Gender),
+				Text:        text,
+				Translation: strings.TrimSpace(turn.Translation),
+				Position:    position,
+			})
+			position++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return dialogs.Dialog{}, fmt.Errorf("read stream: %w", err)
+	}
+
+	return generateWithRepairs(ctx, c.logger, c.name, c.maxRepairs, params, messages, parser.String(), c.complete)
+}
+
+func (c *OpenAIClient) newCompletionRequest(ctx context.Context, system string, schema jsonSchema, messages []chatMessage, stream bool) (*http.Request, error) {
+	reqPayload := completionRequest{
+		Model:       c.model,
+		Temperature: c.temperature,
+		MaxTokens:   c.maxTokens,
+		Stream:      stream,
+		Messages:    append([]chatMessage{{Role: "system", Content: system}}, messages...),
+	}
+
+	switch {
+	case c.quirks.JSONSchema:
+		reqPayload.ResponseFormat = &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: &schema,
+		}
+	case c.quirks.JSONMode:
+		reqPayload.ResponseFormat = &responseFormat{Type: "json_object"}
+	}
+
+	body, err := json.Marshal(reqPayload)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	// Self-hosted servers often run without authentication.
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// parseDialog turns the model's JSON answer into a Dialog. provider names the
+// client in logs and errors.
+func parseDialog(logger *slog.Logger, provider, content string, params dialogs.GenerateDialogParams) (dialogs.Dialog, error) {
+	content = strings.TrimSpace(content)
 	content = stripCodeFence(content)
 
-	c.logger.Debug("parsing LLM response",
+	logger.Debug("parsing LLM response",
 		slog.Int("content_length", len(content)),
 		slog.String("content_preview", truncate([]byte(content), 200)),
 	)
 
 	var parsed dialogJSON
 	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
-		c.logger.Error("failed to parse LLM JSON response",
+		logger.Error("failed to parse LLM JSON response",
 			slog.String("error", err.Error()),
 			slog.String("content", truncate([]byte(content), 500)),
 		)
 		return dialogs.Dialog{}, fmt.Errorf("parse dialog json: %w content=%s", err, truncate([]byte(content), 256))
 	}
 
-	c.logger.Info("parsed LLM response",
+	logger.Info("parsed LLM response",
 		slog.Int("turns", len(parsed.Turns)),
 		slog.Int("translations", len(parsed.Translations)),
 		slog.Any("translation_keys", getMapKeys(parsed.Translations)),
 	)
 
 	if len(parsed.Turns) == 0 {
-		return dialogs.Dialog{}, fmt.Errorf("openai returned no dialog turns")
+		return dialogs.Dialog{}, fmt.Errorf("%s returned no dialog turns", provider)
 	}
 
 	turns := make([]dialogs.DialogTurn, 0, len(parsed.Turns))
@@ -210,14 +431,16 @@ func (c *OpenAIClient) GenerateDialog(ctx context.Context, params dialogs.Genera
 			continue
 		}
 		turns = append(turns, dialogs.DialogTurn{
-			Speaker:  speaker,
-			Text:     text,
-			Position: i,
+			Speaker:     speaker,
+			Gender:      normalizeGender(turn.Gender),
+			Text:        text,
+			Translation: strings.TrimSpace(turn.Translation),
+			Position:    i,
 		})
 	}
 
 	if len(turns) == 0 {
-		return dialogs.Dialog{}, fmt.Errorf("openai returned empty turns after validation")
+		return dialogs.Dialog{}, fmt.Errorf("%s returned empty turns after validation", provider)
 	}
 
 	title := strings.TrimSpace(parsed.Title)
@@ -246,7 +469,7 @@ func (c *OpenAIClient) GenerateDialog(ctx context.Context, params dialogs.Genera
 		// Try exact match first
 		if trans, ok := translations[trimmed]; ok && trans != "" {
 			normalizedTranslations[trimmed] = trans
-			c.logger.Debug("matched translation", slog.String("input", trimmed), slog.String("translation", trans))
+			logger.Debug("matched translation", slog.String("input", trimmed), slog.String("translation", trans))
 			continue
 		}
 		// Try case-insensitive match
@@ -254,7 +477,7 @@ func (c *OpenAIClient) GenerateDialog(ctx context.Context, params dialogs.Genera
 		for key, trans := range translations {
 			if strings.EqualFold(strings.TrimSpace(key), trimmed) && trans != "" {
 				normalizedTranslations[trimmed] = trans
-				c.logger.Debug("matched translation (case-insensitive)", slog.String("input", trimmed), slog.String("key", key), slog.String("translation", trans))
+				logger.Debug("matched translation (case-insensitive)", slog.String("input", trimmed), slog.String("key", key), slog.String("translation", trans))
 				found = true
 				break
 			}
@@ -262,11 +485,11 @@ func (c *OpenAIClient) GenerateDialog(ctx context.Context, params dialogs.Genera
 		if !found {
 			// If no translation found, leave empty (will show placeholder in UI)
 			normalizedTranslations[trimmed] = ""
-			c.logger.Warn("no translation found for input word", slog.String("input", trimmed), slog.Int("translations_count", len(translations)))
+			logger.Warn("no translation found for input word", slog.String("input", trimmed), slog.Int("translations_count", len(translations)))
 		}
 	}
 
-	c.logger.Info("normalized translations",
+	logger.Info("normalized translations",
 		slog.Int("input_words", len(params.InputWords)),
 		slog.Int("raw_translations", len(translations)),
 		slog.Int("normalized_translations", len(normalizedTranslations)),
@@ -307,7 +530,15 @@ func buildUserPrompt(params dialogs.GenerateDialogParams) string {
 	sb.WriteString(params.DialogLanguage)
 	sb.WriteString(" - no words from ")
 	sb.WriteString(params.InputLanguage)
-	sb.WriteString(" should appear. Provide between 6 and 10 turns. ")
+	sb.WriteString(" should appear. ")
+	if lo, hi := turnBounds(params); lo == hi {
+		sb.WriteString(fmt.Sprintf("Provide exactly %d turns. ", lo))
+	} else {
+		sb.WriteString(fmt.Sprintf("Provide between %d and %d turns. ", lo, hi))
+	}
+	sb.WriteString("Give every turn a \"translation\" of its text into ")
+	sb.WriteString(params.InputLanguage)
+	sb.WriteString(". ")
 	sb.WriteString("CRITICAL: You MUST include a \"translations\" object in your JSON response. ")
 	sb.WriteString("The translations object must map EACH input word/phrase (using the EXACT spelling: ")
 	sb.WriteString(strings.Join(params.InputWords, ", "))
@@ -324,6 +555,51 @@ func buildUserPrompt(params dialogs.GenerateDialogParams) string {
 		}
 	}
 	sb.WriteString("}}")
+
+	if src := params.Source; src != nil {
+		sb.WriteString("\n\nDo not invent a new scene: rewrite the following CEFR ")
+		sb.WriteString(src.CEFRLevel)
+		sb.WriteString(" dialog at CEFR ")
+		sb.WriteString(params.CEFRLevel)
+		sb.WriteString(" level. Keep the same storyline, the same speakers and the same vocabulary; ")
+		sb.WriteString("adapt only sentence length, grammar and word choice to the new level.\n")
+		for _, turn := range src.Turns {
+			sb.WriteString(turn.Speaker)
+			sb.WriteString(": ")
+			sb.WriteString(turn.Text)
+			sb.WriteString("\n")
+		}
+	}
+
+	if rewrite := params.Rewrite; rewrite != nil {
+		sb.WriteString("\n\nDo not start a new dialog: write a new version of the turn marked >>> in the following conversation. ")
+		sb.WriteString("It must be spoken by ")
+		sb.WriteString(rewrite.Speaker)
+		sb.WriteString(", fit the turns before and after it, and say something different from the current version. ")
+		sb.WriteString("Put only that one turn in \"turns\" and repeat the title and translations unchanged.\n")
+		for _, turn := range params.Prior {
+			if turn.Position == rewrite.Position {
+				sb.WriteString(">>> ")
+			}
+			sb.WriteString(turn.Speaker)
+			sb.WriteString(": ")
+			sb.WriteString(turn.Text)
+			sb.WriteString("\n")
+		}
+	} else if len(params.Prior) > 0 {
+		sb.WriteString("\n\nDo not start a new dialog: continue the following conversation where it stops, ")
+		sb.WriteString("with the same speakers and genders, topic and level. Put only the new turns in \"turns\" ")
+		sb.WriteString("and repeat the title and translations unchanged.\n")
+		for _, turn := range params.Prior {
+			sb.WriteString(turn.Speaker)
+			if turn.Gender != "" {
+				sb.WriteString(" (" + turn.Gender + ")")
+			}
+			sb.WriteString(": ")
+			sb.WriteString(turn.Text)
+			sb.WriteString("\n")
+		}
+	}
 	return sb.String()
 }
 
@@ -335,6 +611,18 @@ func getMapKeys(m map[string]string) []string {
 	return keys
 }
 
+// normalizeGender maps the model's gender hint onto "female", "male" or "".
+func normalizeGender(v string) string {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "female", "f", "woman", "girl":
+		return "female"
+	case "male", "m", "man", "boy":
+		return "male"
+	default:
+		return ""
+	}
+}
+
 func stripCodeFence(v string) string {
 	v = strings.TrimSpace(v)
 	if strings.HasPrefix(v, "```") {
@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestOpenAIClientStreamDialog(t *testing.T) {
+	content := strings.Replace(validDialogContent, `"gender":"male"`, `"gender":"Male"`, 1)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req completionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.True(t, req.Stream)
+		require.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Deltas are JSON strings, so fragments never split a rune.
+		for i := 0; i < len(content); {
+			end := min(i+11, len(content))
+			for end < len(content) && !utf8.RuneStart(content[end]) {
+				end++
+			}
+			chunk, _ := json.Marshal(map[string]any{
+				"choices": []map[string]any{{"delta": map[string]string{"content": content[i:end]}}},
+			})
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			i = end
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	client := NewOpenAIClient(logger, "key", "gpt-test", &OpenAIOptions{BaseURL: srv.URL})
+
+	var streamed []dialogs.DialogTurn
+	dlg, err := client.StreamDialog(context.Background(), dialogs.GenerateDialogParams{
+		InputLanguage:  "ru",
+		DialogLanguage: "es",
+		CEFRLevel:      "A1",
+		InputWords:     []string{"дом"},
+	}, func(turn dialogs.DialogTurn) {
+		streamed = append(streamed, turn)
+	})
+	require.NoError(t, err)
+
+	require.Len(t, streamed, 6)
+	require.Equal(t, "Ana", streamed[0].Speaker)
+	require.Equal(t, "Мой дом большой.", streamed[0].Translation)
+	require.Equal(t, 1, streamed[1].Position)
+	require.Equal(t, "male", streamed[1].Gender)
+	require.Equal(t, "En casa", dlg.Title)
+	require.Len(t, dlg.Turns, 6)
+	require.Zero(t, dlg.RepairAttempts)
+	require.Equal(t, "female", dlg.Turns[0].Gender)
+	require.Equal(t, "Мой дом большой.", dlg.Turns[0].Translation)
+	require.Empty(t, dlg.Turns[1].Translation)
+	require.Equal(t, "casa", dlg.Translations["дом"])
+}
+
+func TestBuildUserPromptRewritesSource(t *testing.T) {
+	params := dialogs.GenerateDialogParams{
+		InputLanguage:  "ru",
+		DialogLanguage: "es",
+		CEFRLevel:      "B2",
+		InputWords:     []string{"дом"},
+	}
+	require.NotContains(t, buildUserPrompt(params), "rewrite")
+
+	params.Source = &dialogs.Dialog{
+		CEFRLevel: "A2",
+		Turns:     []dialogs.DialogTurn{{Speaker: "Ana", Text: "Mi casa es grande."}},
+	}
+	prompt := buildUserPrompt(params)
+	require.Contains(t, prompt, "rewrite the following CEFR A2 dialog at CEFR B2 level")
+	require.Contains(t, prompt, "\nAna: Mi casa es grande.\n")
+}
@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"leveltalk/internal/dialogs"
+)
+
+// quizToolName is the tool Anthropic is forced to call for quizzes; its
+// input is a quizJSON.
+const quizToolName = "emit_quiz"
+
+const quizSystemPrompt = "You are an expert language tutor writing reading comprehension questions about a dialog. " +
+	"Write every question and option ONLY in the dialog language, at the requested CEFR level, so a learner at that level understands them. " +
+	"Mix multiple-choice questions with three or four options and true/false statements. " +
+	"Every question must be answerable from exactly one turn: give that turn's number in \"turn\" and copy the words of the turn that answer it, verbatim, into \"evidence\". " +
+	"For multiple-choice questions, \"answer\" is the zero-based index of the right option, which must use words of that turn or its speaker's name; leave \"correct\" false. " +
+	"For true/false questions, leave \"options\" empty, set \"answer\" to 0 and set \"correct\" to whether the statement is true. " +
+	"Always respond ONLY with JSON matching this exact schema: " +
+	"{\"questions\":[{\"type\":\"multiple_choice|true_false\",\"question\":\"string\",\"options\":[\"string\"],\"answer\":0,\"correct\":false,\"turn\":0,\"evidence\":\"string\"}]}."
+
+// quizSchema is the JSON Schema of quizJSON.
+var quizSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"questions"},
+	"properties": map[string]any{
+		"questions": map[string]any{
+			"type": "array",
+			"items": map[string]any{
+				"type":     "object",
+				"required": []string{"type", "question", "options", "answer", "correct", "turn", "evidence"},
+				"properties": map[string]any{
+					"type":     map[string]any{"type": "string", "enum": []string{string(dialogs.QuestionMultipleChoice), string(dialogs.QuestionTrueFalse)}},
+					"question": map[string]any{"type": "string"},
+					"options":  map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+					"answer":   map[string]any{"type": "integer"},
+					"correct":  map[string]any{"type": "boolean"},
+					"turn":     map[string]any{"type": "integer"},
+					"evidence": map[string]any{"type": "string"},
+				},
+			},
+		},
+	},
+}
+
+type quizJSON struct {
+	Questions []struct {
+		Type     string   `json:"type"`
+		Question string   `json:"question"`
+		Options  []string `json:"options"`
+		Answer   int      `json:"answer"`
+		Correct  bool     `json:"correct"`
+		Turn     int      `json:"turn"`
+		Evidence string   `json:"evidence"`
+	} `json:"questions"`
+}
+
+// GenerateQuiz asks OpenAI for comprehension questions about a dialog.
+func (c *OpenAIClient) GenerateQuiz(ctx context.Context, params dialogs.QuizParams) ([]dialogs.QuizQuestion, error) {
+	content, err := c.completeWith(ctx, quizSystemPrompt, jsonSchema{Name: "dialog_quiz", Schema: quizSchema}, quizMessages(params))
+	if err != nil {
+		return nil, err
+	}
+	return parseQuiz(c.name, content)
+}
+
+// GenerateQuiz asks Anthropic for comprehension questions about a dialog.
+func (c *AnthropicClient) GenerateQuiz(ctx context.Context, params dialogs.QuizParams) ([]dialogs.QuizQuestion, error) {
+	content, err := c.completeWith(ctx, quizSystemPrompt, anthropicTool{
+		Name:        quizToolName,
+		Description: "Return the comprehension questions about the dialog with their answer keys.",
+		InputSchema: quizSchema,
+	}, quizMessages(params))
+	if err != nil {
+		return nil, err
+	}
+	return parseQuiz("anthropic", content)
+}
+
+// GenerateQuiz asks each client that can write quizzes in turn until one
+// succeeds.
+func (f *FallbackClient) GenerateQuiz(ctx context.Context, params dialogs.QuizParams) ([]dialogs.QuizQuestion, error) {
+	var errs []error
+	for i, client := range f.clients {
+		writer, ok := client.(dialogs.QuizWriter)
+		if !ok {
+			continue
+		}
+		questions, err := writer.GenerateQuiz(ctx, params)
+		if err == nil {
+			return questions, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", f.names[i], err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return nil, dialogs.ErrQuizUnsupported
+	}
+	return nil, errors.Join(errs...)
+}
+
+// quizMessages lists the numbered turns of the dialog in a single prompt, so
+// the model can cite them by number.
+func quizMessages(params dialogs.QuizParams) []chatMessage {
+	var sb strings.Builder
+	sb.WriteString("Write ")
+	sb.WriteString(strconv.Itoa(params.Questions))
+	sb.WriteString(" comprehension questions in ")
+	sb.WriteString(params.DialogLanguage)
+	sb.WriteString(" at CEFR ")
+	sb.WriteString(params.CEFRLevel)
+	sb.WriteString(" level about the dialog below. The learner's native language is ")
+	sb.WriteString(params.InputLanguage)
+	sb.WriteString(".")
+	if params.Title != "" {
+		sb.WriteString("\n\nTitle: ")
+		sb.WriteString(params.Title)
+	}
+	sb.WriteString("\n\nTurns:\n")
+	for i, turn := range params.Turns {
+		sb.WriteString("[")
+		sb.WriteString(strconv.Itoa(i))
+		sb.WriteString("] ")
+		sb.WriteString(turn.Speaker)
+		sb.WriteString(": ")
+		sb.WriteString(turn.Text)
+		sb.WriteString("\n")
+	}
+	return []chatMessage{{Role: "user", Content: sb.String()}}
+}
+
+// parseQuiz decodes the model's JSON answer. Checking the answer keys against
+// the dialog is left to the dialogs service.
+func parseQuiz(provider, content string) ([]dialogs.QuizQuestion, error) {
+	content = stripCodeFence(content)
+	var parsed quizJSON
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return nil, fmt.Errorf("parse quiz json: %w content=%s", err, truncate([]byte(content), 256))
+	}
+
+	questions := make([]dialogs.QuizQuestion, 0, len(parsed.Questions))
+	for _, q := range parsed.Questions {
+		question := dialogs.QuizQuestion{
+			Kind:     dialogs.QuestionKind(strings.TrimSpace(q.Type)),
+			Prompt:   strings.TrimSpace(q.Question),
+			Answer:   q.Answer,
+			Turn:     q.Turn,
+			Evidence: strings.TrimSpace(q.Evidence),
+		}
+		if question.Kind == dialogs.QuestionTrueFalse {
+			question.Answer = 1
+			if q.Correct {
+				question.Answer = 0
+			}
+		} else {
+			for _, option := range q.Options {
+				question.Options = append(question.Options, strings.TrimSpace(option))
+			}
+		}
+		questions = append(questions, question)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("%s returned no quiz questions", provider)
+	}
+	return questions, nil
+}
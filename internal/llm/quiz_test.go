@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+var quizParams = dialogs.QuizParams{
+	InputLanguage:  "ru",
+	DialogLanguage: "es",
+	CEFRLevel:      "A2",
+	Title:          "En el mercado",
+	Turns: []dialogs.DialogTurn{
+		{Speaker: "Vendedora", Text: "¡Buenos días! ¿Qué desea?"},
+		{Speaker: "Cliente", Text: "Quiero un pescado fresco."},
+	},
+	Questions: 4,
+}
+
+func TestAnthropicClientGenerateQuizUsesQuizTool(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req messagesRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, quizSystemPrompt, req.System)
+		require.Equal(t, quizToolName, req.ToolChoice.Name)
+		require.Contains(t, req.Messages[0].Content, "[1] Cliente: Quiero un pescado fresco.")
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content": []map[string]any{{
+				"type": "tool_use",
+				"name": quizToolName,
+				"input": map[string]any{"questions": []map[string]any{
+					{"type": "multiple_choice", "question": "¿Qué quiere el cliente?", "options": []string{"carne", "pescado", "fruta"}, "answer": 1, "turn": 1, "evidence": "un pescado fresco"},
+				}},
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	client := NewAnthropicClient(slog.New(slog.NewTextHandler(io.Discard, nil)), "key", "claude-test", &AnthropicOptions{BaseURL: srv.URL})
+	questions, err := client.GenerateQuiz(context.Background(), quizParams)
+	require.NoError(t, err)
+	require.Len(t, questions, 1)
+	require.Equal(t, dialogs.QuestionMultipleChoice, questions[0].Kind)
+	require.Equal(t, []string{"carne", "pescado", "fruta"}, questions[0].Options)
+	require.Equal(t, 1, questions[0].Answer)
+}
+
+func TestParseQuizMapsTrueFalseAnswers(t *testing.T) {
+	content := "```json\n" + `{"questions":[
+		{"type":"true_false","question":"El cliente quiere pescado.","options":[],"answer":0,"correct":true,"turn":1,"evidence":"pescado"},
+		{"type":"true_false","question":"El cliente quiere carne.","options":["x"],"answer":0,"correct":false,"turn":1,"evidence":"pescado"}
+	]}` + "\n```"
+	questions, err := parseQuiz("test", content)
+	require.NoError(t, err)
+	require.Len(t, questions, 2)
+	require.Equal(t, 0, questions[0].Answer)
+	require.Equal(t, 1, questions[1].Answer)
+	require.Empty(t, questions[1].Options)
+
+	_, err = parseQuiz("test", `{"questions":[]}`)
+	require.Error(t, err)
+}
+
+func TestStubClientGenerateQuizIsDeterministic(t *testing.T) {
+	stub := NewStubClient(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	questions, err := stub.GenerateQuiz(context.Background(), quizParams)
+	require.NoError(t, err)
+	again, err := stub.GenerateQuiz(context.Background(), quizParams)
+	require.NoError(t, err)
+	require.Equal(t, questions, again)
+
+	require.Len(t, questions, 4)
+	require.Equal(t, "Vendedora", questions[0].Options[questions[0].Answer])
+	require.Equal(t, "Vendedora", questions[2].Options[questions[2].Answer])
+	require.NotEqual(t, questions[0].Answer, questions[2].Answer)
+	require.Equal(t, 0, questions[1].Answer)
+	require.Equal(t, 1, questions[3].Answer)
+}
@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"leveltalk/internal/config"
+	"leveltalk/internal/dialogs"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOllamaBaseURL = "http://localhost:11434/v1"
+	defaultAnthropicURL  = "https://api.anthropic.com/v1"
+
+	// Self-hosted models on a CPU box can take minutes per dialog.
+	defaultSelfHostedTimeout = 5 * time.Minute
+)
+
+// Factory builds an LLM client from configuration.
+type Factory func(logger *slog.Logger, cfg config.Config) (dialogs.LLMClient, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"stub": func(logger *slog.Logger, cfg config.Config) (dialogs.LLMClient, error) {
+			return NewStubClient(logger), nil
+		},
+		"openai": func(logger *slog.Logger, cfg config.Config) (dialogs.LLMClient, error) {
+			return newCompatibleClient(logger, cfg, "openai", defaultOpenAIBaseURL, 0, Quirks{JSONMode: true, JSONSchema: true}), nil
+		},
+		"anthropic": func(logger *slog.Logger, cfg config.Config) (dialogs.LLMClient, error) {
+			baseURL := defaultAnthropicURL
+			if cfg.LLMBaseURL != "" {
+				baseURL = cfg.LLMBaseURL
+			}
+			opts := &AnthropicOptions{
+				BaseURL:    strings.TrimRight(baseURL, "/") + "/messages",
+				MaxTokens:  cfg.LLMMaxTokens,
+				MaxRepairs: maxRepairs(cfg),
+			}
+			if cfg.LLMTimeout > 0 {
+				opts.HTTPClient = &http.Client{Timeout: cfg.LLMTimeout}
+			}
+			return NewAnthropicClient(logger, cfg.LLMAPIKey, cfg.LLMModel, opts), nil
+		},
+		"ollama": func(logger *slog.Logger, cfg config.Config) (dialogs.LLMClient, error) {
+			// Ollama's OpenAI endpoint ignores response_format and answers
+			// errors as {"error":"..."}; apiError handles the latter.
+			return newCompatibleClient(logger, cfg, "ollama", defaultOllamaBaseURL, defaultSelfHostedTimeout, Quirks{}), nil
+		},
+		"openai_compatible": func(logger *slog.Logger, cfg config.Config) (dialogs.LLMClient, error) {
+			if cfg.LLMBaseURL == "" {
+				return nil, fmt.Errorf("LLM_BASE_URL is required for openai_compatible")
+			}
+			return newCompatibleClient(logger, cfg, "openai_compatible", "", defaultSelfHostedTimeout, Quirks{}), nil
+		},
+	}
+)
+
+// Register adds or replaces a provider under name.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Providers lists the registered provider names.
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FromConfig builds the client selected by cfg.LLMProvider. When
+// cfg.LLMFallback names a provider, the result is a FallbackClient that turns
+// to it whenever the primary fails.
+func FromConfig(logger *slog.Logger, cfg config.Config) (dialogs.LLMClient, error) {
+	primary, err := build(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.LLMFallback.Provider == "" {
+		return primary, nil
+	}
+
+	fallbackCfg := cfg
+	fallbackCfg.LLMProvider = cfg.LLMFallback.Provider
+	fallbackCfg.LLMAPIKey = cfg.LLMFallback.APIKey
+	fallbackCfg.LLMModel = cfg.LLMFallback.Model
+	fallbackCfg.LLMBaseURL = cfg.LLMFallback.BaseURL
+	secondary, err := build(logger, fallbackCfg)
+	if err != nil {
+		return nil, fmt.Errorf("fallback: %w", err)
+	}
+	return NewFallbackClient(logger,
+		[]string{cfg.LLMProvider, cfg.LLMFallback.Provider},
+		[]dialogs.LLMClient{primary, secondary},
+	), nil
+}
+
+// build runs the factory registered for cfg.LLMProvider.
+func build(logger *slog.Logger, cfg config.Config) (dialogs.LLMClient, error) {
+	registryMu.RLock()
+	factory, ok := registry[cfg.LLMProvider]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider %q (available: %s)", cfg.LLMProvider, strings.Join(Providers(), ", "))
+	}
+	return factory(logger, cfg)
+}
+
+// newCompatibleClient configures an OpenAIClient for an OpenAI-compatible
+// server. cfg.LLMBaseURL overrides baseURL, and cfg.LLMTimeout overrides
+// timeout; a zero timeout keeps the client default.
+func newCompatibleClient(logger *slog.Logger, cfg config.Config, name, baseURL string, timeout time.Duration, quirks Quirks) *OpenAIClient {
+	if cfg.LLMBaseURL != "" {
+		baseURL = cfg.LLMBaseURL
+	}
+	if cfg.LLMTimeout > 0 {
+		timeout = cfg.LLMTimeout
+	}
+	if cfg.LLMJSONMode != nil {
+		quirks.JSONMode = *cfg.LLMJSONMode
+		quirks.JSONSchema = quirks.JSONSchema && *cfg.LLMJSONMode
+	}
+	if cfg.LLMStream != nil {
+		quirks.NoStreaming = !*cfg.LLMStream
+	}
+
+	opts := &OpenAIOptions{
+		BaseURL:    strings.TrimRight(baseURL, "/") + "/chat/completions",
+		Name:       name,
+		Quirks:     quirks,
+		MaxTokens:  cfg.LLMMaxTokens,
+		MaxRepairs: maxRepairs(cfg),
+	}
+	if timeout > 0 {
+		opts.HTTPClient = &http.Client{Timeout: timeout}
+	}
+	return NewOpenAIClient(logger, cfg.LLMAPIKey, cfg.LLMModel, opts)
+}
+
+// maxRepairs converts LLM_MAX_REPAIRS, where zero disables repairs, to the
+// MaxRepairs option, where zero selects the default.
+func maxRepairs(cfg config.Config) int {
+	if cfg.LLMMaxRepairs == 0 {
+		return -1
+	}
+	return cfg.LLMMaxRepairs
+}
@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/config"
+	"leveltalk/internal/dialogs"
+)
+
+var registryParams = dialogs.GenerateDialogParams{
+	InputLanguage:  "ru",
+	DialogLanguage: "es",
+	CEFRLevel:      "A1",
+	InputWords:     []string{"дом"},
+}
+
+// validDialogContent satisfies the dialog contract, so no repairs are requested.
+const validDialogContent = `{"title":"En casa","turns":[` +
+	`{"speaker":"Ana","gender":"female","text":"Mi casa es grande.","translation":"Мой дом большой."},` +
+	`{"speaker":"Luis","gender":"male","text":"¿Cuántas habitaciones tiene?"},` +
+	`{"speaker":"Ana","gender":"female","text":"Tiene tres habitaciones."},` +
+	`{"speaker":"Luis","gender":"male","text":"¡Qué bien!"},` +
+	`{"speaker":"Ana","gender":"female","text":"¿Quieres verla?"},` +
+	`{"speaker":"Luis","gender":"male","text":"Sí, mañana."}` +
+	`],"translations":{"дом":"casa"}}`
+
+func completionHandler(t *testing.T, check func(r *http.Request, req map[string]any)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/chat/completions", r.URL.Path)
+		var req map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		check(r, req)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": validDialogContent}}},
+		})
+	}
+}
+
+func TestFromConfigOllamaSkipsAuthAndResponseFormat(t *testing.T) {
+	srv := httptest.NewServer(completionHandler(t, func(r *http.Request, req map[string]any) {
+		require.Empty(t, r.Header.Get("Authorization"))
+		require.NotContains(t, req, "response_format")
+		require.Equal(t, "llama3.1:8b", req["model"])
+	}))
+	defer srv.Close()
+
+	client, err := FromConfig(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Config{
+		LLMProvider: "ollama",
+		LLMModel:    "llama3.1:8b",
+		LLMBaseURL:  srv.URL + "/v1/",
+	})
+	require.NoError(t, err)
+
+	dlg, err := client.GenerateDialog(context.Background(), registryParams)
+	require.NoError(t, err)
+	require.Equal(t, "En casa", dlg.Title)
+}
+
+func TestFromConfigOpenAISendsJSONSchema(t *testing.T) {
+	srv := httptest.NewServer(completionHandler(t, func(r *http.Request, req map[string]any) {
+		require.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		format := req["response_format"].(map[string]any)
+		require.Equal(t, "json_schema", format["type"])
+		require.Equal(t, "dialog", format["json_schema"].(map[string]any)["name"])
+	}))
+	defer srv.Close()
+
+	client, err := FromConfig(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Config{
+		LLMProvider: "openai",
+		LLMAPIKey:   "key",
+		LLMModel:    "gpt-test",
+		LLMBaseURL:  srv.URL + "/v1",
+	})
+	require.NoError(t, err)
+
+	_, err = client.GenerateDialog(context.Background(), registryParams)
+	require.NoError(t, err)
+}
+
+func TestFromConfigMaxTokens(t *testing.T) {
+	for configured, want := range map[int]float64{0: defaultMaxTokens, 4000: 4000} {
+		srv := httptest.NewServer(completionHandler(t, func(r *http.Request, req map[string]any) {
+			require.Equal(t, want, req["max_tokens"])
+		}))
+
+		client, err := FromConfig(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Config{
+			LLMProvider:  "ollama",
+			LLMModel:     "llama3.1:8b",
+			LLMBaseURL:   srv.URL + "/v1",
+			LLMMaxTokens: configured,
+		})
+		require.NoError(t, err)
+		_, err = client.GenerateDialog(context.Background(), registryParams)
+		require.NoError(t, err)
+		srv.Close()
+	}
+}
+
+func TestFromConfigUnknownProvider(t *testing.T) {
+	_, err := FromConfig(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Config{LLMProvider: "nope"})
+	require.ErrorContains(t, err, "unknown LLM provider")
+}
+
+func TestErrorBodyShapes(t *testing.T) {
+	require.Equal(t, "Incorrect API key (invalid_request_error)", errorBody([]byte(`{"error":{"message":"Incorrect API key","type":"invalid_request_error"}}`)))
+	require.Equal(t, `model "llama3" not found`, errorBody([]byte(`{"error":"model \"llama3\" not found"}`)))
+	require.Equal(t, "context length exceeded", errorBody([]byte(`{"object":"error","message":"context length exceeded","code":400}`)))
+	require.Equal(t, "Bad Gateway", errorBody([]byte("Bad Gateway")))
+}
+
+func TestStreamDialogFallsBackWithoutStreaming(t *testing.T) {
+	srv := httptest.NewServer(completionHandler(t, func(r *http.Request, req map[string]any) {
+		require.NotContains(t, req, "stream")
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(slog.New(slog.NewTextHandler(io.Discard, nil)), "", "model", &OpenAIOptions{
+		BaseURL: srv.URL + "/v1/chat/completions",
+		Quirks:  Quirks{NoStreaming: true},
+	})
+
+	var streamed int
+	dlg, err := client.StreamDialog(context.Background(), registryParams, func(dialogs.DialogTurn) { streamed++ })
+	require.NoError(t, err)
+	require.Equal(t, 6, streamed)
+	require.Len(t, dlg.Turns, 6)
+}
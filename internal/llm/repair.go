@@ -0,0 +1,196 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+
+	"leveltalk/internal/dialogs"
+)
+
+const (
+	minDialogTurns = 6
+	maxDialogTurns = 10
+
+	// defaultMaxRepairs bounds the follow-up requests sent for one dialog.
+	defaultMaxRepairs = 2
+)
+
+// dialogSchema is the JSON Schema of dialogJSON. OpenAI receives it as a
+// json_schema response format and Anthropic as the input schema of the
+// dialog tool.
+var dialogSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"title", "turns", "translations"},
+	"properties": map[string]any{
+		"title": map[string]any{"type": "string"},
+		"turns": map[string]any{
+			"type":     "array",
+			"minItems": minDialogTurns,
+			"maxItems": maxDialogTurns,
+			"items": map[string]any{
+				"type":     "object",
+				"required": []string{"speaker", "gender", "text"},
+				"properties": map[string]any{
+					"speaker":     map[string]any{"type": "string"},
+					"gender":      map[string]any{"type": "string", "enum": []string{"female", "male"}},
+					"text":        map[string]any{"type": "string"},
+					"translation": map[string]any{"type": "string"},
+				},
+			},
+		},
+		"translations": map[string]any{
+			"type":                 "object",
+			"additionalProperties": map[string]any{"type": "string"},
+		},
+	},
+}
+
+// turnBounds returns how many turns the model must produce for params.
+func turnBounds(params dialogs.GenerateDialogParams) (int, int) {
+	if params.NewTurns > 0 {
+		return params.NewTurns, params.NewTurns
+	}
+	return minDialogTurns, maxDialogTurns
+}
+
+// dialogSchemaFor returns dialogSchema with the turn bounds of params.
+func dialogSchemaFor(params dialogs.GenerateDialogParams) map[string]any {
+	lo, hi := turnBounds(params)
+	if lo == minDialogTurns && hi == maxDialogTurns {
+		return dialogSchema
+	}
+	turns := maps.Clone(dialogSchema["properties"].(map[string]any)["turns"].(map[string]any))
+	turns["minItems"], turns["maxItems"] = lo, hi
+	properties := maps.Clone(dialogSchema["properties"].(map[string]any))
+	properties["turns"] = turns
+	schema := maps.Clone(dialogSchema)
+	schema["properties"] = properties
+	return schema
+}
+
+// dialogMessages opens the conversation that asks for a dialog. The system
+// prompt is added by each client in the form its API expects.
+func dialogMessages(params dialogs.GenerateDialogParams) []chatMessage {
+	return []chatMessage{{Role: "user", Content: buildUserPrompt(params)}}
+}
+
+// validateDialog checks a parsed dialog against the contract of the prompt
+// and describes every violation in a sentence the model can act on.
+func validateDialog(dlg dialogs.Dialog, params dialogs.GenerateDialogParams) []string {
+	var problems []string
+	lo, hi := turnBounds(params)
+	switch n := len(dlg.Turns); {
+	case lo == hi && n != lo:
+		problems = append(problems, fmt.Sprintf("the dialog has %d turns but must have exactly %d", n, lo))
+	case n < lo || n > hi:
+		problems = append(problems, fmt.Sprintf("the dialog has %d turns but must have between %d and %d", n, lo, hi))
+	}
+
+	// A continuation extends a conversation whose speakers and vocabulary are
+	// already settled.
+	if len(params.Prior) > 0 {
+		known := make(map[string]bool)
+		for _, turn := range params.Prior {
+			known[turn.Speaker] = true
+		}
+		if params.Rewrite != nil {
+			for _, turn := range dlg.Turns {
+				if turn.Speaker != params.Rewrite.Speaker {
+					problems = append(problems, fmt.Sprintf("the rewritten turn must be spoken by %q, not %q", params.Rewrite.Speaker, turn.Speaker))
+					break
+				}
+			}
+			return problems
+		}
+		for _, turn := range dlg.Turns {
+			if !known[turn.Speaker] {
+				problems = append(problems, fmt.Sprintf("the speaker %q does not take part in the conversation so far", turn.Speaker))
+				break
+			}
+		}
+		return problems
+	}
+
+	speakers := make(map[string]bool)
+	for _, turn := range dlg.Turns {
+		speakers[turn.Speaker] = true
+	}
+	if len(speakers) < 2 {
+		problems = append(problems, "both speakers must take turns, but only one speaker appears")
+	}
+
+	var missing []string
+	for _, word := range params.InputWords {
+		word = strings.TrimSpace(word)
+		if dlg.Translations[word] == "" {
+			missing = append(missing, fmt.Sprintf("%q", word))
+		}
+	}
+	if len(missing) > 0 {
+		problems = append(problems, "the translations object has no entry for "+strings.Join(missing, ", "))
+	}
+	return problems
+}
+
+// completeFunc sends a conversation to the model and returns its raw answer.
+// schema is the dialog JSON Schema the answer must follow.
+type completeFunc func(ctx context.Context, schema map[string]any, messages []chatMessage) (string, error)
+
+// generateWithRepairs parses content, the model's answer to messages, and
+// validates it. While the answer cannot be parsed or breaks the contract, up
+// to maxRepairs follow-up requests quote the answer back together with the
+// problems found. A dialog that still violates the contract after the last
+// repair is returned as it is, so a minor flaw does not cost the whole
+// generation; an answer that never parsed is an error.
+func generateWithRepairs(ctx context.Context, logger *slog.Logger, provider string, maxRepairs int, params dialogs.GenerateDialogParams, messages []chatMessage, content string, complete completeFunc) (dialogs.Dialog, error) {
+	for attempt := 0; ; attempt++ {
+		dlg, err := parseDialog(logger, provider, content, params)
+		var problems []string
+		if err != nil {
+			problems = []string{err.Error()}
+		} else {
+			problems = validateDialog(dlg, params)
+			dlg.RepairAttempts = attempt
+		}
+		if len(problems) == 0 {
+			return dlg, nil
+		}
+
+		if attempt == maxRepairs {
+			if err != nil {
+				return dialogs.Dialog{}, err
+			}
+			logger.Warn("LLM dialog still invalid after repairs",
+				slog.String("provider", provider),
+				slog.Int("repairs", attempt),
+				slog.Any("problems", problems),
+			)
+			return dlg, nil
+		}
+
+		logger.Info("asking LLM to repair dialog",
+			slog.String("provider", provider),
+			slog.Int("attempt", attempt+1),
+			slog.Any("problems", problems),
+		)
+		messages = append(messages,
+			chatMessage{Role: "assistant", Content: content},
+			chatMessage{Role: "user", Content: repairPrompt(problems)},
+		)
+		content, err = complete(ctx, dialogSchemaFor(params), messages)
+		if err != nil {
+			return dialogs.Dialog{}, fmt.Errorf("repair dialog: %w", err)
+		}
+	}
+}
+
+func repairPrompt(problems []string) string {
+	var sb strings.Builder
+	sb.WriteString("Your previous answer is not valid: ")
+	sb.WriteString(strings.Join(problems, "; "))
+	sb.WriteString(". Respond again with the complete, corrected JSON only, keeping the same schema.")
+	return sb.String()
+}
@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/config"
+	"leveltalk/internal/dialogs"
+)
+
+func TestOpenAIClientRepairsInvalidDialog(t *testing.T) {
+	answers := []string{
+		"```json\n{\"title\":\"En casa\",\"turns\":[{\"speaker\":\"Ana\",\"text\":\"Mi casa.\"}]",
+		`{"title":"En casa","turns":[{"speaker":"Ana","text":"Mi casa."}],"translations":{}}`,
+		validDialogContent,
+	}
+	var requests []completionRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req completionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		requests = append(requests, req)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": answers[len(requests)-1]}}},
+		})
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(slog.New(slog.NewTextHandler(io.Discard, nil)), "", "model", &OpenAIOptions{BaseURL: srv.URL})
+	dlg, err := client.GenerateDialog(context.Background(), registryParams)
+	require.NoError(t, err)
+	require.Len(t, dlg.Turns, 6)
+	require.Equal(t, 2, dlg.RepairAttempts)
+
+	require.Len(t, requests, 3)
+	last := requests[2].Messages
+	require.Len(t, last, 6)
+	require.Equal(t, "assistant", last[4].Role)
+	require.Equal(t, answers[1], last[4].Content)
+	require.Contains(t, last[5].Content, "has 1 turns but must have between 6 and 10")
+	require.Contains(t, last[5].Content, "only one speaker")
+	require.Contains(t, last[5].Content, `no entry for "дом"`)
+}
+
+func TestOpenAIClientKeepsInvalidDialogAfterLastRepair(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		content := `{"title":"En casa","turns":[{"speaker":"Ana","text":"Mi casa."},{"speaker":"Luis","text":"Sí."}],"translations":{"дом":"casa"}}`
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": content}}},
+		})
+	}))
+	defer srv.Close()
+
+	client := NewOpenAIClient(slog.New(slog.NewTextHandler(io.Discard, nil)), "", "model", &OpenAIOptions{BaseURL: srv.URL, MaxRepairs: 1})
+	dlg, err := client.GenerateDialog(context.Background(), registryParams)
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+	require.Equal(t, 1, dlg.RepairAttempts)
+	require.Len(t, dlg.Turns, 2)
+}
+
+func TestFromConfigZeroMaxRepairsDisablesRepairs(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		content := `{"title":"En casa","turns":[{"speaker":"Ana","text":"Mi casa."}],"translations":{}}`
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"choices": []map[string]any{{"message": map[string]string{"content": content}}},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := FromConfig(slog.New(slog.NewTextHandler(io.Discard, nil)), config.Config{
+		LLMProvider:   "openai_compatible",
+		LLMModel:      "model",
+		LLMBaseURL:    srv.URL,
+		LLMMaxRepairs: 0,
+	})
+	require.NoError(t, err)
+	dlg, err := client.GenerateDialog(context.Background(), registryParams)
+	require.NoError(t, err)
+	require.Equal(t, 1, calls)
+	require.Zero(t, dlg.RepairAttempts)
+}
+
+func TestValidateContinuation(t *testing.T) {
+	params := registryParams
+	params.Prior = []dialogs.DialogTurn{{Speaker: "Ana", Text: "Hola."}, {Speaker: "Luis", Text: "Hola, Ana."}}
+	params.NewTurns = 2
+
+	require.Empty(t, validateDialog(dialogs.Dialog{Turns: []dialogs.DialogTurn{{Speaker: "Ana"}, {Speaker: "Luis"}}}, params))
+	problems := validateDialog(dialogs.Dialog{Turns: []dialogs.DialogTurn{{Speaker: "Ana"}, {Speaker: "Marta"}, {Speaker: "Ana"}}}, params)
+	require.Len(t, problems, 2)
+	require.Contains(t, problems[0], "has 3 turns but must have exactly 2")
+	require.Contains(t, problems[1], `"Marta"`)
+
+	turns := dialogSchemaFor(params)["properties"].(map[string]any)["turns"].(map[string]any)
+	require.Equal(t, 2, turns["minItems"])
+	require.Equal(t, 2, turns["maxItems"])
+	require.Equal(t, minDialogTurns, dialogSchema["properties"].(map[string]any)["turns"].(map[string]any)["minItems"])
+}
+
+func TestRewriteTurnPromptAndValidation(t *testing.T) {
+	params := registryParams
+	params.Prior = []dialogs.DialogTurn{
+		{Speaker: "Ana", Text: "Hola.", Position: 0},
+		{Speaker: "Luis", Text: "Hola, Ana.", Position: 1},
+		{Speaker: "Ana", Text: "¿Qué tal?", Position: 2},
+	}
+	params.NewTurns = 1
+	params.Rewrite = &params.Prior[1]
+
+	prompt := buildUserPrompt(params)
+	require.Contains(t, prompt, "\nAna: Hola.\n>>> Luis: Hola, Ana.\nAna: ¿Qué tal?\n")
+	require.Contains(t, prompt, "spoken by Luis")
+	require.NotContains(t, prompt, "continue the following conversation")
+
+	require.Empty(t, validateDialog(dialogs.Dialog{Turns: []dialogs.DialogTurn{{Speaker: "Luis"}}}, params))
+	problems := validateDialog(dialogs.Dialog{Turns: []dialogs.DialogTurn{{Speaker: "Ana"}}}, params)
+	require.Len(t, problems, 1)
+	require.Contains(t, problems[0], `must be spoken by "Luis"`)
+}
@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"leveltalk/internal/dialogs"
+)
+
+// rolePlayToolName is the tool Anthropic is forced to call for role-play
+// replies; its input is a rolePlayJSON.
+const rolePlayToolName = "emit_reply"
+
+const rolePlaySystemPrompt = "You are an expert language tutor acting out a scene with a learner. " +
+	"You play exactly one character and speak ONLY in the dialog language, at the requested CEFR level: short, natural lines the learner can understand and answer. " +
+	"Never speak for the learner's character and never leave the scene. " +
+	"When the learner's last message contains mistakes, put the whole message as a native speaker would say it in \"correction\" and a brief, encouraging explanation in the learner's input language in \"note\"; " +
+	"leave both empty when the message is fine. Do not mention mistakes in \"reply\". " +
+	"Always respond ONLY with JSON matching this exact schema: {\"reply\":\"string\",\"correction\":\"string\",\"note\":\"string\"}."
+
+// rolePlaySchema is the JSON Schema of rolePlayJSON.
+var rolePlaySchema = map[string]any{
+	"type":     "object",
+	"required": []string{"reply", "correction", "note"},
+	"properties": map[string]any{
+		"reply":      map[string]any{"type": "string"},
+		"correction": map[string]any{"type": "string"},
+		"note":       map[string]any{"type": "string"},
+	},
+}
+
+type rolePlayJSON struct {
+	Reply      string `json:"reply"`
+	Correction string `json:"correction"`
+	Note       string `json:"note"`
+}
+
+// RolePlay asks OpenAI for the partner's next line.
+func (c *OpenAIClient) RolePlay(ctx context.Context, params dialogs.RolePlayParams) (dialogs.RolePlayReply, error) {
+	content, err := c.completeWith(ctx, rolePlaySystemPrompt, jsonSchema{Name: "role_play_reply", Schema: rolePlaySchema}, rolePlayMessages(params))
+	if err != nil {
+		return dialogs.RolePlayReply{}, err
+	}
+	return parseRolePlayReply(c.name, content, params)
+}
+
+// RolePlay asks Anthropic for the partner's next line.
+func (c *AnthropicClient) RolePlay(ctx context.Context, params dialogs.RolePlayParams) (dialogs.RolePlayReply, error) {
+	content, err := c.completeWith(ctx, rolePlaySystemPrompt, anthropicTool{
+		Name:        rolePlayToolName,
+		Description: "Return the character's next line and the correction of the learner's last message.",
+		InputSchema: rolePlaySchema,
+	}, rolePlayMessages(params))
+	if err != nil {
+		return dialogs.RolePlayReply{}, err
+	}
+	return parseRolePlayReply("anthropic", content, params)
+}
+
+// RolePlay asks each client that can role-play in turn until one succeeds.
+func (f *FallbackClient) RolePlay(ctx context.Context, params dialogs.RolePlayParams) (dialogs.RolePlayReply, error) {
+	var errs []error
+	for i, client := range f.clients {
+		player, ok := client.(dialogs.RolePlayer)
+		if !ok {
+			continue
+		}
+		reply, err := player.RolePlay(ctx, params)
+		if err == nil {
+			return reply, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", f.names[i], err))
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if len(errs) == 0 {
+		return dialogs.RolePlayReply{}, dialogs.ErrRolePlayUnsupported
+	}
+	return dialogs.RolePlayReply{}, errors.Join(errs...)
+}
+
+// rolePlayMessages describes the scene and the transcript so far in a single
+// prompt, so every provider sees the same request.
+func rolePlayMessages(params dialogs.RolePlayParams) []chatMessage {
+	var sb strings.Builder
+	sb.WriteString("Act out a scene in ")
+	sb.WriteString(params.DialogLanguage)
+	sb.WriteString(" at CEFR ")
+	sb.WriteString(params.CEFRLevel)
+	sb.WriteString(" level. You are ")
+	sb.WriteString(params.PartnerSpeaker)
+	if params.PartnerGender != "" {
+		sb.WriteString(" (" + params.PartnerGender + ")")
+	}
+	sb.WriteString("; the learner is ")
+	sb.WriteString(params.LearnerSpeaker)
+	sb.WriteString(". The learner's native language is ")
+	sb.WriteString(params.InputLanguage)
+	sb.WriteString(".")
+	if params.Scenario != "" {
+		sb.WriteString(" Scenario: ")
+		sb.WriteString(params.Scenario)
+		sb.WriteString(".")
+	}
+	if len(params.InputWords) > 0 {
+		sb.WriteString(" Over the course of the scene, naturally use the ")
+		sb.WriteString(params.DialogLanguage)
+		sb.WriteString(" translations of these ")
+		sb.WriteString(params.InputLanguage)
+		sb.WriteString(" words so the learner practises them: ")
+		sb.WriteString(strings.Join(params.InputWords, ", "))
+		sb.WriteString(".")
+	}
+
+	if len(params.History) == 0 {
+		sb.WriteString("\n\nOpen the scene with your first line. Leave \"correction\" and \"note\" empty.")
+		return []chatMessage{{Role: "user", Content: sb.String()}}
+	}
+
+	sb.WriteString("\n\nConversation so far:\n")
+	for _, msg := range params.History {
+		sb.WriteString(msg.Speaker)
+		sb.WriteString(": ")
+		sb.WriteString(msg.Text)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nReply as ")
+	sb.WriteString(params.PartnerSpeaker)
+	sb.WriteString(" to the learner's last message and correct that message if needed.")
+	return []chatMessage{{Role: "user", Content: sb.String()}}
+}
+
+// parseRolePlayReply decodes the model's JSON answer. A correction that only
+// repeats the learner's message is dropped.
+func parseRolePlayReply(provider, content string, params dialogs.RolePlayParams) (dialogs.RolePlayReply, error) {
+	content = stripCodeFence(content)
+	var parsed rolePlayJSON
+	if err := json.Unmarshal([]byte(content), &parsed); err != nil {
+		return dialogs.RolePlayReply{}, fmt.Errorf("parse role-play json: %w content=%s", err, truncate([]byte(content), 256))
+	}
+
+	reply := dialogs.RolePlayReply{
+		Text:       strings.TrimSpace(parsed.Reply),
+		Correction: strings.TrimSpace(parsed.Correction),
+		Note:       strings.TrimSpace(parsed.Note),
+	}
+	if reply.Text == "" {
+		return dialogs.RolePlayReply{}, fmt.Errorf("%s returned an empty role-play reply", provider)
+	}
+
+	last := lastLearnerMessage(params.History)
+	if last == "" || reply.Correction == last {
+		reply.Correction, reply.Note = "", ""
+	}
+	return reply, nil
+}
+
+// lastLearnerMessage returns the text of the message the reply answers, or
+// "" when the partner opens the scene.
+func lastLearnerMessage(history []dialogs.RolePlayMessage) string {
+	if len(history) == 0 || !history[len(history)-1].Learner {
+		return ""
+	}
+	return history[len(history)-1].Text
+}
@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+var rolePlayParams = dialogs.RolePlayParams{
+	InputLanguage:  "ru",
+	DialogLanguage: "es",
+	CEFRLevel:      "A2",
+	Scenario:       "En el mercado",
+	InputWords:     []string{"рыба"},
+	LearnerSpeaker: "Cliente",
+	PartnerSpeaker: "Vendedora",
+	History: []dialogs.RolePlayMessage{
+		{Speaker: "Vendedora", Text: "¡Buenos días! ¿Qué desea?"},
+		{Speaker: "Cliente", Learner: true, Text: "Quiero un pescado fresca."},
+	},
+}
+
+func TestAnthropicClientRolePlayUsesReplyTool(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req messagesRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, rolePlaySystemPrompt, req.System)
+		require.Equal(t, rolePlayToolName, req.ToolChoice.Name)
+		require.Contains(t, req.Messages[0].Content, "Cliente: Quiero un pescado fresca.")
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"content": []map[string]any{{
+				"type":  "tool_use",
+				"name":  rolePlayToolName,
+				"input": map[string]string{"reply": "Claro, ¿cuál le gusta?", "correction": "Quiero un pescado fresco.", "note": "«Pescado» — мужской род."},
+			}},
+		})
+	}))
+	defer srv.Close()
+
+	client := NewAnthropicClient(slog.New(slog.NewTextHandler(io.Discard, nil)), "key", "claude-test", &AnthropicOptions{BaseURL: srv.URL})
+	reply, err := client.RolePlay(context.Background(), rolePlayParams)
+	require.NoError(t, err)
+	require.Equal(t, "Claro, ¿cuál le gusta?", reply.Text)
+	require.Equal(t, "Quiero un pescado fresco.", reply.Correction)
+	require.NotEmpty(t, reply.Note)
+}
+
+func TestParseRolePlayReplyDropsUnchangedCorrection(t *testing.T) {
+	content := `{"reply":"Muy bien.","correction":"Quiero un pescado fresca.","note":"Perfecto."}`
+	reply, err := parseRolePlayReply("test", content, rolePlayParams)
+	require.NoError(t, err)
+	require.Empty(t, reply.Correction)
+	require.Empty(t, reply.Note)
+
+	_, err = parseRolePlayReply("test", `{"reply":" "}`, rolePlayParams)
+	require.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 
 	"leveltalk/internal/dialogs"
@@ -31,17 +32,34 @@ func (s *StubClient) GenerateDialog(ctx context.Context, params dialogs.Generate
 	}
 
 	turnCount := max(4, len(params.InputWords))
+	if params.NewTurns > 0 {
+		turnCount = params.NewTurns
+	}
 	speakers := []string{"Ana", "Luis"}
+	genders := []string{"female", "male"}
 
+	// A continuation picks up the alternation and numbering where the prior
+	// turns stop.
+	offset := len(params.Prior)
 	turns := make([]dialogs.DialogTurn, 0, turnCount)
-	for i := 0; i < turnCount; i++ {
+	for i := offset; i < offset+turnCount; i++ {
 		word := params.InputWords[i%len(params.InputWords)]
 		sentence := buildSentence(params.DialogLanguage, params.CEFRLevel, word, i)
 		turns = append(turns, dialogs.DialogTurn{
-			Speaker: speakers[i%len(speakers)],
-			Text:    sentence,
+			Speaker:     speakers[i%len(speakers)],
+			Gender:      genders[i%len(genders)],
+			Text:        sentence,
+			Translation: buildSentence(params.InputLanguage, params.CEFRLevel, word, i),
+			Position:    i,
 		})
 	}
+	// A rewritten turn keeps its speaker and place; its numbering comes from
+	// the end of the conversation, so the text always changes.
+	if rewrite := params.Rewrite; rewrite != nil {
+		turns[0].Speaker = rewrite.Speaker
+		turns[0].Gender = rewrite.Gender
+		turns[0].Position = rewrite.Position
+	}
 
 	s.logger.Debug("stub LLM generated dialog",
 		slog.String("input_language", params.InputLanguage),
@@ -76,6 +94,107 @@ func (s *StubClient) GenerateDialog(ctx context.Context, params dialogs.Generate
 	}, nil
 }
 
+// StreamDialog generates the same dialog as GenerateDialog and reports each
+// turn through onTurn, mirroring how a streaming provider behaves.
+func (s *StubClient) StreamDialog(ctx context.Context, params dialogs.GenerateDialogParams, onTurn func(dialogs.DialogTurn)) (dialogs.Dialog, error) {
+	dlg, err := s.GenerateDialog(ctx, params)
+	if err != nil {
+		return dialogs.Dialog{}, err
+	}
+	for _, turn := range dlg.Turns {
+		onTurn(turn)
+	}
+	return dlg, nil
+}
+
+// RolePlay answers with a deterministic line about the session's words. A
+// learner message without final punctuation is "corrected" by adding it, so
+// the correction path can be exercised without a real model.
+func (s *StubClient) RolePlay(ctx context.Context, params dialogs.RolePlayParams) (dialogs.RolePlayReply, error) {
+	topics := params.InputWords
+	if len(topics) == 0 {
+		topics = []string{params.Scenario}
+	}
+	idx := len(params.History)
+	reply := dialogs.RolePlayReply{
+		Text: buildSentence(params.DialogLanguage, params.CEFRLevel, topics[idx%len(topics)], idx),
+	}
+
+	if last := lastLearnerMessage(params.History); last != "" && !strings.ContainsAny(last[len(last)-1:], ".!?") {
+		reply.Correction = last + "."
+		reply.Note = "End the sentence with a punctuation mark."
+	}
+	return reply, nil
+}
+
+// stubQuizNames are the distractors of the stub's multiple-choice questions.
+var stubQuizNames = []string{"Marta", "Pablo", "Sofía", "Jorge"}
+
+// GenerateQuiz asks about who says which turn, so the answer keys always hold
+// up against the dialog. Multiple-choice and true/false questions alternate,
+// and every other true/false statement names the wrong speaker.
+func (s *StubClient) GenerateQuiz(ctx context.Context, params dialogs.QuizParams) ([]dialogs.QuizQuestion, error) {
+	if len(params.Turns) == 0 {
+		return nil, fmt.Errorf("turns required")
+	}
+
+	var speakers []string
+	for _, turn := range params.Turns {
+		if !slices.Contains(speakers, turn.Speaker) {
+			speakers = append(speakers, turn.Speaker)
+		}
+	}
+	names := slices.Clone(speakers)
+	for _, name := range stubQuizNames {
+		if len(names) >= 4 {
+			break
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	questions := make([]dialogs.QuizQuestion, 0, params.Questions)
+	for i := 0; i < params.Questions; i++ {
+		idx := i % len(params.Turns)
+		turn := params.Turns[idx]
+		question := dialogs.QuizQuestion{Turn: idx, Evidence: turn.Text}
+		if i%2 == 0 {
+			// Rotating the options moves the right one around.
+			question.Kind = dialogs.QuestionMultipleChoice
+			question.Prompt = fmt.Sprintf("%s «%s»?", stubQuizPrompt(params.DialogLanguage), turn.Text)
+			shift := (i / 2) % len(names)
+			question.Options = append(slices.Clone(names[shift:]), names[:shift]...)
+			question.Answer = slices.Index(question.Options, turn.Speaker)
+		} else {
+			speaker := turn.Speaker
+			if i%4 == 3 {
+				speaker = names[(slices.Index(names, speaker)+1)%len(names)]
+				question.Answer = 1
+			}
+			question.Kind = dialogs.QuestionTrueFalse
+			question.Prompt = fmt.Sprintf("%s: «%s»", speaker, turn.Text)
+		}
+		questions = append(questions, question)
+	}
+	return questions, nil
+}
+
+func stubQuizPrompt(language string) string {
+	prompt := map[string]string{
+		"es": "¿Quién dice",
+		"en": "Who says",
+		"ru": "Кто говорит",
+		"fi": "Kuka sanoo",
+		"de": "Wer sagt",
+		"fr": "Qui dit",
+	}[strings.ToLower(language)]
+	if prompt == "" {
+		prompt = "Who says"
+	}
+	return prompt
+}
+
 func buildSentence(language, level, word string, idx int) string {
 	// Generate sentences entirely in the dialog language (monolingual)
 	prefix := map[string]string{
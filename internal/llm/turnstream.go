@@ -0,0 +1,102 @@
+package llm
+
+import "encoding/json"
+
+type turnJSON struct {
+	Speaker     string `json:"speaker"`
+	Gender      string `json:"gender,omitempty"`
+	Text        string `json:"text"`
+	Translation string `json:"translation,omitempty"`
+}
+
+// turnStreamParser scans a dialog JSON document as it arrives in fragments
+// and reports every object of the top-level "turns" array as soon as its
+// closing brace is seen. It tolerates leading noise such as code fences.
+// Each Write scans only the bytes it appends, so a response is scanned once
+// however finely it is split.
+type turnStreamParser struct {
+	buf []byte
+	pos int // next byte of buf to scan, carried across writes
+
+	stack      []byte // open containers: '{' or '['
+	inString   bool
+	escaped    bool
+	strStart   int            // offset of the current string's opening quote
+	lastString string         // most recent complete string, candidate key
+	keys       map[int]string // depth -> key being assigned in the object at that depth
+
+	turnsDepth int // stack depth of the turns array, 0 when not inside it
+	turnStart  int // offset of the current turn object's opening brace
+}
+
+func newTurnStreamParser() *turnStreamParser {
+	return &turnStreamParser{keys: make(map[int]string), turnStart: -1}
+}
+
+// Write feeds a fragment and returns the turns completed by it.
+func (p *turnStreamParser) Write(fragment string) []turnJSON {
+	p.buf = append(p.buf, fragment...)
+
+	var turns []turnJSON
+	for ; p.pos < len(p.buf); p.pos++ {
+		c := p.buf[p.pos]
+
+		if p.inString {
+			switch {
+			case p.escaped:
+				p.escaped = false
+			case c == '\\':
+				p.escaped = true
+			case c == '"':
+				p.inString = false
+				var s string
+				if err := json.Unmarshal(p.buf[p.strStart:p.pos+1], &s); err == nil {
+					p.lastString = s
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			if len(p.stack) > 0 {
+				p.inString = true
+				p.strStart = p.pos
+			}
+		case ':':
+			p.keys[len(p.stack)] = p.lastString
+		case ',':
+			delete(p.keys, len(p.stack))
+		case '{', '[':
+			if len(p.stack) == p.turnsDepth && p.turnsDepth > 0 && c == '{' {
+				p.turnStart = p.pos
+			}
+			p.stack = append(p.stack, c)
+			if c == '[' && len(p.stack) == 2 && p.stack[0] == '{' && p.keys[1] == "turns" {
+				p.turnsDepth = len(p.stack)
+			}
+		case '}', ']':
+			if len(p.stack) == 0 {
+				continue
+			}
+			delete(p.keys, len(p.stack))
+			p.stack = p.stack[:len(p.stack)-1]
+			switch {
+			case c == '}' && p.turnStart >= 0 && len(p.stack) == p.turnsDepth:
+				var turn turnJSON
+				if err := json.Unmarshal(p.buf[p.turnStart:p.pos+1], &turn); err == nil {
+					turns = append(turns, turn)
+				}
+				p.turnStart = -1
+			case c == ']' && len(p.stack) == p.turnsDepth-1:
+				p.turnsDepth = 0
+			}
+		}
+	}
+	return turns
+}
+
+// String returns everything written so far.
+func (p *turnStreamParser) String() string {
+	return string(p.buf)
+}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTurnStreamParserYieldsTurnsAsObjectsClose(t *testing.T) {
+	doc := "```json\n" + `{"title":"En la {tienda}","turns":[{"speaker":"Ana","text":"Hola, \"Luis\" ]}"},` +
+		`{"speaker":"Luis","text":"¿Qué tal? {bien}"}],"translations":{"дом":"casa"}}` + "\n```"
+
+	p := newTurnStreamParser()
+	var got []turnJSON
+	var yieldedAt []int
+	for i := 0; i < len(doc); i += 7 {
+		end := min(i+7, len(doc))
+		turns := p.Write(doc[i:end])
+		for range turns {
+			yieldedAt = append(yieldedAt, end)
+		}
+		got = append(got, turns...)
+	}
+
+	require.Equal(t, []turnJSON{
+		{Speaker: "Ana", Text: `Hola, "Luis" ]}`},
+		{Speaker: "Luis", Text: "¿Qué tal? {bien}"},
+	}, got)
+	// The first turn is reported before the second one has even started streaming.
+	require.LessOrEqual(t, yieldedAt[0], strings.Index(doc, `{"speaker":"Luis"`)+7)
+	require.Equal(t, doc, p.String())
+}
+
+func TestTurnStreamParserIgnoresNestedTurnsKeys(t *testing.T) {
+	p := newTurnStreamParser()
+	got := p.Write(`{"meta":{"turns":[{"speaker":"X","text":"nested"}]},"turns":[{"speaker":"A","text":"top"}]}`)
+	require.Equal(t, []turnJSON{{Speaker: "A", Text: "top"}}, got)
+}
+
+func TestTurnStreamParserScansEachByteOnce(t *testing.T) {
+	doc := `{"turns":[{"speaker":"A","text":"` + strings.Repeat("x", 1000) + `"},{"speaker":"B","text":"y"}]}`
+
+	p := newTurnStreamParser()
+	var got []turnJSON
+	for i := range len(doc) {
+		got = append(got, p.Write(doc[i:i+1])...)
+		require.Equal(t, i+1, p.pos, "only the new byte is scanned")
+	}
+	require.Len(t, got, 2)
+	require.Equal(t, "B", got[1].Speaker)
+}
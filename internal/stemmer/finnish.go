@@ -0,0 +1,44 @@
+package stemmer
+
+import "strings"
+
+var finnishVowel = vowels("aeiouyäö")
+
+var (
+	finnishParticle   = list("kin", "kaan", "kään", "ko", "kö", "han", "hän", "pa", "pä")
+	finnishPossessive = list("si", "ni", "nsa", "nsä", "mme", "nne", "an", "än", "en")
+	finnishCase       = list(
+		"hin", "hen", "hon", "hän", "hön", "siin", "seen", "han",
+		"ssa", "ssä", "sta", "stä", "lla", "llä", "lta", "ltä", "lle", "ksi",
+		"ine", "na", "nä", "tta", "ttä", "ta", "tä", "a", "ä", "n",
+	)
+	finnishDoubles = []string{"aa", "ee", "ii", "oo", "uu", "yy", "ää", "öö"}
+)
+
+func stemFinnish(w string) string {
+	r1 := regionAfter(w, 0, finnishVowel)
+
+	replaceIn(&w, r1, finnishParticle)
+	replaceIn(&w, r1, finnishPossessive)
+	replaceIn(&w, r1, finnishCase)
+
+	// Plural markers: -t of the nominative, -i-/-j- after the stem vowel.
+	switch {
+	case strings.HasSuffix(w, "t") && len(w)-1 >= r1 && endsWithAny(strings.TrimSuffix(w, "t"), "a", "e", "i", "o", "u", "y", "ä", "ö"):
+		w = strings.TrimSuffix(w, "t")
+	case endsWithAny(w, "i", "j") && len(w)-1 >= r1 && endsWithAny(w[:len(w)-1], "a", "e", "i", "o", "u", "y", "ä", "ö"):
+		w = w[:len(w)-1]
+	}
+
+	for _, double := range finnishDoubles {
+		if strings.HasSuffix(w, double) {
+			w = strings.TrimSuffix(w, double[len(double)/2:])
+			break
+		}
+	}
+
+	// Stem vowels change between forms (kirja, kirjassa, kirjoissa), so the
+	// last one is dropped as well.
+	replaceIn(&w, r1, list("a", "ä", "e", "i", "o", "u", "y", "ö"))
+	return w
+}
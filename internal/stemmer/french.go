@@ -0,0 +1,98 @@
+package stemmer
+
+import "strings"
+
+var frenchVowel = vowels("aeiouyâàëéêèïîôûù")
+
+var (
+	frenchStandardR2 = suffixes{
+		"ance": "", "ique": "", "isme": "", "able": "", "iste": "", "eux": "",
+		"ances": "", "iques": "", "ismes": "", "ables": "", "istes": "",
+		"atrice": "", "ateur": "", "ation": "", "atrices": "", "ateurs": "", "ations": "",
+		"logie": "log", "logies": "log", "usion": "u", "ution": "u", "usions": "u", "utions": "u",
+		"ence": "ent", "ences": "ent", "ité": "", "ités": "",
+		"if": "", "ive": "", "ifs": "", "ives": "", "euse": "", "euses": "",
+	}
+	frenchStandardRV = suffixes{
+		"ement": "", "ements": "", "amment": "ant", "emment": "ent", "ment": "", "ments": "",
+	}
+	frenchVerbI = list(
+		"îmes", "ît", "îtes", "i", "ie", "ies", "ir", "ira", "irai", "iraient", "irais",
+		"irait", "iras", "irent", "irez", "iriez", "irions", "irons", "iront", "is",
+		"issaient", "issais", "issait", "issant", "issante", "issantes", "issants", "isse",
+		"issent", "isses", "issez", "issiez", "issions", "issons", "it",
+	)
+	frenchVerb = list(
+		"é", "ée", "ées", "és", "èrent", "er", "era", "erai", "eraient", "erais", "erait",
+		"eras", "erez", "eriez", "erions", "erons", "eront", "ez", "iez",
+		"âmes", "ât", "âtes", "a", "ai", "aient", "ais", "ait", "ant", "ante", "antes",
+		"ants", "as", "asse", "assent", "asses", "assiez", "assions",
+	)
+	frenchAccents = strings.NewReplacer("é", "e", "è", "e", "ê", "e", "à", "a", "â", "a", "î", "i", "ô", "o", "û", "u", "ù", "u", "ç", "c")
+)
+
+func stemFrench(w string) string {
+	r1 := regionAfter(w, 0, frenchVowel)
+	r2 := regionAfter(w, r1, frenchVowel)
+	rv := frenchRV(w)
+
+	changed := replaceIn(&w, r2, frenchStandardR2) || replaceIn(&w, rv, frenchStandardRV)
+	switch {
+	case strings.HasSuffix(w, "eaux"):
+		w = strings.TrimSuffix(w, "x")
+		changed = true
+	case strings.HasSuffix(w, "aux") && len(w)-len("aux") >= r1:
+		w = strings.TrimSuffix(w, "ux") + "l"
+		changed = true
+	}
+
+	if !changed {
+		// i-verb endings only count after a consonant (fin|issons, not parla|it).
+		if suffix, _, ok := frenchVerbI.longest(w); ok && len(w)-len(suffix) > rv {
+			if stem := strings.TrimSuffix(w, suffix); !frenchVowel([]rune(stem)[len([]rune(stem))-1]) {
+				w = stem
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		changed = replaceIn(&w, rv, frenchVerb)
+	}
+	if !changed {
+		// Residual plural s and final e.
+		if strings.HasSuffix(w, "s") && !endsWithAny(strings.TrimSuffix(w, "s"), "a", "i", "o", "u", "è", "s") {
+			w = strings.TrimSuffix(w, "s")
+		}
+		replaceIn(&w, rv, suffixes{"ier": "i", "ière": "i", "e": "", "ë": ""})
+	}
+
+	for _, double := range []string{"enn", "onn", "ett", "ell", "eill"} {
+		if strings.HasSuffix(w, double) {
+			w = w[:len(w)-1]
+			break
+		}
+	}
+	return frenchAccents.Replace(w)
+}
+
+// frenchRV follows Snowball's French definition of RV.
+func frenchRV(w string) int {
+	runes := []rune(w)
+	if len(runes) < 3 {
+		return len(w)
+	}
+	if frenchVowel(runes[0]) && frenchVowel(runes[1]) {
+		return len(string(runes[:3]))
+	}
+	for _, prefix := range []string{"par", "col", "tap"} {
+		if strings.HasPrefix(w, prefix) {
+			return len(prefix)
+		}
+	}
+	for i := 1; i < len(runes); i++ {
+		if frenchVowel(runes[i]) {
+			return len(string(runes[:i+1]))
+		}
+	}
+	return len(w)
+}
@@ -0,0 +1,42 @@
+package stemmer
+
+import "strings"
+
+var germanVowel = vowels("aeiouyäöü")
+
+var germanUmlauts = strings.NewReplacer("ä", "a", "ö", "o", "ü", "u")
+
+func stemGerman(w string) string {
+	w = strings.ReplaceAll(w, "ß", "ss")
+
+	r1 := regionAfter(w, 0, germanVowel)
+	r2 := regionAfter(w, r1, germanVowel)
+	// R1 must leave at least three letters in front of it.
+	if prefix := len(string([]rune(w)[:min(3, len([]rune(w)))])); r1 < prefix {
+		r1 = prefix
+	}
+
+	// Step 1: case and plural endings.
+	switch {
+	case replaceIn(&w, r1, list("em", "ern", "er")):
+	case replaceIn(&w, r1, list("e", "en", "es")):
+		if strings.HasSuffix(w, "niss") {
+			w = strings.TrimSuffix(w, "s")
+		}
+	case strings.HasSuffix(w, "s") && len(w)-1 >= r1 && endsWithAny(strings.TrimSuffix(w, "s"), "b", "d", "f", "g", "h", "k", "l", "m", "n", "r", "t"):
+		w = strings.TrimSuffix(w, "s")
+	}
+
+	// Step 2: verb and comparative endings.
+	switch {
+	case replaceIn(&w, r1, list("en", "er", "est")):
+	case strings.HasSuffix(w, "st") && len(w)-2 >= r1 && len([]rune(w)) > 5 &&
+		endsWithAny(strings.TrimSuffix(w, "st"), "b", "d", "f", "g", "h", "k", "l", "m", "n", "t"):
+		w = strings.TrimSuffix(w, "st")
+	}
+
+	// Step 3: derivational suffixes.
+	replaceIn(&w, r2, list("end", "ung", "isch", "lich", "heit", "keit", "ig", "ik"))
+
+	return germanUmlauts.Replace(w)
+}
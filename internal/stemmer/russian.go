@@ -0,0 +1,70 @@
+package stemmer
+
+import "strings"
+
+var russianVowel = vowels("аеиоуыэюя")
+
+var (
+	// Endings that Snowball only removes after а or я keep that letter.
+	russianGerund = suffixes{
+		"ив": "", "ивши": "", "ившись": "", "ыв": "", "ывши": "", "ывшись": "",
+		"ав": "а", "авши": "а", "авшись": "а", "яв": "я", "явши": "я", "явшись": "я",
+	}
+	russianReflexive = list("ся", "сь")
+	russianEnding    = suffixes{
+		// adjectival
+		"ее": "", "ие": "", "ые": "", "ое": "", "ими": "", "ыми": "", "ей": "", "ий": "",
+		"ый": "", "ой": "", "ем": "", "им": "", "ым": "", "ом": "", "его": "", "ого": "",
+		"ему": "", "ому": "", "их": "", "ых": "", "ую": "", "юю": "", "ая": "", "яя": "",
+		"ою": "", "ею": "",
+		// verbal
+		"ала": "а", "ана": "а", "аете": "а", "айте": "а", "али": "а", "ай": "а", "ал": "а",
+		"аем": "а", "ан": "а", "ало": "а", "ано": "а", "ает": "а", "ают": "а", "аны": "а",
+		"ать": "а", "аешь": "а", "анно": "а",
+		"яла": "я", "яна": "я", "яете": "я", "яйте": "я", "яли": "я", "яй": "я", "ял": "я",
+		"яем": "я", "ян": "я", "яло": "я", "яно": "я", "яет": "я", "яют": "я", "яны": "я",
+		"ять": "я", "яешь": "я", "янно": "я",
+		"ила": "", "ыла": "", "ена": "", "ейте": "", "уйте": "", "ите": "", "или": "",
+		"ыли": "", "уй": "", "ил": "", "ыл": "", "ен": "", "ило": "", "ыло": "", "ено": "",
+		"ят": "", "ует": "", "уют": "", "ит": "", "ыт": "", "ены": "", "ить": "", "ыть": "",
+		"ишь": "", "ю": "",
+		// nominal
+		"а": "", "ев": "", "ов": "", "ье": "", "е": "", "иями": "", "ями": "", "ами": "",
+		"еи": "", "ии": "", "и": "", "ией": "", "й": "", "иям": "", "ям": "", "ием": "",
+		"ам": "", "о": "", "у": "", "ах": "", "иях": "", "ях": "", "ы": "", "ь": "",
+		"ию": "", "ью": "", "ия": "", "ья": "", "я": "",
+	}
+	russianDerivational = list("ост", "ость")
+	russianSuperlative  = list("ейш", "ейше")
+)
+
+func stemRussian(w string) string {
+	w = strings.ReplaceAll(w, "ё", "е")
+
+	// RV is the region after the first vowel; R2 is computed inside RV.
+	rv := len(w)
+	if i := strings.IndexFunc(w, russianVowel); i >= 0 {
+		rv = i + len("а")
+	}
+	r1 := regionAfter(w, 0, russianVowel)
+	r2 := regionAfter(w, r1, russianVowel)
+
+	if !replaceIn(&w, rv, russianGerund) {
+		replaceIn(&w, rv, russianReflexive)
+		replaceIn(&w, rv, russianEnding)
+	}
+	replaceIn(&w, rv, list("и"))
+	replaceIn(&w, r2, russianDerivational)
+
+	switch {
+	case replaceIn(&w, rv, russianSuperlative):
+		if strings.HasSuffix(w, "нн") {
+			w = strings.TrimSuffix(w, "н")
+		}
+	case strings.HasSuffix(w, "нн") && len(w)-len("н") >= rv:
+		w = strings.TrimSuffix(w, "н")
+	default:
+		replaceIn(&w, rv, list("ь"))
+	}
+	return w
+}
@@ -0,0 +1,72 @@
+package stemmer
+
+import "strings"
+
+var spanishVowel = vowels("aeiouáéíóúü")
+
+var (
+	spanishStandard = suffixes{
+		"anza": "", "anzas": "", "ico": "", "ica": "", "icos": "", "icas": "",
+		"ismo": "", "ismos": "", "able": "", "ables": "", "ible": "", "ibles": "",
+		"ista": "", "istas": "", "oso": "", "osa": "", "osos": "", "osas": "",
+		"amiento": "", "amientos": "", "imiento": "", "imientos": "",
+		"adora": "", "ador": "", "ación": "", "adoras": "", "adores": "", "aciones": "",
+		"ante": "", "antes": "", "ancia": "", "ancias": "",
+		"logía": "log", "logías": "log", "ución": "u", "uciones": "u",
+		"encia": "ente", "encias": "ente", "mente": "",
+		"idad": "", "idades": "", "iva": "", "ivo": "", "ivas": "", "ivos": "",
+	}
+	spanishVerb = list(
+		"arían", "arías", "arán", "arás", "aríais", "aría", "aréis", "aríamos", "aremos", "ará", "aré",
+		"erían", "erías", "erán", "erás", "eríais", "ería", "eréis", "eríamos", "eremos", "erá", "eré",
+		"irían", "irías", "irán", "irás", "iríais", "iría", "iréis", "iríamos", "iremos", "irá", "iré",
+		"aba", "ada", "ida", "ía", "ara", "iera", "ad", "ed", "id", "ase", "iese", "aste", "iste",
+		"an", "aban", "ían", "aran", "ieran", "asen", "iesen", "aron", "ieron", "ado", "ido",
+		"ando", "iendo", "ió", "ar", "er", "ir", "as", "abas", "adas", "idas", "ías", "aras",
+		"ieras", "ases", "ieses", "ís", "áis", "abais", "íais", "arais", "ierais", "aseis",
+		"ieseis", "asteis", "isteis", "ados", "idos", "amos", "ábamos", "íamos", "imos",
+		"áramos", "iéramos", "iésemos", "ásemos", "en", "es", "éis", "emos",
+	)
+	spanishResidual = list("os", "a", "o", "á", "í", "ó", "e", "é")
+	spanishAccents  = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u")
+)
+
+func stemSpanish(w string) string {
+	r1 := regionAfter(w, 0, spanishVowel)
+	r2 := regionAfter(w, r1, spanishVowel)
+	rv := spanishRV(w)
+
+	if !replaceIn(&w, r2, spanishStandard) {
+		replaceIn(&w, rv, spanishVerb)
+	}
+	replaceIn(&w, rv, spanishResidual)
+	return spanishAccents.Replace(w)
+}
+
+// spanishRV follows the Snowball definition shared by the Romance languages.
+func spanishRV(w string) int {
+	runes := []rune(w)
+	if len(runes) < 2 {
+		return len(w)
+	}
+	offset := func(i int) int { return len(string(runes[:i])) }
+	switch {
+	case !spanishVowel(runes[1]):
+		for i := 2; i < len(runes); i++ {
+			if spanishVowel(runes[i]) {
+				return offset(i + 1)
+			}
+		}
+	case spanishVowel(runes[0]):
+		for i := 2; i < len(runes); i++ {
+			if !spanishVowel(runes[i]) {
+				return offset(i + 1)
+			}
+		}
+	default:
+		if len(runes) >= 3 {
+			return offset(3)
+		}
+	}
+	return len(w)
+}
@@ -0,0 +1,122 @@
+// Package stemmer provides light Snowball-style stemmers. They follow the
+// structure of the Snowball algorithms (R1, R2 and RV regions, ordered suffix
+// steps) but keep only the steps that matter for matching inflected forms of
+// vocabulary, not for search-engine recall.
+package stemmer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Stemmer reduces words of one language to stems shared by their inflected
+// forms. Words must be lowercase.
+type Stemmer struct {
+	language string
+	stem     func(string) string
+}
+
+var languages = map[string]func(string) string{
+	"de": stemGerman,
+	"es": stemSpanish,
+	"fi": stemFinnish,
+	"fr": stemFrench,
+	"ru": stemRussian,
+}
+
+// New returns the stemmer for a language code such as "es".
+func New(language string) (*Stemmer, error) {
+	stem, ok := languages[strings.ToLower(language)]
+	if !ok {
+		return nil, fmt.Errorf("no stemmer for language %q", language)
+	}
+	return &Stemmer{language: strings.ToLower(language), stem: stem}, nil
+}
+
+// Languages lists the supported language codes.
+func Languages() []string {
+	codes := make([]string, 0, len(languages))
+	for code := range languages {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Language returns the language code the stemmer was built for.
+func (s *Stemmer) Language() string {
+	return s.language
+}
+
+// Stem returns the stem of word. Very short words are returned unchanged.
+func (s *Stemmer) Stem(word string) string {
+	if utf8.RuneCountInString(word) < 3 {
+		return word
+	}
+	return s.stem(word)
+}
+
+// suffixes maps a suffix to its replacement; "" deletes the suffix.
+type suffixes map[string]string
+
+// longest returns the longest suffix of w listed in set.
+func (set suffixes) longest(w string) (suffix, replace string, ok bool) {
+	for s, r := range set {
+		if len(s) > len(suffix) && strings.HasSuffix(w, s) {
+			suffix, replace, ok = s, r, true
+		}
+	}
+	return suffix, replace, ok
+}
+
+// list builds a suffix set that deletes every listed suffix.
+func list(items ...string) suffixes {
+	set := make(suffixes, len(items))
+	for _, item := range items {
+		set[item] = ""
+	}
+	return set
+}
+
+// replaceIn replaces the longest suffix of w listed in set when it starts at
+// or after region. Like Snowball, a longest suffix outside the region blocks
+// shorter ones. It reports whether w changed.
+func replaceIn(w *string, region int, set suffixes) bool {
+	suffix, replace, ok := set.longest(*w)
+	if !ok || len(*w)-len(suffix) < region {
+		return false
+	}
+	*w = strings.TrimSuffix(*w, suffix) + replace
+	return true
+}
+
+// regionAfter returns the byte offset just after the first non-vowel that
+// follows a vowel at or after from, or len(w) when there is none. Applied at
+// 0 it yields Snowball's R1, applied at R1 it yields R2.
+func regionAfter(w string, from int, isVowel func(rune) bool) int {
+	prevVowel := false
+	for i, r := range w[from:] {
+		v := isVowel(r)
+		if prevVowel && !v {
+			return from + i + utf8.RuneLen(r)
+		}
+		prevVowel = v
+	}
+	return len(w)
+}
+
+func vowels(set string) func(rune) bool {
+	return func(r rune) bool { return strings.ContainsRune(set, r) }
+}
+
+// endsWithAny reports whether w ends with one of the given suffixes.
+func endsWithAny(w string, items ...string) bool {
+	for _, item := range items {
+		if strings.HasSuffix(w, item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package stemmer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStemmersConflateInflectedForms(t *testing.T) {
+	cases := map[string][][]string{
+		"es": {{"casa", "casas"}, {"hablar", "hablamos", "hablaba"}, {"libro", "libros"}},
+		"ru": {{"дом", "дома", "домом", "домах"}, {"книга", "книги", "книгу", "книгой"}, {"читать", "читает"}},
+		"de": {{"haus", "hauses", "häuser"}, {"kind", "kinder", "kindern"}, {"spielen", "spiele", "spieler"}},
+		"fi": {{"talo", "talossa", "taloissa", "talot"}, {"kirja", "kirjassa", "kirjoissa"}},
+		"fr": {{"maison", "maisons"}, {"parler", "parlez", "parlait"}, {"cheval", "chevaux"}},
+	}
+	for lang, groups := range cases {
+		s, err := New(lang)
+		require.NoError(t, err)
+		for _, forms := range groups {
+			want := s.Stem(forms[0])
+			for _, form := range forms[1:] {
+				require.Equal(t, want, s.Stem(form), "%s: %s vs %s", lang, forms[0], form)
+			}
+		}
+	}
+}
+
+func TestNewUnknownLanguage(t *testing.T) {
+	_, err := New("xx")
+	require.Error(t, err)
+	require.Equal(t, []string{"de", "es", "fi", "fr", "ru"}, Languages())
+}
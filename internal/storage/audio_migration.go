@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// InlineAudioTurn is a turn whose audio is still embedded as a data URL.
+type InlineAudioTurn struct {
+	ID       uuid.UUID
+	DialogID uuid.UUID
+	Position int
+	AudioURL string
+}
+
+// ListInlineAudioTurns returns up to limit turns that still carry data URLs,
+// ordered by dialog and position and starting after the turn at position in
+// dialogID. Paging past the last turn seen skips turns that failed to migrate
+// and still carry their data URL. Pass uuid.Nil and -1 for the first page.
+func (r *DialogRepository) ListInlineAudioTurns(ctx context.Context, dialogID uuid.UUID, position, limit int) ([]InlineAudioTurn, error) {
+	const query = `
+		SELECT id, dialog_id, position, audio_url
+		FROM dialog_turns
+		WHERE audio_url LIKE 'data:%' AND (dialog_id, position) > ($1, $2)
+		ORDER BY dialog_id, position
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, dialogID, position, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select inline audio turns: %w", err)
+	}
+	defer rows.Close()
+
+	var result []InlineAudioTurn
+	for rows.Next() {
+		var turn InlineAudioTurn
+		if err := rows.Scan(&turn.ID, &turn.DialogID, &turn.Position, &turn.AudioURL); err != nil {
+			return nil, fmt.Errorf("scan inline audio turn: %w", err)
+		}
+		result = append(result, turn)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return result, nil
+}
+
+// AttachTurnAudio points a turn at its stored audio key, clears the inline
+// URL, and rewrites the dialog_json snapshot to match.
+func (r *DialogRepository) AttachTurnAudio(ctx context.Context, dialogID, turnID uuid.UUID, key string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	const updateTurn = `UPDATE dialog_turns SET audio_key = $1, audio_url = '', audio_pending = FALSE WHERE id = $2 AND dialog_id = $3`
+	if _, err := tx.ExecContext(ctx, updateTurn, key, turnID, dialogID); err != nil {
+		return fmt.Errorf("update turn: %w", err)
+	}
+
+	if err := rewriteDialogJSON(ctx, tx, dialogID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// rewriteDialogJSON rebuilds dialogs.dialog_json from the dialog_turns rows.
+func rewriteDialogJSON(ctx context.Context, tx *sql.Tx, dialogID uuid.UUID) error {
+	const queryTurns = `
+		SELECT ` + turnColumns + `
+		FROM dialog_turns
+		WHERE dialog_id = $1
+		ORDER BY position ASC
+	`
+	rows, err := tx.QueryContext(ctx, queryTurns, dialogID)
+	if err != nil {
+		return fmt.Errorf("select turns: %w", err)
+	}
+	defer rows.Close()
+
+	turns := []dialogs.DialogTurn{}
+	for rows.Next() {
+		turn, err := scanTurn(rows)
+		if err != nil {
+			return fmt.Errorf("scan turn: %w", err)
+		}
+		turns = append(turns, turn)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	turnsJSON, err := json.Marshal(turns)
+	if err != nil {
+		return fmt.Errorf("marshal turns: %w", err)
+	}
+
+	const updateDialog = `UPDATE dialogs SET dialog_json = $1 WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, updateDialog, turnsJSON, dialogID); err != nil {
+		return fmt.Errorf("update dialog json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialogRepositoryListInlineAudioTurnsPagesByPosition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDialogRepository(db)
+	dialogID, turnID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`WHERE audio_url LIKE 'data:%' AND \(dialog_id, position\) > \(\$1, \$2\)`).
+		WithArgs(dialogID, 3, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dialog_id", "position", "audio_url"}).
+			AddRow(turnID, dialogID, 4, "data:audio/mpeg;base64,AA=="))
+
+	turns, err := repo.ListInlineAudioTurns(context.Background(), dialogID, 3, 10)
+	require.NoError(t, err)
+	require.Equal(t, []InlineAudioTurn{{ID: turnID, DialogID: dialogID, Position: 4, AudioURL: "data:audio/mpeg;base64,AA=="}}, turns)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,214 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// JobRepository is a PostgreSQL-backed dialogs.JobQueue.
+type JobRepository struct {
+	db *sql.DB
+}
+
+// NewJobRepository creates a new job repository.
+func NewJobRepository(db *sql.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Enqueue inserts a queued job.
+func (r *JobRepository) Enqueue(ctx context.Context, job dialogs.Job) error {
+	inputJSON, err := json.Marshal(job.Input)
+	if err != nil {
+		return fmt.Errorf("marshal job input: %w", err)
+	}
+
+	kind := job.Kind
+	if kind == "" {
+		kind = dialogs.JobCreate
+	}
+
+	const insertJob = `
+		INSERT INTO generation_jobs (id, kind, state, input, parent_id, new_turns, max_attempts, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+	`
+	if _, err := r.db.ExecContext(ctx, insertJob,
+		job.ID,
+		kind,
+		job.State,
+		inputJSON,
+		job.ParentID,
+		job.NewTurns,
+		job.MaxAttempts,
+		job.CreatedAt,
+		job.UpdatedAt,
+	); err != nil {
+		return fmt.Errorf("insert job: %w", err)
+	}
+	return nil
+}
+
+// Claim locks the oldest runnable queued job with SKIP LOCKED so concurrent
+// workers never receive the same job, and marks it as generating_text.
+func (r *JobRepository) Claim(ctx context.Context) (dialogs.Job, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return dialogs.Job{}, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	const selectJob = `
+		SELECT id
+		FROM generation_jobs
+		WHERE state = 'queued' AND run_after <= NOW()
+		ORDER BY created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`
+	var id uuid.UUID
+	if err := tx.QueryRowContext(ctx, selectJob).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.Job{}, dialogs.ErrNoJob
+		}
+		return dialogs.Job{}, fmt.Errorf("select job: %w", err)
+	}
+
+	const claimJob = `
+		UPDATE generation_jobs
+		SET state = 'generating_text', attempts = attempts + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + jobColumns
+	job, err := scanJob(tx.QueryRowContext(ctx, claimJob, id))
+	if err != nil {
+		return dialogs.Job{}, fmt.Errorf("claim job: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return dialogs.Job{}, fmt.Errorf("commit tx: %w", err)
+	}
+	return job, nil
+}
+
+// GetJob fetches a job by id.
+func (r *JobRepository) GetJob(ctx context.Context, id uuid.UUID) (dialogs.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM generation_jobs WHERE id = $1`
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.Job{}, dialogs.ErrNotFound
+		}
+		return dialogs.Job{}, fmt.Errorf("select job: %w", err)
+	}
+	return job, nil
+}
+
+// SetState records the phase a running job is in.
+func (r *JobRepository) SetState(ctx context.Context, id uuid.UUID, state dialogs.JobState) error {
+	const query = `UPDATE generation_jobs SET state = $1, updated_at = NOW() WHERE id = $2`
+	return r.exec(ctx, "set job state", query, state, id)
+}
+
+// Complete marks a job as done and links the generated dialog.
+func (r *JobRepository) Complete(ctx context.Context, id, dialogID uuid.UUID) error {
+	const query = `UPDATE generation_jobs SET state = 'done', dialog_id = $1, last_error = '', updated_at = NOW() WHERE id = $2`
+	return r.exec(ctx, "complete job", query, dialogID, id)
+}
+
+// Retry returns a failed attempt to the queue.
+func (r *JobRepository) Retry(ctx context.Context, id uuid.UUID, lastError string, runAfter time.Time) error {
+	const query = `UPDATE generation_jobs SET state = 'queued', last_error = $1, run_after = $2, updated_at = NOW() WHERE id = $3`
+	return r.exec(ctx, "retry job", query, lastError, runAfter, id)
+}
+
+// Fail marks a job as permanently failed.
+func (r *JobRepository) Fail(ctx context.Context, id uuid.UUID, lastError string) error {
+	const query = `UPDATE generation_jobs SET state = 'failed', last_error = $1, updated_at = NOW() WHERE id = $2`
+	return r.exec(ctx, "fail job", query, lastError, id)
+}
+
+// Release requeues an interrupted job and gives back the attempt it consumed.
+func (r *JobRepository) Release(ctx context.Context, id uuid.UUID) error {
+	const query = `
+		UPDATE generation_jobs
+		SET state = 'queued', attempts = GREATEST(attempts - 1, 0), updated_at = NOW()
+		WHERE id = $1 AND state IN ('generating_text','synthesizing')
+	`
+	return r.exec(ctx, "release job", query, id)
+}
+
+// ReleaseStale requeues jobs that have been running since before olderThan.
+func (r *JobRepository) ReleaseStale(ctx context.Context, olderThan time.Time) (int, error) {
+	const query = `
+		UPDATE generation_jobs
+		SET state = 'queued', updated_at = NOW()
+		WHERE state IN ('generating_text','synthesizing') AND updated_at < $1
+	`
+	result, err := r.db.ExecContext(ctx, query, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("release stale jobs: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get rows affected: %w", err)
+	}
+	return int(n), nil
+}
+
+func (r *JobRepository) exec(ctx context.Context, op, query string, args ...any) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if n == 0 {
+		return dialogs.ErrNotFound
+	}
+	return nil
+}
+
+const jobColumns = `id, kind, state, input, parent_id, new_turns, dialog_id, attempts, max_attempts, last_error, created_at, updated_at`
+
+func scanJob(row *sql.Row) (dialogs.Job, error) {
+	var (
+		job       dialogs.Job
+		inputJSON []byte
+		parentID  uuid.NullUUID
+		dialogID  uuid.NullUUID
+	)
+	if err := row.Scan(
+		&job.ID,
+		&job.Kind,
+		&job.State,
+		&inputJSON,
+		&parentID,
+		&job.NewTurns,
+		&dialogID,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.LastError,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	); err != nil {
+		return dialogs.Job{}, err
+	}
+	if err := json.Unmarshal(inputJSON, &job.Input); err != nil {
+		return dialogs.Job{}, fmt.Errorf("unmarshal job input: %w", err)
+	}
+	if parentID.Valid {
+		job.ParentID = &parentID.UUID
+	}
+	if dialogID.Valid {
+		job.DialogID = &dialogID.UUID
+	}
+	return job, nil
+}
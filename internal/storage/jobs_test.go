@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestJobRepositoryClaim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewJobRepository(db)
+	id, parentID := uuid.New(), uuid.New()
+	input := dialogs.CreateDialogInput{InputLanguage: "ru", DialogLanguage: "es", CEFRLevel: "A2", InputWords: []string{"дом"}}
+	inputJSON, _ := json.Marshal(input)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE SKIP LOCKED").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
+	mock.ExpectQuery("UPDATE generation_jobs").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "kind", "state", "input", "parent_id", "new_turns", "dialog_id", "attempts", "max_attempts", "last_error", "created_at", "updated_at",
+		}).AddRow(id, "relevel", "generating_text", inputJSON, parentID, 0, nil, 1, 3, "", now, now))
+	mock.ExpectCommit()
+
+	job, err := repo.Claim(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, id, job.ID)
+	require.Equal(t, dialogs.JobGeneratingText, job.State)
+	require.Equal(t, dialogs.JobRelevel, job.Kind)
+	require.Equal(t, parentID, *job.ParentID)
+	require.Equal(t, input, job.Input)
+	require.Equal(t, 1, job.Attempts)
+	require.Nil(t, job.DialogID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepositoryClaimEmptyQueue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FOR UPDATE SKIP LOCKED").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	_, err = NewJobRepository(db).Claim(context.Background())
+	require.ErrorIs(t, err, dialogs.ErrNoJob)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepositoryEnqueueContinue(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	parentID := uuid.New()
+	job := dialogs.Job{
+		ID:          uuid.New(),
+		Kind:        dialogs.JobContinue,
+		State:       dialogs.JobQueued,
+		Input:       dialogs.CreateDialogInput{InputLanguage: "ru", DialogLanguage: "es", CEFRLevel: "B1", InputWords: []string{"дом"}},
+		ParentID:    &parentID,
+		NewTurns:    4,
+		MaxAttempts: 3,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	inputJSON, _ := json.Marshal(job.Input)
+
+	mock.ExpectExec("INSERT INTO generation_jobs").
+		WithArgs(job.ID, dialogs.JobContinue, dialogs.JobQueued, inputJSON, &parentID, 4, 3, job.CreatedAt, job.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, NewJobRepository(db).Enqueue(context.Background(), job))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// PracticeRepository is a PostgreSQL-backed dialogs.PracticeStore.
+type PracticeRepository struct {
+	db *sql.DB
+}
+
+// NewPracticeRepository creates a new practice repository.
+func NewPracticeRepository(db *sql.DB) *PracticeRepository {
+	return &PracticeRepository{db: db}
+}
+
+// AddClozeAttempt inserts a graded cloze submission.
+func (r *PracticeRepository) AddClozeAttempt(ctx context.Context, attempt dialogs.ClozeAttempt) error {
+	answersJSON, err := json.Marshal(attempt.Answers)
+	if err != nil {
+		return fmt.Errorf("marshal answers: %w", err)
+	}
+
+	const query = `
+		INSERT INTO cloze_attempts (id, learner_id, dialog_id, answers, correct, total, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7)
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		attempt.ID,
+		attempt.LearnerID,
+		attempt.DialogID,
+		answersJSON,
+		attempt.Correct,
+		attempt.Total(),
+		attempt.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("insert cloze attempt: %w", err)
+	}
+	return nil
+}
+
+// ListClozeAttempts returns the newest attempts of a learner at a dialog.
+func (r *PracticeRepository) ListClozeAttempts(ctx context.Context, learnerID, dialogID uuid.UUID, limit int) ([]dialogs.ClozeAttempt, error) {
+	const query = `
+		SELECT id, learner_id, dialog_id, answers, correct, created_at
+		FROM cloze_attempts
+		WHERE learner_id = $1 AND dialog_id = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, learnerID, dialogID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list cloze attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []dialogs.ClozeAttempt
+	for rows.Next() {
+		var (
+			attempt     dialogs.ClozeAttempt
+			answersJSON []byte
+		)
+		if err := rows.Scan(
+			&attempt.ID,
+			&attempt.LearnerID,
+			&attempt.DialogID,
+			&answersJSON,
+			&attempt.Correct,
+			&attempt.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan cloze attempt: %w", err)
+		}
+		if err := json.Unmarshal(answersJSON, &attempt.Answers); err != nil {
+			return nil, fmt.Errorf("unmarshal answers: %w", err)
+		}
+		attempts = append(attempts, attempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate cloze attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// AddDictationAttempt inserts a graded dictation of a turn.
+func (r *PracticeRepository) AddDictationAttempt(ctx context.Context, attempt dialogs.DictationAttempt) error {
+	const query = `
+		INSERT INTO dictation_attempts (id, learner_id, dialog_id, turn_id, given, correct, total, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		attempt.ID,
+		attempt.LearnerID,
+		attempt.DialogID,
+		attempt.TurnID,
+		attempt.Given,
+		attempt.Correct,
+		attempt.Total,
+		attempt.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("insert dictation attempt: %w", err)
+	}
+	return nil
+}
+
+// TurnAccuracies sums up the dictations of a learner per turn of a dialog.
+func (r *PracticeRepository) TurnAccuracies(ctx context.Context, learnerID, dialogID uuid.UUID) ([]dialogs.TurnAccuracy, error) {
+	const query = `
+		SELECT turn_id, COUNT(*), SUM(correct), SUM(total), MAX(created_at)
+		FROM dictation_attempts
+		WHERE learner_id = $1 AND dialog_id = $2
+		GROUP BY turn_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, learnerID, dialogID)
+	if err != nil {
+		return nil, fmt.Errorf("list turn accuracies: %w", err)
+	}
+	defer rows.Close()
+
+	var accuracies []dialogs.TurnAccuracy
+	for rows.Next() {
+		var accuracy dialogs.TurnAccuracy
+		if err := rows.Scan(
+			&accuracy.TurnID,
+			&accuracy.Attempts,
+			&accuracy.Correct,
+			&accuracy.Total,
+			&accuracy.LastAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan turn accuracy: %w", err)
+		}
+		accuracies = append(accuracies, accuracy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate turn accuracies: %w", err)
+	}
+	return accuracies, nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestPracticeRepositoryAddClozeAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	turnID := uuid.New()
+	attempt := dialogs.ClozeAttempt{
+		ID:        uuid.New(),
+		LearnerID: uuid.New(),
+		DialogID:  uuid.New(),
+		Answers: []dialogs.ClozeAnswer{
+			{TurnID: turnID, Expected: "casa", Given: "casa", Correct: true},
+			{TurnID: turnID, Expected: "perro", Given: "gato"},
+		},
+		Correct:   1,
+		CreatedAt: time.Now(),
+	}
+	answersJSON := `[{"turn_id":"` + turnID.String() + `","expected":"casa","given":"casa","correct":true},` +
+		`{"turn_id":"` + turnID.String() + `","expected":"perro","given":"gato","correct":false}]`
+
+	mock.ExpectExec("INSERT INTO cloze_attempts").
+		WithArgs(attempt.ID, attempt.LearnerID, attempt.DialogID, []byte(answersJSON), 1, 2, attempt.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, NewPracticeRepository(db).AddClozeAttempt(context.Background(), attempt))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPracticeRepositoryListClozeAttempts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	learnerID, dialogID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("FROM cloze_attempts").
+		WithArgs(learnerID, dialogID, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "learner_id", "dialog_id", "answers", "correct", "created_at"}).
+			AddRow(uuid.New(), learnerID, dialogID, []byte(`[{"expected":"casa","given":"cas","correct":true}]`), 1, now))
+
+	attempts, err := NewPracticeRepository(db).ListClozeAttempts(context.Background(), learnerID, dialogID, 5)
+	require.NoError(t, err)
+	require.Len(t, attempts, 1)
+	require.Equal(t, 1, attempts[0].Total())
+	require.Equal(t, "cas", attempts[0].Answers[0].Given)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPracticeRepositoryAddDictationAttempt(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	attempt := dialogs.DictationAttempt{
+		ID:        uuid.New(),
+		LearnerID: uuid.New(),
+		DialogID:  uuid.New(),
+		TurnID:    uuid.New(),
+		Given:     "mi pero es grande",
+		Correct:   3,
+		Total:     4,
+		CreatedAt: time.Now(),
+	}
+	mock.ExpectExec("INSERT INTO dictation_attempts").
+		WithArgs(attempt.ID, attempt.LearnerID, attempt.DialogID, attempt.TurnID, attempt.Given, 3, 4, attempt.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, NewPracticeRepository(db).AddDictationAttempt(context.Background(), attempt))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPracticeRepositoryTurnAccuracies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	learnerID, dialogID, turnID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("FROM dictation_attempts").
+		WithArgs(learnerID, dialogID).
+		WillReturnRows(sqlmock.NewRows([]string{"turn_id", "count", "sum", "sum", "max"}).
+			AddRow(turnID, 2, 5, 8, now))
+
+	accuracies, err := NewPracticeRepository(db).TurnAccuracies(context.Background(), learnerID, dialogID)
+	require.NoError(t, err)
+	require.Equal(t, []dialogs.TurnAccuracy{{TurnID: turnID, Attempts: 2, Correct: 5, Total: 8, LastAt: now}}, accuracies)
+	require.Equal(t, 0.625, accuracies[0].Accuracy())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
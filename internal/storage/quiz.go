@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// SaveQuiz stores quiz, replacing the earlier quiz of its dialog.
+func (r *DialogRepository) SaveQuiz(ctx context.Context, quiz dialogs.Quiz) error {
+	questionsJSON, err := json.Marshal(quiz.Questions)
+	if err != nil {
+		return fmt.Errorf("marshal questions: %w", err)
+	}
+
+	const query = `
+		INSERT INTO dialog_quizzes (dialog_id, questions, created_at)
+		VALUES ($1,$2,$3)
+		ON CONFLICT (dialog_id) DO UPDATE SET questions = EXCLUDED.questions, created_at = EXCLUDED.created_at
+	`
+	if _, err := r.db.ExecContext(ctx, query, quiz.DialogID, questionsJSON, quiz.CreatedAt); err != nil {
+		return fmt.Errorf("upsert quiz: %w", err)
+	}
+	return nil
+}
+
+// GetQuiz returns the quiz of a dialog, or dialogs.ErrQuizNotFound.
+func (r *DialogRepository) GetQuiz(ctx context.Context, dialogID uuid.UUID) (dialogs.Quiz, error) {
+	var (
+		quiz          = dialogs.Quiz{DialogID: dialogID}
+		questionsJSON []byte
+	)
+	const query = `SELECT questions, created_at FROM dialog_quizzes WHERE dialog_id = $1`
+	if err := r.db.QueryRowContext(ctx, query, dialogID).Scan(&questionsJSON, &quiz.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.Quiz{}, dialogs.ErrQuizNotFound
+		}
+		return dialogs.Quiz{}, fmt.Errorf("select quiz: %w", err)
+	}
+	if err := json.Unmarshal(questionsJSON, &quiz.Questions); err != nil {
+		return dialogs.Quiz{}, fmt.Errorf("unmarshal questions: %w", err)
+	}
+	return quiz, nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestDialogRepositorySaveQuiz(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	quiz := dialogs.Quiz{
+		DialogID: uuid.New(),
+		Questions: []dialogs.QuizQuestion{
+			{Kind: dialogs.QuestionTrueFalse, Prompt: "Ana tiene un perro.", Answer: 0, Turn: 1, Evidence: "mi perro"},
+		},
+		CreatedAt: time.Now(),
+	}
+	questionsJSON := `[{"kind":"true_false","prompt":"Ana tiene un perro.","answer":0,"turn":1,"evidence":"mi perro"}]`
+
+	mock.ExpectExec("INSERT INTO dialog_quizzes").
+		WithArgs(quiz.DialogID, []byte(questionsJSON), quiz.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, NewDialogRepository(db).SaveQuiz(context.Background(), quiz))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialogRepositoryGetQuiz(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	dialogID := uuid.New()
+	now := time.Now()
+	questionsJSON := `[{"kind":"multiple_choice","prompt":"¿Quién?","options":["Ana","Luis","Marta"],"answer":1,"turn":0,"evidence":"hola"}]`
+
+	mock.ExpectQuery("FROM dialog_quizzes").
+		WithArgs(dialogID).
+		WillReturnRows(sqlmock.NewRows([]string{"questions", "created_at"}).AddRow([]byte(questionsJSON), now))
+
+	quiz, err := NewDialogRepository(db).GetQuiz(context.Background(), dialogID)
+	require.NoError(t, err)
+	require.Equal(t, dialogID, quiz.DialogID)
+	require.Len(t, quiz.Questions, 1)
+	require.Equal(t, []string{"Ana", "Luis", "Marta"}, quiz.Questions[0].Options)
+	require.Equal(t, 1, quiz.Questions[0].Answer)
+
+	mock.ExpectQuery("FROM dialog_quizzes").
+		WithArgs(dialogID).
+		WillReturnRows(sqlmock.NewRows([]string{"questions", "created_at"}))
+	_, err = NewDialogRepository(db).GetQuiz(context.Background(), dialogID)
+	require.ErrorIs(t, err, dialogs.ErrQuizNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -13,6 +13,30 @@ import (
 	"leveltalk/internal/dialogs"
 )
 
+// turnColumns lists the dialog_turns columns read by scanTurn, in order.
+const turnColumns = `id, speaker, gender, voice_id, text, audio_url, audio_key, audio_pending, position, translation`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTurn(row rowScanner) (dialogs.DialogTurn, error) {
+	var turn dialogs.DialogTurn
+	err := row.Scan(
+		&turn.ID,
+		&turn.Speaker,
+		&turn.Gender,
+		&turn.VoiceID,
+		&turn.Text,
+		&turn.AudioURL,
+		&turn.AudioKey,
+		&turn.AudioPending,
+		&turn.Position,
+		&turn.Translation,
+	)
+	return turn, err
+}
+
 // DialogRepository persists dialogs in PostgreSQL.
 type DialogRepository struct {
 	db *sql.DB
@@ -48,8 +72,8 @@ func (r *DialogRepository) Create(ctx context.Context, dlg dialogs.Dialog) error
 
 	const insertDialog = `
 		INSERT INTO dialogs (
-			id, title, input_language, dialog_language, cefr_level, input_words, dialog_json, translations, created_at
-		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			id, title, input_language, dialog_language, cefr_level, input_words, dialog_json, translations, created_at, repair_attempts, coverage, coverage_flagged, estimated_level, parent_id
+		) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
 	`
 	if _, err := tx.ExecContext(ctx, insertDialog,
 		dlg.ID,
@@ -61,24 +85,157 @@ func (r *DialogRepository) Create(ctx context.Context, dlg dialogs.Dialog) error
 		turnsJSON,
 		translationsJSON,
 		dlg.CreatedAt,
+		dlg.RepairAttempts,
+		dlg.Coverage,
+		dlg.CoverageFlagged,
+		dlg.EstimatedLevel,
+		dlg.ParentID,
 	); err != nil {
 		return fmt.Errorf("insert dialog: %w", err)
 	}
 
-	const insertTurn = `
-		INSERT INTO dialog_turns (id, dialog_id, speaker, text, audio_url, position)
-		VALUES ($1,$2,$3,$4,$5,$6)
+	for _, turn := range dlg.Turns {
+		if err := insertTurn(ctx, tx, dlg.ID, turn); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// AppendTurns inserts dlg.Turns[from:] and rewrites the dialog's turn
+// snapshot, coverage and estimated level within one transaction. It returns
+// dialogs.ErrConflict when the stored dialog no longer has exactly from
+// turns, so a concurrent edit or continuation is not overwritten.
+func (r *DialogRepository) AppendTurns(ctx context.Context, dlg dialogs.Dialog, from int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	const lockDialog = `SELECT id FROM dialogs WHERE id = $1 FOR UPDATE`
+	var foundID uuid.UUID
+	if err := tx.QueryRowContext(ctx, lockDialog, dlg.ID).Scan(&foundID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.ErrNotFound
+		}
+		return fmt.Errorf("lock dialog: %w", err)
+	}
+
+	const countTurns = `SELECT COUNT(*) FROM dialog_turns WHERE dialog_id = $1`
+	var stored int
+	if err := tx.QueryRowContext(ctx, countTurns, dlg.ID).Scan(&stored); err != nil {
+		return fmt.Errorf("count turns: %w", err)
+	}
+	if stored != from {
+		return fmt.Errorf("%w: dialog has %d turns, expected %d", dialogs.ErrConflict, stored, from)
+	}
+
+	turnsJSON, err := json.Marshal(dlg.Turns)
+	if err != nil {
+		return fmt.Errorf("marshal turns: %w", err)
+	}
+
+	const updateDialog = `UPDATE dialogs SET dialog_json = $2, coverage = $3, coverage_flagged = $4, estimated_level = $5 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, updateDialog, dlg.ID, turnsJSON, dlg.Coverage, dlg.CoverageFlagged, dlg.EstimatedLevel); err != nil {
+		return fmt.Errorf("update dialog: %w", err)
+	}
+
+	for _, turn := range dlg.Turns[from:] {
+		if err := insertTurn(ctx, tx, dlg.ID, turn); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// Update snapshots the current content of dlg into dialog_revisions and
+// replaces it within one transaction. Turns keep their rows, so anything
+// keyed by turn id survives an edit; turns missing from dlg are removed.
+func (r *DialogRepository) Update(ctx context.Context, dlg dialogs.Dialog) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the dialog row serializes concurrent updates, so revision
+	// numbers cannot collide.
+	const lockDialog = `SELECT id FROM dialogs WHERE id = $1 FOR UPDATE`
+	var foundID uuid.UUID
+	if err := tx.QueryRowContext(ctx, lockDialog, dlg.ID).Scan(&foundID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.ErrNotFound
+		}
+		return fmt.Errorf("lock dialog: %w", err)
+	}
+
+	const insertRevision = `
+		INSERT INTO dialog_revisions (dialog_id, number, title, translations, turns)
+		SELECT id,
+			COALESCE((SELECT MAX(number) FROM dialog_revisions WHERE dialog_id = $1), 0) + 1,
+			COALESCE(title, ''), COALESCE(translations, '{}'::jsonb), dialog_json
+		FROM dialogs
+		WHERE id = $1
 	`
+	if _, err := tx.ExecContext(ctx, insertRevision, dlg.ID); err != nil {
+		return fmt.Errorf("insert revision: %w", err)
+	}
+
+	turnsJSON, err := json.Marshal(dlg.Turns)
+	if err != nil {
+		return fmt.Errorf("marshal turns: %w", err)
+	}
+	translationsJSON, err := json.Marshal(dlg.Translations)
+	if err != nil {
+		return fmt.Errorf("marshal translations: %w", err)
+	}
+
+	const updateDialog = `
+		UPDATE dialogs
+		SET title = $2, translations = $3, dialog_json = $4, coverage = $5, coverage_flagged = $6, estimated_level = $7
+		WHERE id = $1
+	`
+	if _, err := tx.ExecContext(ctx, updateDialog,
+		dlg.ID,
+		dlg.Title,
+		translationsJSON,
+		turnsJSON,
+		dlg.Coverage,
+		dlg.CoverageFlagged,
+		dlg.EstimatedLevel,
+	); err != nil {
+		return fmt.Errorf("update dialog: %w", err)
+	}
+
+	args := []any{dlg.ID}
+	keep := make([]string, 0, len(dlg.Turns))
 	for _, turn := range dlg.Turns {
-		if _, err := tx.ExecContext(ctx, insertTurn,
-			turn.ID,
-			dlg.ID,
-			turn.Speaker,
-			turn.Text,
-			turn.AudioURL,
-			turn.Position,
-		); err != nil {
-			return fmt.Errorf("insert turn: %w", err)
+		args = append(args, turn.ID)
+		keep = append(keep, fmt.Sprintf("$%d", len(args)))
+	}
+	deleteTurns := `DELETE FROM dialog_turns WHERE dialog_id = $1`
+	if len(keep) > 0 {
+		deleteTurns += ` AND id NOT IN (` + strings.Join(keep, ",") + `)`
+	}
+	if _, err := tx.ExecContext(ctx, deleteTurns, args...); err != nil {
+		return fmt.Errorf("delete turns: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM dialog_turn_spans WHERE dialog_id = $1`, dlg.ID); err != nil {
+		return fmt.Errorf("delete spans: %w", err)
+	}
+
+	for _, turn := range dlg.Turns {
+		if err := insertTurn(ctx, tx, dlg.ID, turn); err != nil {
+			return err
 		}
 	}
 
@@ -88,10 +245,107 @@ func (r *DialogRepository) Create(ctx context.Context, dlg dialogs.Dialog) error
 	return nil
 }
 
+// ListRevisions returns the revisions of a dialog, newest first.
+func (r *DialogRepository) ListRevisions(ctx context.Context, dialogID uuid.UUID) ([]dialogs.DialogRevision, error) {
+	const query = `
+		SELECT ` + revisionColumns + `
+		FROM dialog_revisions
+		WHERE dialog_id = $1
+		ORDER BY number DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, dialogID)
+	if err != nil {
+		return nil, fmt.Errorf("select revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []dialogs.DialogRevision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetRevision fetches a single revision of a dialog.
+func (r *DialogRepository) GetRevision(ctx context.Context, dialogID uuid.UUID, number int) (dialogs.DialogRevision, error) {
+	const query = `
+		SELECT ` + revisionColumns + `
+		FROM dialog_revisions
+		WHERE dialog_id = $1 AND number = $2
+	`
+	rev, err := scanRevision(r.db.QueryRowContext(ctx, query, dialogID, number))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.DialogRevision{}, dialogs.ErrNotFound
+		}
+		return dialogs.DialogRevision{}, err
+	}
+	return rev, nil
+}
+
+const revisionColumns = `dialog_id, number, title, translations, turns, created_at`
+
+func scanRevision(row rowScanner) (dialogs.DialogRevision, error) {
+	var (
+		rev              dialogs.DialogRevision
+		translationsJSON []byte
+		turnsJSON        []byte
+	)
+	if err := row.Scan(&rev.DialogID, &rev.Number, &rev.Title, &translationsJSON, &turnsJSON, &rev.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.DialogRevision{}, err
+		}
+		return dialogs.DialogRevision{}, fmt.Errorf("scan revision: %w", err)
+	}
+	if err := json.Unmarshal(translationsJSON, &rev.Translations); err != nil {
+		return dialogs.DialogRevision{}, fmt.Errorf("unmarshal translations: %w", err)
+	}
+	if err := json.Unmarshal(turnsJSON, &rev.Turns); err != nil {
+		return dialogs.DialogRevision{}, fmt.Errorf("unmarshal turns: %w", err)
+	}
+	return rev, nil
+}
+
+// insertTurn stores a turn of dialogID together with its vocabulary spans.
+// A turn that already has a row is overwritten, which Update relies on.
+func insertTurn(ctx context.Context, tx *sql.Tx, dialogID uuid.UUID, turn dialogs.DialogTurn) error {
+	const query = `
+		INSERT INTO dialog_turns (id, dialog_id, speaker, gender, voice_id, text, audio_url, audio_key, audio_pending, position, translation)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+		ON CONFLICT (id) DO UPDATE SET
+			speaker = EXCLUDED.speaker, gender = EXCLUDED.gender, voice_id = EXCLUDED.voice_id, text = EXCLUDED.text,
+			audio_url = EXCLUDED.audio_url, audio_key = EXCLUDED.audio_key, audio_pending = EXCLUDED.audio_pending,
+			position = EXCLUDED.position, translation = EXCLUDED.translation
+	`
+	if _, err := tx.ExecContext(ctx, query,
+		turn.ID,
+		dialogID,
+		turn.Speaker,
+		turn.Gender,
+		turn.VoiceID,
+		turn.Text,
+		turn.AudioURL,
+		turn.AudioKey,
+		turn.AudioPending,
+		turn.Position,
+		turn.Translation,
+	); err != nil {
+		return fmt.Errorf("insert turn: %w", err)
+	}
+	return insertSpans(ctx, tx, dialogID, turn)
+}
+
 // GetByID fetches a dialog with all turns.
 func (r *DialogRepository) GetByID(ctx context.Context, id uuid.UUID) (dialogs.Dialog, error) {
 	const queryDialog = `
-		SELECT id, COALESCE(title, ''), input_language, dialog_language, cefr_level, input_words, COALESCE(translations, '{}'::jsonb), created_at
+		SELECT id, COALESCE(title, ''), input_language, dialog_language, cefr_level, input_words, COALESCE(translations, '{}'::jsonb), created_at, repair_attempts, coverage, coverage_flagged, estimated_level, parent_id
 		FROM dialogs
 		WHERE id = $1
 	`
@@ -107,6 +361,11 @@ func (r *DialogRepository) GetByID(ctx context.Context, id uuid.UUID) (dialogs.D
 		&inputWordsJSON,
 		&translationsJSON,
 		&dlg.CreatedAt,
+		&dlg.RepairAttempts,
+		&dlg.Coverage,
+		&dlg.CoverageFlagged,
+		&dlg.EstimatedLevel,
+		&dlg.ParentID,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return dialogs.Dialog{}, dialogs.ErrNotFound
@@ -124,7 +383,7 @@ func (r *DialogRepository) GetByID(ctx context.Context, id uuid.UUID) (dialogs.D
 	}
 
 	const queryTurns = `
-		SELECT id, speaker, text, audio_url, position
+		SELECT ` + turnColumns + `
 		FROM dialog_turns
 		WHERE dialog_id = $1
 		ORDER BY position ASC
@@ -136,8 +395,8 @@ func (r *DialogRepository) GetByID(ctx context.Context, id uuid.UUID) (dialogs.D
 	defer rows.Close()
 
 	for rows.Next() {
-		var turn dialogs.DialogTurn
-		if err := rows.Scan(&turn.ID, &turn.Speaker, &turn.Text, &turn.AudioURL, &turn.Position); err != nil {
+		turn, err := scanTurn(rows)
+		if err != nil {
 			return dialogs.Dialog{}, fmt.Errorf("scan turn: %w", err)
 		}
 		dlg.Turns = append(dlg.Turns, turn)
@@ -145,16 +404,199 @@ func (r *DialogRepository) GetByID(ctx context.Context, id uuid.UUID) (dialogs.D
 	if err := rows.Err(); err != nil {
 		return dialogs.Dialog{}, fmt.Errorf("rows error: %w", err)
 	}
+
+	if err := r.attachSpans(ctx, &dlg); err != nil {
+		return dialogs.Dialog{}, err
+	}
 	return dlg, nil
 }
 
+// insertSpans stores where a turn realizes the input words.
+func insertSpans(ctx context.Context, tx *sql.Tx, dialogID uuid.UUID, turn dialogs.DialogTurn) error {
+	const insertSpan = `
+		INSERT INTO dialog_turn_spans (turn_id, dialog_id, start_offset, end_offset, input_word)
+		VALUES ($1,$2,$3,$4,$5)
+		ON CONFLICT DO NOTHING
+	`
+	for _, span := range turn.Spans {
+		if _, err := tx.ExecContext(ctx, insertSpan, turn.ID, dialogID, span.Start, span.End, span.Word); err != nil {
+			return fmt.Errorf("insert span: %w", err)
+		}
+	}
+	return nil
+}
+
+// attachSpans loads the vocabulary spans of every turn of dlg.
+func (r *DialogRepository) attachSpans(ctx context.Context, dlg *dialogs.Dialog) error {
+	const querySpans = `
+		SELECT turn_id, start_offset, end_offset, input_word
+		FROM dialog_turn_spans
+		WHERE dialog_id = $1
+		ORDER BY start_offset ASC
+	`
+	rows, err := r.db.QueryContext(ctx, querySpans, dlg.ID)
+	if err != nil {
+		return fmt.Errorf("select spans: %w", err)
+	}
+	defer rows.Close()
+
+	byTurn := make(map[uuid.UUID][]dialogs.VocabSpan)
+	for rows.Next() {
+		var (
+			turnID uuid.UUID
+			span   dialogs.VocabSpan
+		)
+		if err := rows.Scan(&turnID, &span.Start, &span.End, &span.Word); err != nil {
+			return fmt.Errorf("scan span: %w", err)
+		}
+		byTurn[turnID] = append(byTurn[turnID], span)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+
+	for i := range dlg.Turns {
+		dlg.Turns[i].Spans = byTurn[dlg.Turns[i].ID]
+	}
+	return nil
+}
+
+// GetTurn fetches a single turn by id.
+func (r *DialogRepository) GetTurn(ctx context.Context, id uuid.UUID) (dialogs.DialogTurn, error) {
+	const queryTurn = `
+		SELECT ` + turnColumns + `
+		FROM dialog_turns
+		WHERE id = $1
+	`
+	turn, err := scanTurn(r.db.QueryRowContext(ctx, queryTurn, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.DialogTurn{}, dialogs.ErrNotFound
+		}
+		return dialogs.DialogTurn{}, fmt.Errorf("select turn: %w", err)
+	}
+	return turn, nil
+}
+
+// SetTurnAudio stores the voice and audio fields of turn and rewrites the
+// dialog_json snapshot to match.
+func (r *DialogRepository) SetTurnAudio(ctx context.Context, dialogID uuid.UUID, turn dialogs.DialogTurn) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	const updateTurn = `
+		UPDATE dialog_turns
+		SET voice_id = $3, audio_key = $4, audio_url = $5, audio_pending = $6
+		WHERE id = $1 AND dialog_id = $2
+	`
+	res, err := tx.ExecContext(ctx, updateTurn, turn.ID, dialogID, turn.VoiceID, turn.AudioKey, turn.AudioURL, turn.AudioPending)
+	if err != nil {
+		return fmt.Errorf("update turn: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return dialogs.ErrNotFound
+	}
+
+	if err := rewriteDialogJSON(ctx, tx, dialogID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// ListPlaceholderDialogs returns the ids of dialogs with a turn that has no
+// stored audio and is pending or points at the placeholder clip.
+func (r *DialogRepository) ListPlaceholderDialogs(ctx context.Context) ([]uuid.UUID, error) {
+	const query = `
+		SELECT d.id
+		FROM dialogs d
+		WHERE EXISTS (
+			SELECT 1 FROM dialog_turns t
+			WHERE t.dialog_id = d.id AND t.audio_key = ''
+				AND (t.audio_pending OR t.audio_url LIKE $1)
+		)
+		ORDER BY d.created_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, dialogs.PlaceholderAudioPath+"%")
+	if err != nil {
+		return nil, fmt.Errorf("select placeholder dialogs: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan dialog id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return ids, nil
+}
+
+// ListFamily returns the dialogs sharing a root with id through parent_id,
+// other than id itself, without their turns.
+func (r *DialogRepository) ListFamily(ctx context.Context, id uuid.UUID) ([]dialogs.Dialog, error) {
+	const query = `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM dialogs WHERE id = $1
+			UNION ALL
+			SELECT d.id, d.parent_id FROM dialogs d JOIN ancestors a ON d.id = a.parent_id
+		), family AS (
+			SELECT id FROM ancestors WHERE parent_id IS NULL
+			UNION ALL
+			SELECT d.id FROM dialogs d JOIN family f ON d.parent_id = f.id
+		)
+		SELECT d.id, COALESCE(d.title, ''), d.input_language, d.dialog_language, d.cefr_level, d.created_at, d.parent_id
+		FROM dialogs d
+		JOIN family f ON f.id = d.id
+		WHERE d.id <> $1
+		ORDER BY d.created_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("select dialog family: %w", err)
+	}
+	defer rows.Close()
+
+	var family []dialogs.Dialog
+	for rows.Next() {
+		var dlg dialogs.Dialog
+		if err := rows.Scan(
+			&dlg.ID,
+			&dlg.Title,
+			&dlg.InputLanguage,
+			&dlg.DialogLanguage,
+			&dlg.CEFRLevel,
+			&dlg.CreatedAt,
+			&dlg.ParentID,
+		); err != nil {
+			return nil, fmt.Errorf("scan dialog: %w", err)
+		}
+		family = append(family, dlg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return family, nil
+}
+
 // Search returns dialogs filtered by provided criteria.
 func (r *DialogRepository) Search(ctx context.Context, filter dialogs.DialogFilter) ([]dialogs.Dialog, error) {
 	query := strings.Builder{}
 	args := []any{}
 
 	query.WriteString(`
-		SELECT id, COALESCE(title, ''), input_language, dialog_language, cefr_level, input_words, dialog_json, COALESCE(translations, '{}'::jsonb), created_at
+		SELECT id, COALESCE(title, ''), input_language, dialog_language, cefr_level, input_words, dialog_json, COALESCE(translations, '{}'::jsonb), created_at, repair_attempts, coverage, coverage_flagged, estimated_level
 		FROM dialogs
 		WHERE 1=1
 	`)
@@ -209,6 +651,10 @@ func (r *DialogRepository) Search(ctx context.Context, filter dialogs.DialogFilt
 			&dialogTurns,
 			&translations,
 			&dlg.CreatedAt,
+			&dlg.RepairAttempts,
+			&dlg.Coverage,
+			&dlg.CoverageFlagged,
+			&dlg.EstimatedLevel,
 		); err != nil {
 			return nil, fmt.Errorf("scan dialog: %w", err)
 		}
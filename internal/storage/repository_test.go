@@ -29,8 +29,10 @@ func TestDialogRepositoryCreate(t *testing.T) {
 		InputWords:     []string{"дом", "улица"},
 		Translations:   map[string]string{"дом": "casa", "улица": "calle"},
 		CreatedAt:      now,
+		EstimatedLevel: "B1",
 		Turns: []dialogs.DialogTurn{
-			{ID: uuid.New(), Speaker: "Ana", Text: "Hola casa", AudioURL: "/static/audio/placeholder.mp3", Position: 0},
+			{ID: uuid.New(), Speaker: "Ana", Gender: "female", VoiceID: "voice-ana", Text: "Hola casa", AudioURL: "/static/audio/placeholder.mp3", Position: 0,
+				Translation: "Привет, дом", Spans: []dialogs.VocabSpan{{Start: 5, End: 9, Word: "дом"}}},
 		},
 	}
 
@@ -46,6 +48,11 @@ func TestDialogRepositoryCreate(t *testing.T) {
 			sqlmock.AnyArg(),
 			sqlmock.AnyArg(),
 			dlg.CreatedAt,
+			dlg.RepairAttempts,
+			dlg.Coverage,
+			dlg.CoverageFlagged,
+			dlg.EstimatedLevel,
+			dlg.ParentID,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectExec("INSERT INTO dialog_turns").
@@ -53,11 +60,19 @@ func TestDialogRepositoryCreate(t *testing.T) {
 			dlg.Turns[0].ID,
 			dlg.ID,
 			dlg.Turns[0].Speaker,
+			dlg.Turns[0].Gender,
+			dlg.Turns[0].VoiceID,
 			dlg.Turns[0].Text,
 			dlg.Turns[0].AudioURL,
+			dlg.Turns[0].AudioKey,
+			dlg.Turns[0].AudioPending,
 			dlg.Turns[0].Position,
+			dlg.Turns[0].Translation,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO dialog_turn_spans").
+		WithArgs(dlg.Turns[0].ID, dlg.ID, 5, 9, "дом").
+		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
 	err = repo.Create(context.Background(), dlg)
@@ -65,6 +80,245 @@ func TestDialogRepositoryCreate(t *testing.T) {
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDialogRepositoryGetByIDAttachesSpans(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDialogRepository(db)
+	dialogID, turnID := uuid.New(), uuid.New()
+	wordsJSON, _ := json.Marshal([]string{"дом"})
+
+	mock.ExpectQuery("SELECT id, COALESCE\\(title, ''\\), input_language").
+		WithArgs(dialogID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "title", "input_language", "dialog_language", "cefr_level", "input_words", "translations", "created_at", "repair_attempts", "coverage", "coverage_flagged", "estimated_level", "parent_id",
+		}).AddRow(dialogID, "En casa", "ru", "es", "A1", wordsJSON, []byte(`{"дом":"casa"}`), time.Now(), 0, 1.0, false, "A1", nil))
+	mock.ExpectQuery("FROM dialog_turns").
+		WithArgs(dialogID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "speaker", "gender", "voice_id", "text", "audio_url", "audio_key", "audio_pending", "position", "translation",
+		}).AddRow(turnID, "Ana", "female", "", "Hola casa", "", "", false, 0, "Привет, дом"))
+	mock.ExpectQuery("FROM dialog_turn_spans").
+		WithArgs(dialogID).
+		WillReturnRows(sqlmock.NewRows([]string{"turn_id", "start_offset", "end_offset", "input_word"}).
+			AddRow(turnID, 5, 9, "дом"))
+
+	dlg, err := repo.GetByID(context.Background(), dialogID)
+	require.NoError(t, err)
+	require.Equal(t, []dialogs.VocabSpan{{Start: 5, End: 9, Word: "дом"}}, dlg.Turns[0].Spans)
+	require.Equal(t, "Привет, дом", dlg.Turns[0].Translation)
+	require.False(t, dlg.ParentID.Valid)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialogRepositoryAppendTurns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDialogRepository(db)
+	dlg := dialogs.Dialog{
+		ID:             uuid.New(),
+		Coverage:       1,
+		EstimatedLevel: "B1",
+		Turns: []dialogs.DialogTurn{
+			{ID: uuid.New(), Speaker: "Ana", Text: "Hola casa", Position: 0},
+			{ID: uuid.New(), Speaker: "Luis", Text: "Adiós", Position: 1},
+		},
+	}
+	turnsJSON, _ := json.Marshal(dlg.Turns)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM dialogs WHERE id = \\$1 FOR UPDATE").
+		WithArgs(dlg.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(dlg.ID))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM dialog_turns").
+		WithArgs(dlg.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec("UPDATE dialogs SET dialog_json").
+		WithArgs(dlg.ID, turnsJSON, 1.0, false, "B1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO dialog_turns").
+		WithArgs(dlg.Turns[1].ID, dlg.ID, "Luis", "", "", "Adiós", "", "", false, 1, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.AppendTurns(context.Background(), dlg, 1))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialogRepositoryAppendTurnsConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	dlg := dialogs.Dialog{
+		ID: uuid.New(),
+		Turns: []dialogs.DialogTurn{
+			{ID: uuid.New(), Speaker: "Ana", Text: "Hola", Position: 0},
+			{ID: uuid.New(), Speaker: "Luis", Text: "Adiós", Position: 1},
+		},
+	}
+
+	// Another continuation appended a turn after this one read the dialog.
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM dialogs WHERE id = \\$1 FOR UPDATE").
+		WithArgs(dlg.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(dlg.ID))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM dialog_turns").
+		WithArgs(dlg.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectRollback()
+
+	err = NewDialogRepository(db).AppendTurns(context.Background(), dlg, 1)
+	require.ErrorIs(t, err, dialogs.ErrConflict)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialogRepositoryUpdateKeepsRevision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDialogRepository(db)
+	dlg := dialogs.Dialog{
+		ID:           uuid.New(),
+		Title:        "En casa",
+		Translations: map[string]string{"дом": "casa"},
+		Turns: []dialogs.DialogTurn{
+			{ID: uuid.New(), Speaker: "Ana", Text: "Hola casa", Position: 0, Spans: []dialogs.VocabSpan{{Start: 5, End: 9, Word: "дом"}}},
+			{ID: uuid.New(), Speaker: "Luis", Text: "Adiós", Position: 1},
+		},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM dialogs WHERE id = \\$1 FOR UPDATE").
+		WithArgs(dlg.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(dlg.ID))
+	mock.ExpectExec("INSERT INTO dialog_revisions").
+		WithArgs(dlg.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE dialogs").
+		WithArgs(dlg.ID, dlg.Title, sqlmock.AnyArg(), sqlmock.AnyArg(), 0.0, false, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM dialog_turns WHERE dialog_id = \\$1 AND id NOT IN \\(\\$2,\\$3\\)").
+		WithArgs(dlg.ID, dlg.Turns[0].ID, dlg.Turns[1].ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM dialog_turn_spans").
+		WithArgs(dlg.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO dialog_turns .* ON CONFLICT \\(id\\) DO UPDATE").
+		WithArgs(dlg.Turns[0].ID, dlg.ID, "Ana", "", "", "Hola casa", "", "", false, 0, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO dialog_turn_spans").
+		WithArgs(dlg.Turns[0].ID, dlg.ID, 5, 9, "дом").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO dialog_turns").
+		WithArgs(dlg.Turns[1].ID, dlg.ID, "Luis", "", "", "Adiós", "", "", false, 1, "").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Update(context.Background(), dlg))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialogRepositoryGetRevision(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id := uuid.New()
+	turns := []dialogs.DialogTurn{{ID: uuid.New(), Speaker: "Ana", Text: "Hola", AudioKey: "dialogs/a/b.mp3"}}
+	turnsJSON, _ := json.Marshal(turns)
+
+	mock.ExpectQuery("FROM dialog_revisions").
+		WithArgs(id, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"dialog_id", "number", "title", "translations", "turns", "created_at"}).
+			AddRow(id, 2, "Antes", []byte(`{"дом":"casa"}`), turnsJSON, time.Now()))
+	mock.ExpectQuery("FROM dialog_revisions").
+		WithArgs(id, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"dialog_id", "number", "title", "translations", "turns", "created_at"}))
+
+	repo := NewDialogRepository(db)
+	rev, err := repo.GetRevision(context.Background(), id, 2)
+	require.NoError(t, err)
+	require.Equal(t, "Antes", rev.Title)
+	require.Equal(t, "casa", rev.Translations["дом"])
+	require.Equal(t, turns, rev.Turns)
+
+	_, err = repo.GetRevision(context.Background(), id, 3)
+	require.ErrorIs(t, err, dialogs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialogRepositorySetTurnAudio(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	dialogID := uuid.New()
+	turn := dialogs.DialogTurn{ID: uuid.New(), Speaker: "Ana", VoiceID: "v1", Text: "Hola", AudioKey: "dialogs/a/b.mp3"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE dialog_turns").
+		WithArgs(turn.ID, dialogID, "v1", "dialogs/a/b.mp3", "", false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("FROM dialog_turns").
+		WithArgs(dialogID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "speaker", "gender", "voice_id", "text", "audio_url", "audio_key", "audio_pending", "position", "translation",
+		}).AddRow(turn.ID, "Ana", "", "v1", "Hola", "", "dialogs/a/b.mp3", false, 0, ""))
+	mock.ExpectExec("UPDATE dialogs SET dialog_json").
+		WithArgs(sqlmock.AnyArg(), dialogID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	repo := NewDialogRepository(db)
+	require.NoError(t, repo.SetTurnAudio(context.Background(), dialogID, turn))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialogRepositoryListPlaceholderDialogs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	first, second := uuid.New(), uuid.New()
+	mock.ExpectQuery("audio_pending OR t.audio_url LIKE \\$1").
+		WithArgs("/static/audio/placeholder.mp3%").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(first).AddRow(second))
+
+	repo := NewDialogRepository(db)
+	ids, err := repo.ListPlaceholderDialogs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{first, second}, ids)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDialogRepositoryListFamily(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewDialogRepository(db)
+	rootID, childID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("WITH RECURSIVE ancestors").
+		WithArgs(childID).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "title", "input_language", "dialog_language", "cefr_level", "created_at", "parent_id",
+		}).AddRow(rootID, "En casa", "ru", "es", "A2", time.Now(), nil))
+
+	family, err := repo.ListFamily(context.Background(), childID)
+	require.NoError(t, err)
+	require.Len(t, family, 1)
+	require.Equal(t, rootID, family[0].ID)
+	require.Equal(t, "A2", family[0].CEFRLevel)
+	require.False(t, family[0].ParentID.Valid)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDialogRepositorySearch(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -78,8 +332,8 @@ func TestDialogRepositorySearch(t *testing.T) {
 	translationsJSON, _ := json.Marshal(map[string]string{"дом": "casa"})
 
 	rows := sqlmock.NewRows([]string{
-		"id", "title", "input_language", "dialog_language", "cefr_level", "input_words", "dialog_json", "translations", "created_at",
-	}).AddRow(uuid.New(), "Conversación sobre casa", "ru", "es", "A2", wordsJSON, turnsJSON, translationsJSON, time.Now())
+		"id", "title", "input_language", "dialog_language", "cefr_level", "input_words", "dialog_json", "translations", "created_at", "repair_attempts", "coverage", "coverage_flagged", "estimated_level",
+	}).AddRow(uuid.New(), "Conversación sobre casa", "ru", "es", "A2", wordsJSON, turnsJSON, translationsJSON, time.Now(), 1, 0.5, true, "B2")
 
 	mock.ExpectQuery("SELECT id, COALESCE\\(title, ''\\), input_language").
 		WithArgs("ru", "es", "A2", 5).
@@ -99,6 +353,10 @@ func TestDialogRepositorySearch(t *testing.T) {
 	require.Equal(t, "es", result[0].DialogLanguage)
 	require.Equal(t, "A2", result[0].CEFRLevel)
 	require.NotEmpty(t, result[0].Turns)
+	require.Equal(t, 1, result[0].RepairAttempts)
+	require.True(t, result[0].CoverageFlagged)
+	require.Equal(t, "B2", result[0].EstimatedLevel)
+	require.True(t, result[0].LevelMismatch())
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
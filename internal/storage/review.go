@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// ReviewRepository is a PostgreSQL-backed dialogs.ReviewStore.
+type ReviewRepository struct {
+	db *sql.DB
+}
+
+// NewReviewRepository creates a new review repository.
+func NewReviewRepository(db *sql.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+const reviewColumns = `id, dialog_id, word, ease, interval_days, repetitions, lapses, due_at, reviewed_at, created_at`
+
+// AddItems inserts items within a transaction, skipping words their dialog
+// already has.
+func (r *ReviewRepository) AddItems(ctx context.Context, items []dialogs.ReviewItem) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+		INSERT INTO review_items (id, dialog_id, word, ease, due_at, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6)
+		ON CONFLICT (dialog_id, word) DO NOTHING
+	`
+	for _, item := range items {
+		if _, err := tx.ExecContext(ctx, query,
+			item.ID,
+			item.DialogID,
+			item.Word,
+			item.Ease,
+			item.DueAt,
+			item.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("insert review item: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// GetItem fetches a single review item.
+func (r *ReviewRepository) GetItem(ctx context.Context, id uuid.UUID) (dialogs.ReviewItem, error) {
+	query := `SELECT ` + reviewColumns + ` FROM review_items WHERE id = $1`
+	item, err := scanReviewItem(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.ReviewItem{}, dialogs.ErrNotFound
+		}
+		return dialogs.ReviewItem{}, fmt.Errorf("select review item: %w", err)
+	}
+	return item, nil
+}
+
+// DueItems returns up to limit items due at now, most overdue first.
+func (r *ReviewRepository) DueItems(ctx context.Context, now time.Time, limit int) ([]dialogs.ReviewItem, error) {
+	query := `SELECT ` + reviewColumns + ` FROM review_items WHERE due_at <= $1 ORDER BY due_at, id LIMIT $2`
+	rows, err := r.db.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list due items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []dialogs.ReviewItem
+	for rows.Next() {
+		item, err := scanReviewItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan review item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate review items: %w", err)
+	}
+	return items, nil
+}
+
+// CountDue counts the items due before until.
+func (r *ReviewRepository) CountDue(ctx context.Context, until time.Time) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM review_items WHERE due_at < $1`, until).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count due items: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateSchedule stores the scheduling fields of item.
+func (r *ReviewRepository) UpdateSchedule(ctx context.Context, item dialogs.ReviewItem) error {
+	const query = `
+		UPDATE review_items
+		SET ease = $1, interval_days = $2, repetitions = $3, lapses = $4, due_at = $5, reviewed_at = $6
+		WHERE id = $7
+	`
+	result, err := r.db.ExecContext(ctx, query,
+		item.Ease,
+		item.IntervalDays,
+		item.Repetitions,
+		item.Lapses,
+		item.DueAt,
+		item.ReviewedAt,
+		item.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update review item: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if n == 0 {
+		return dialogs.ErrNotFound
+	}
+	return nil
+}
+
+func scanReviewItem(row rowScanner) (dialogs.ReviewItem, error) {
+	var (
+		item       dialogs.ReviewItem
+		reviewedAt sql.NullTime
+	)
+	if err := row.Scan(
+		&item.ID,
+		&item.DialogID,
+		&item.Word,
+		&item.Ease,
+		&item.IntervalDays,
+		&item.Repetitions,
+		&item.Lapses,
+		&item.DueAt,
+		&reviewedAt,
+		&item.CreatedAt,
+	); err != nil {
+		return dialogs.ReviewItem{}, err
+	}
+	item.ReviewedAt = reviewedAt.Time
+	return item, nil
+}
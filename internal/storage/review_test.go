@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestReviewRepositoryDueItems(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id, dialogID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("FROM review_items WHERE due_at <= \\$1 ORDER BY due_at").
+		WithArgs(now, 5).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "dialog_id", "word", "ease", "interval_days", "repetitions", "lapses", "due_at", "reviewed_at", "created_at",
+		}).
+			AddRow(id, dialogID, "дом", 2.5, 0, 0, 0, now, nil, now).
+			AddRow(uuid.New(), dialogID, "кошка", 2.36, 6, 2, 1, now, now, now))
+
+	items, err := NewReviewRepository(db).DueItems(context.Background(), now, 5)
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, id, items[0].ID)
+	require.True(t, items[0].ReviewedAt.IsZero())
+	require.Equal(t, 6, items[1].IntervalDays)
+	require.Equal(t, now, items[1].ReviewedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReviewRepositoryUpdateScheduleMissingItem(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	item := dialogs.ReviewItem{ID: uuid.New(), Ease: 2.6, IntervalDays: 1, Repetitions: 1, DueAt: time.Now(), ReviewedAt: time.Now()}
+	mock.ExpectExec("UPDATE review_items").
+		WithArgs(item.Ease, item.IntervalDays, item.Repetitions, item.Lapses, item.DueAt, item.ReviewedAt, item.ID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = NewReviewRepository(db).UpdateSchedule(context.Background(), item)
+	require.ErrorIs(t, err, dialogs.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
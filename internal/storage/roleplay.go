@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// RolePlayRepository is a PostgreSQL-backed dialogs.RolePlayStore.
+type RolePlayRepository struct {
+	db *sql.DB
+}
+
+// NewRolePlayRepository creates a new role-play repository.
+func NewRolePlayRepository(db *sql.DB) *RolePlayRepository {
+	return &RolePlayRepository{db: db}
+}
+
+const sessionColumns = `id, input_language, dialog_language, cefr_level, scenario, input_words, learner_speaker, partner_speaker, partner_gender, partner_voice_id, dialog_id, created_at`
+
+const messageColumns = `id, position, speaker, learner, text, correction, note, audio_url, audio_key, created_at`
+
+// CreateSession inserts a session without messages.
+func (r *RolePlayRepository) CreateSession(ctx context.Context, session dialogs.RolePlaySession) error {
+	wordsJSON, err := json.Marshal(session.InputWords)
+	if err != nil {
+		return fmt.Errorf("marshal input words: %w", err)
+	}
+
+	const query = `
+		INSERT INTO roleplay_sessions (` + sessionColumns + `)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		session.ID,
+		session.InputLanguage,
+		session.DialogLanguage,
+		session.CEFRLevel,
+		session.Scenario,
+		wordsJSON,
+		session.LearnerSpeaker,
+		session.PartnerSpeaker,
+		session.PartnerGender,
+		session.PartnerVoiceID,
+		session.DialogID,
+		session.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("insert session: %w", err)
+	}
+	return nil
+}
+
+// GetSession fetches a session with its messages in order.
+func (r *RolePlayRepository) GetSession(ctx context.Context, id uuid.UUID) (dialogs.RolePlaySession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM roleplay_sessions WHERE id = $1`
+	session, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dialogs.RolePlaySession{}, dialogs.ErrNotFound
+		}
+		return dialogs.RolePlaySession{}, fmt.Errorf("select session: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+messageColumns+` FROM roleplay_messages WHERE session_id = $1 ORDER BY position`, id)
+	if err != nil {
+		return dialogs.RolePlaySession{}, fmt.Errorf("select messages: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var msg dialogs.RolePlayMessage
+		if err := rows.Scan(
+			&msg.ID,
+			&msg.Position,
+			&msg.Speaker,
+			&msg.Learner,
+			&msg.Text,
+			&msg.Correction,
+			&msg.Note,
+			&msg.AudioURL,
+			&msg.AudioKey,
+			&msg.CreatedAt,
+		); err != nil {
+			return dialogs.RolePlaySession{}, fmt.Errorf("scan message: %w", err)
+		}
+		session.Messages = append(session.Messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return dialogs.RolePlaySession{}, fmt.Errorf("iterate messages: %w", err)
+	}
+	return session, nil
+}
+
+// ListSessions returns the newest sessions without their messages.
+func (r *RolePlayRepository) ListSessions(ctx context.Context, limit int) ([]dialogs.RolePlaySession, error) {
+	query := `SELECT ` + sessionColumns + ` FROM roleplay_sessions ORDER BY created_at DESC LIMIT $1`
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []dialogs.RolePlaySession
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// AddMessages appends messages to a session within a transaction.
+func (r *RolePlayRepository) AddMessages(ctx context.Context, sessionID uuid.UUID, messages []dialogs.RolePlayMessage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	const query = `
+		INSERT INTO roleplay_messages (session_id, ` + messageColumns + `)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+	`
+	for _, msg := range messages {
+		if _, err := tx.ExecContext(ctx, query,
+			sessionID,
+			msg.ID,
+			msg.Position,
+			msg.Speaker,
+			msg.Learner,
+			msg.Text,
+			msg.Correction,
+			msg.Note,
+			msg.AudioURL,
+			msg.AudioKey,
+			msg.CreatedAt,
+		); err != nil {
+			return fmt.Errorf("insert message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// SetSessionDialog links the dialog a session was converted into.
+func (r *RolePlayRepository) SetSessionDialog(ctx context.Context, sessionID, dialogID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE roleplay_sessions SET dialog_id = $1 WHERE id = $2`, dialogID, sessionID)
+	if err != nil {
+		return fmt.Errorf("set session dialog: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("get rows affected: %w", err)
+	}
+	if n == 0 {
+		return dialogs.ErrNotFound
+	}
+	return nil
+}
+
+func scanSession(row rowScanner) (dialogs.RolePlaySession, error) {
+	var (
+		session   dialogs.RolePlaySession
+		wordsJSON []byte
+	)
+	if err := row.Scan(
+		&session.ID,
+		&session.InputLanguage,
+		&session.DialogLanguage,
+		&session.CEFRLevel,
+		&session.Scenario,
+		&wordsJSON,
+		&session.LearnerSpeaker,
+		&session.PartnerSpeaker,
+		&session.PartnerGender,
+		&session.PartnerVoiceID,
+		&session.DialogID,
+		&session.CreatedAt,
+	); err != nil {
+		return dialogs.RolePlaySession{}, err
+	}
+	if err := json.Unmarshal(wordsJSON, &session.InputWords); err != nil {
+		return dialogs.RolePlaySession{}, fmt.Errorf("unmarshal input words: %w", err)
+	}
+	return session, nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestRolePlayRepositoryGetSession(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	id, dialogID, msgID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("FROM roleplay_sessions WHERE id").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "input_language", "dialog_language", "cefr_level", "scenario", "input_words", "learner_speaker",
+			"partner_speaker", "partner_gender", "partner_voice_id", "dialog_id", "created_at",
+		}).AddRow(id, "ru", "es", "A2", "En el mercado", []byte(`["рыба"]`), "Cliente", "Vendedora", "female", "voice-1", dialogID, now))
+	mock.ExpectQuery("FROM roleplay_messages WHERE session_id").
+		WithArgs(id).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "position", "speaker", "learner", "text", "correction", "note", "audio_url", "audio_key", "created_at",
+		}).AddRow(msgID, 0, "Cliente", true, "Quiero pescado", "Quiero pescado, por favor.", "Añade «por favor».", "", "", now))
+
+	session, err := NewRolePlayRepository(db).GetSession(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, []string{"рыба"}, session.InputWords)
+	require.True(t, session.Converted())
+	require.Equal(t, dialogID, session.DialogID.UUID)
+	require.Len(t, session.Messages, 1)
+	require.True(t, session.Messages[0].Learner)
+	require.Equal(t, "Quiero pescado, por favor.", session.Messages[0].Correction)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRolePlayRepositoryAddMessages(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sessionID := uuid.New()
+	messages := []dialogs.RolePlayMessage{
+		{ID: uuid.New(), Position: 1, Speaker: "Cliente", Learner: true, Text: "Hola", Correction: "Hola.", Note: "Punto final."},
+		{ID: uuid.New(), Position: 2, Speaker: "Vendedora", Text: "¿Qué desea?", AudioKey: "roleplay/x/y.mp3"},
+	}
+
+	mock.ExpectBegin()
+	for _, msg := range messages {
+		mock.ExpectExec("INSERT INTO roleplay_messages").
+			WithArgs(sessionID, msg.ID, msg.Position, msg.Speaker, msg.Learner, msg.Text, msg.Correction, msg.Note, msg.AudioURL, msg.AudioKey, msg.CreatedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	require.NoError(t, NewRolePlayRepository(db).AddMessages(context.Background(), sessionID, messages))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
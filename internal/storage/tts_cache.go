@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"leveltalk/internal/ttscache"
+)
+
+// TTSCacheRepository is a PostgreSQL-backed ttscache.Backend.
+type TTSCacheRepository struct {
+	db *sql.DB
+}
+
+var _ ttscache.Backend = (*TTSCacheRepository)(nil)
+
+// NewTTSCacheRepository creates a new TTS cache repository.
+func NewTTSCacheRepository(db *sql.DB) *TTSCacheRepository {
+	return &TTSCacheRepository{db: db}
+}
+
+// Get returns cached audio and bumps its last use.
+func (r *TTSCacheRepository) Get(ctx context.Context, key string) ([]byte, error) {
+	const touch = `UPDATE tts_cache SET last_used_at = NOW() WHERE key = $1 RETURNING audio`
+	var audio []byte
+	if err := r.db.QueryRowContext(ctx, touch, key).Scan(&audio); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ttscache.ErrMiss
+		}
+		return nil, fmt.Errorf("select tts cache entry: %w", err)
+	}
+	return audio, nil
+}
+
+// Put stores audio under key, refreshing an existing entry.
+func (r *TTSCacheRepository) Put(ctx context.Context, key string, audio []byte) error {
+	const upsert = `
+		INSERT INTO tts_cache (key, audio, size)
+		VALUES ($1,$2,$3)
+		ON CONFLICT (key) DO UPDATE SET audio = EXCLUDED.audio, size = EXCLUDED.size, last_used_at = NOW()
+	`
+	if _, err := r.db.ExecContext(ctx, upsert, key, audio, len(audio)); err != nil {
+		return fmt.Errorf("upsert tts cache entry: %w", err)
+	}
+	return nil
+}
+
+// Evict deletes the least recently used entries beyond maxBytes.
+func (r *TTSCacheRepository) Evict(ctx context.Context, maxBytes int64) (int, error) {
+	const evict = `
+		DELETE FROM tts_cache
+		WHERE key IN (
+			SELECT key FROM (
+				SELECT key, SUM(size) OVER (ORDER BY last_used_at DESC, key) AS running
+				FROM tts_cache
+			) ranked
+			WHERE running > $1
+		)
+	`
+	result, err := r.db.ExecContext(ctx, evict, maxBytes)
+	if err != nil {
+		return 0, fmt.Errorf("evict tts cache: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get rows affected: %w", err)
+	}
+	return int(removed), nil
+}
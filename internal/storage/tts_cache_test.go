@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/ttscache"
+)
+
+func TestTTSCacheRepositoryGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTTSCacheRepository(db)
+
+	mock.ExpectQuery("UPDATE tts_cache SET last_used_at").
+		WithArgs("hit").
+		WillReturnRows(sqlmock.NewRows([]string{"audio"}).AddRow([]byte("ID3")))
+	mock.ExpectQuery("UPDATE tts_cache SET last_used_at").
+		WithArgs("miss").
+		WillReturnRows(sqlmock.NewRows([]string{"audio"}))
+
+	audio, err := repo.Get(context.Background(), "hit")
+	require.NoError(t, err)
+	require.Equal(t, []byte("ID3"), audio)
+
+	_, err = repo.Get(context.Background(), "miss")
+	require.ErrorIs(t, err, ttscache.ErrMiss)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTTSCacheRepositoryEvict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := NewTTSCacheRepository(db)
+
+	mock.ExpectExec("DELETE FROM tts_cache").
+		WithArgs(int64(1024)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	removed, err := repo.Evict(context.Background(), 1024)
+	require.NoError(t, err)
+	require.Equal(t, 3, removed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,53 @@
+// Package textutil splits dialog text into words, the same way for
+// vocabulary coverage, exercises and level estimates.
+package textutil
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MinContentRunes is the length from which a word counts as a content word.
+// It is a rough cut that skips most function words ("el", "und", "the")
+// without a stop list per language.
+const MinContentRunes = 4
+
+// Span is the byte range of a word within a text.
+type Span struct {
+	Start, End int
+}
+
+// Words splits text into words: runs of letters, digits and combining marks
+// that may contain apostrophes and hyphens ("l'eau", "Buenos-Aires").
+// Trailing apostrophes and hyphens are not part of the word.
+func Words(text string) []Span {
+	var spans []Span
+	start := -1
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+		if word := strings.TrimRight(text[start:end], "'’-"); word != "" {
+			spans = append(spans, Span{Start: start, End: start + len(word)})
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) ||
+			(start >= 0 && (r == '\'' || r == '’' || r == '-')) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		flush(i)
+	}
+	flush(len(text))
+	return spans
+}
+
+// IsContentWord reports whether word is at least MinContentRunes long.
+func IsContentWord(word string) bool {
+	return utf8.RuneCountInString(word) >= MinContentRunes
+}
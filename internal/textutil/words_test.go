@@ -0,0 +1,25 @@
+package textutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWords(t *testing.T) {
+	text := "¿Qu'est-ce? L'eau-  niño, 42 ok'"
+	var got []string
+	for _, span := range Words(text) {
+		got = append(got, text[span.Start:span.End])
+	}
+	require.Equal(t, []string{"Qu'est-ce", "L'eau", "niño", "42", "ok"}, got)
+
+	// A decomposed ñ keeps its combining tilde inside the word.
+	require.Equal(t, []Span{{Start: 0, End: 6}}, Words("niño"))
+}
+
+func TestIsContentWord(t *testing.T) {
+	require.False(t, IsContentWord("los"))
+	require.True(t, IsContentWord("niño"))
+	require.True(t, IsContentWord("дома"))
+}
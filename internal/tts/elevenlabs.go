@@ -3,12 +3,13 @@ package tts
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -20,6 +21,9 @@ import (
 const (
 	defaultElevenLabsEndpoint = "https://api.elevenlabs.io/v1/text-to-speech/"
 	defaultElevenLabsModel    = "eleven_multilingual_v2"
+	defaultMaxRetries         = 3
+	defaultRetryBackoff       = 500 * time.Millisecond
+	maxRetryDelay             = 30 * time.Second
 )
 
 // ElevenLabsOptions configures optional client behavior.
@@ -27,19 +31,35 @@ type ElevenLabsOptions struct {
 	BaseURL    string
 	ModelID    string
 	HTTPClient *http.Client
+
+	// Limiter throttles requests; share one limiter between clients that
+	// use the same API key. Nil means unlimited.
+	Limiter *RateLimiter
+	// MaxRetries bounds retries of transient failures per turn. Negative
+	// disables retries; zero selects the default.
+	MaxRetries   int
+	RetryBackoff time.Duration // Initial backoff, doubled on every retry
+
+	Speed float64 // Speaking rate; zero keeps the provider default
 }
 
 // ElevenLabsClient implements TTSClient using ElevenLabs' API.
 type ElevenLabsClient struct {
 	logger     *slog.Logger
 	apiKey     string
-	voiceID    string
+	voiceID    string // used for turns that were not cast a voice
 	modelID    string
 	httpClient *http.Client
-	endpoint   string
+	baseURL    string
+	settings   elevenLabsVoiceSettings
+
+	limiter      *RateLimiter
+	maxRetries   int
+	retryBackoff time.Duration
 }
 
-// NewElevenLabsClient creates a new ElevenLabs TTS client.
+// NewElevenLabsClient creates a new ElevenLabs TTS client. voiceID is the
+// default voice for turns whose VoiceID is empty.
 func NewElevenLabsClient(logger *slog.Logger, apiKey, voiceID string, opts *ElevenLabsOptions) *ElevenLabsClient {
 	if opts == nil {
 		opts = &ElevenLabsOptions{}
@@ -62,85 +82,138 @@ func NewElevenLabsClient(logger *slog.Logger, apiKey, voiceID string, opts *Elev
 		baseURL = defaultElevenLabsEndpoint
 	}
 
+	maxRetries := opts.MaxRetries
+	switch {
+	case maxRetries == 0:
+		maxRetries = defaultMaxRetries
+	case maxRetries < 0:
+		maxRetries = 0
+	}
+
+	retryBackoff := opts.RetryBackoff
+	if retryBackoff <= 0 {
+		retryBackoff = defaultRetryBackoff
+	}
+
 	return &ElevenLabsClient{
 		logger:     logger,
 		apiKey:     apiKey,
 		voiceID:    voiceID,
 		modelID:    modelID,
 		httpClient: httpClient,
-		endpoint:   strings.TrimRight(baseURL, "/") + "/" + voiceID,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		settings: elevenLabsVoiceSettings{
+			Stability:       0.5,
+			SimilarityBoost: 0.75,
+			Speed:           opts.Speed,
+		},
+
+		limiter:      opts.Limiter,
+		maxRetries:   maxRetries,
+		retryBackoff: retryBackoff,
 	}
 }
 
+type elevenLabsVoiceSettings struct {
+	Stability       float64 `json:"stability"`
+	SimilarityBoost float64 `json:"similarity_boost"`
+	Speed           float64 `json:"speed,omitempty"`
+}
+
 type elevenLabsRequest struct {
-	Text          string `json:"text"`
-	ModelID       string `json:"model_id"`
-	VoiceSettings struct {
-		Stability       float64 `json:"stability"`
-		SimilarityBoost float64 `json:"similarity_boost"`
-	} `json:"voice_settings"`
+	Text          string                  `json:"text"`
+	ModelID       string                  `json:"model_id"`
+	VoiceSettings elevenLabsVoiceSettings `json:"voice_settings"`
 }
 
-// SynthesizeDialog converts each dialog turn into an audio data URL.
+// SynthesizeDialog attaches synthesized MP3 bytes to each dialog turn.
 func (c *ElevenLabsClient) SynthesizeDialog(ctx context.Context, dlg dialogs.Dialog) (dialogs.Dialog, error) {
 	c.logger.Info("starting ElevenLabs synthesis", slog.Int("turns", len(dlg.Turns)))
 
 	for i := range dlg.Turns {
-		if dlg.Turns[i].ID == uuid.Nil {
-			dlg.Turns[i].ID = uuid.New()
-		}
-
-		c.logger.Debug("synthesizing turn",
-			slog.Int("turn", i),
-			slog.String("speaker", dlg.Turns[i].Speaker),
-			slog.Int("text_length", len(dlg.Turns[i].Text)),
-		)
-
-		audio, err := c.synthesizeText(ctx, dlg.Turns[i].Text)
+		turn, err := c.SynthesizeTurn(ctx, dlg, dlg.Turns[i])
 		if err != nil {
-			c.logger.Error("elevenlabs synthesis failed",
-				slog.Int("turn", i),
-				slog.String("speaker", dlg.Turns[i].Speaker),
-				slog.String("text", dlg.Turns[i].Text),
-				slog.String("error", err.Error()),
-			)
 			return dialogs.Dialog{}, fmt.Errorf("elevenlabs synthesize turn %d: %w", i, err)
 		}
+		dlg.Turns[i] = turn
+	}
 
-		if len(audio) == 0 {
-			c.logger.Warn("elevenlabs returned empty audio",
-				slog.Int("turn", i),
-				slog.String("speaker", dlg.Turns[i].Speaker),
-			)
-			dlg.Turns[i].AudioURL = fmt.Sprintf("/static/audio/placeholder.mp3?turn=%d", i)
-			continue
-		}
+	c.logger.Info("completed ElevenLabs synthesis", slog.Int("turns", len(dlg.Turns)))
+	return dlg, nil
+}
+
+// SynthesisFingerprint describes everything besides the text that shapes the
+// audio of turn, for use in cache keys.
+func (c *ElevenLabsClient) SynthesisFingerprint(dlg dialogs.Dialog, turn dialogs.DialogTurn) string {
+	voiceID := turn.VoiceID
+	if voiceID == "" {
+		voiceID = c.voiceID
+	}
+	return fmt.Sprintf("elevenlabs|voice=%s|model=%s|stability=%g|similarity=%g|speed=%g",
+		voiceID, c.modelID, c.settings.Stability, c.settings.SimilarityBoost, c.settings.Speed)
+}
 
-		c.logger.Debug("elevenlabs synthesis succeeded",
-			slog.Int("turn", i),
-			slog.Int("audio_bytes", len(audio)),
+// SynthesizeTurn attaches synthesized MP3 bytes to a single turn, spoken by
+// the turn's cast voice or the client's default voice.
+func (c *ElevenLabsClient) SynthesizeTurn(ctx context.Context, dlg dialogs.Dialog, turn dialogs.DialogTurn) (dialogs.DialogTurn, error) {
+	if turn.ID == uuid.Nil {
+		turn.ID = uuid.New()
+	}
+	if turn.VoiceID == "" {
+		turn.VoiceID = c.voiceID
+	}
+
+	c.logger.Debug("synthesizing turn",
+		slog.Int("turn", turn.Position),
+		slog.String("speaker", turn.Speaker),
+		slog.String("voice_id", turn.VoiceID),
+		slog.Int("text_length", len(turn.Text)),
+	)
+
+	audio, err := c.synthesizeText(ctx, turn.VoiceID, turn.Text)
+	if err != nil {
+		c.logger.Error("elevenlabs synthesis failed",
+			slog.Int("turn", turn.Position),
+			slog.String("speaker", turn.Speaker),
+			slog.String("text", turn.Text),
+			slog.String("error", err.Error()),
 		)
+		return dialogs.DialogTurn{}, err
+	}
 
-		encoded := base64.StdEncoding.EncodeToString(audio)
-		dlg.Turns[i].AudioURL = "data:audio/mpeg;base64," + encoded
-		
-		c.logger.Debug("audio data URL created",
-			slog.Int("turn", i),
-			slog.Int("url_length", len(dlg.Turns[i].AudioURL)),
+	if len(audio) == 0 {
+		c.logger.Warn("elevenlabs returned empty audio",
+			slog.Int("turn", turn.Position),
+			slog.String("speaker", turn.Speaker),
 		)
+		turn.AudioURL = fmt.Sprintf("%s?turn=%d", dialogs.PlaceholderAudioPath, turn.Position)
+		return turn, nil
 	}
 
-	c.logger.Info("completed ElevenLabs synthesis", slog.Int("turns", len(dlg.Turns)))
-	return dlg, nil
+	c.logger.Debug("elevenlabs synthesis succeeded",
+		slog.Int("turn", turn.Position),
+		slog.Int("audio_bytes", len(audio)),
+	)
+
+	turn.Audio = audio
+	turn.AudioURL = ""
+	return turn, nil
 }
 
-func (c *ElevenLabsClient) synthesizeText(ctx context.Context, text string) ([]byte, error) {
+// synthesizeText requests audio for text, waiting on the shared rate limiter
+// before every attempt and retrying transient failures (network errors, 429
+// and 5xx responses) with exponential backoff or the server's Retry-After.
+func (c *ElevenLabsClient) synthesizeText(ctx context.Context, voiceID, text string) ([]byte, error) {
+	if voiceID == "" {
+		return nil, fmt.Errorf("no voice assigned and no default voice configured")
+	}
+	endpoint := c.baseURL + "/" + voiceID
+
 	reqBody := elevenLabsRequest{
-		Text:    text,
-		ModelID: c.modelID,
+		Text:          text,
+		ModelID:       c.modelID,
+		VoiceSettings: c.settings,
 	}
-	reqBody.VoiceSettings.Stability = 0.5
-	reqBody.VoiceSettings.SimilarityBoost = 0.75
 
 	payload, err := json.Marshal(reqBody)
 	if err != nil {
@@ -148,10 +221,53 @@ func (c *ElevenLabsClient) synthesizeText(ctx context.Context, text string) ([]b
 		return nil, fmt.Errorf("marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		audio, retryAfter, err := c.requestAudio(ctx, endpoint, voiceID, payload)
+		if err == nil {
+			return audio, nil
+		}
+		var transient *transientError
+		if !errors.As(err, &transient) || attempt >= c.maxRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay := retryAfter
+		if delay <= 0 {
+			delay = c.retryBackoff << attempt
+		}
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+		c.logger.Warn("retrying ElevenLabs request",
+			slog.Int("attempt", attempt+1),
+			slog.Duration("delay", delay),
+			slog.String("error", err.Error()),
+		)
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// transientError wraps failures worth retrying.
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// requestAudio performs a single synthesis request. For throttling responses
+// it also returns the delay requested through Retry-After, if any.
+func (c *ElevenLabsClient) requestAudio(ctx context.Context, endpoint, voiceID string, payload []byte) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
 	if err != nil {
 		c.logger.Error("failed to create ElevenLabs request", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("build request: %w", err)
+		return nil, 0, fmt.Errorf("build request: %w", err)
 	}
 
 	req.Header.Set("xi-api-key", c.apiKey)
@@ -159,18 +275,18 @@ func (c *ElevenLabsClient) synthesizeText(ctx context.Context, text string) ([]b
 	req.Header.Set("Accept", "audio/mpeg")
 
 	c.logger.Debug("calling ElevenLabs API",
-		slog.String("endpoint", c.endpoint),
-		slog.String("voice_id", c.voiceID),
+		slog.String("endpoint", endpoint),
+		slog.String("voice_id", voiceID),
 		slog.String("model_id", c.modelID),
 	)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		c.logger.Error("ElevenLabs HTTP request failed",
-			slog.String("endpoint", c.endpoint),
+			slog.String("endpoint", endpoint),
 			slog.String("error", err.Error()),
 		)
-		return nil, fmt.Errorf("call elevenlabs: %w", err)
+		return nil, 0, &transientError{fmt.Errorf("call elevenlabs: %w", err)}
 	}
 	defer resp.Body.Close()
 
@@ -190,9 +306,13 @@ func (c *ElevenLabsClient) synthesizeText(ctx context.Context, text string) ([]b
 		c.logger.Error("ElevenLabs API error",
 			slog.Int("status_code", resp.StatusCode),
 			slog.String("response_body", bodyStr),
-			slog.String("endpoint", c.endpoint),
+			slog.String("endpoint", endpoint),
 		)
-		return nil, fmt.Errorf("elevenlabs error: status=%d body=%s", resp.StatusCode, bodyStr)
+		err := fmt.Errorf("elevenlabs error: status=%d body=%s", resp.StatusCode, bodyStr)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, parseRetryAfter(resp.Header.Get("Retry-After")), &transientError{err}
+		}
+		return nil, 0, err
 	}
 
 	audio, err := io.ReadAll(resp.Body)
@@ -200,17 +320,35 @@ func (c *ElevenLabsClient) synthesizeText(ctx context.Context, text string) ([]b
 		c.logger.Error("failed to read ElevenLabs audio response",
 			slog.String("error", err.Error()),
 		)
-		return nil, fmt.Errorf("read audio: %w", err)
+		return nil, 0, &transientError{fmt.Errorf("read audio: %w", err)}
 	}
 
 	if len(audio) == 0 {
 		c.logger.Warn("ElevenLabs returned empty audio response")
-		return nil, fmt.Errorf("elevenlabs returned empty audio")
+		return nil, 0, fmt.Errorf("elevenlabs returned empty audio")
 	}
 
 	c.logger.Debug("successfully received audio from ElevenLabs",
 		slog.Int("audio_bytes", len(audio)),
 	)
 
-	return audio, nil
+	return audio, 0, nil
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an
+// HTTP date. It returns 0 when the header is missing or malformed.
+func parseRetryAfter(v string) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
 }
@@ -0,0 +1,105 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/config"
+	"leveltalk/internal/dialogs"
+)
+
+func testClient(t *testing.T, handler http.HandlerFunc, opts *ElevenLabsOptions) *ElevenLabsClient {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	if opts == nil {
+		opts = &ElevenLabsOptions{}
+	}
+	opts.BaseURL = srv.URL
+	opts.RetryBackoff = time.Millisecond
+	return NewElevenLabsClient(slog.New(slog.NewTextHandler(io.Discard, nil)), "key", "default-voice", opts)
+}
+
+func TestSynthesizeTurnRetriesThrottling(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/cast-voice", r.URL.Path)
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write([]byte("ID3-audio"))
+		}
+	}, nil)
+
+	turn, err := client.SynthesizeTurn(context.Background(), dialogs.Dialog{}, dialogs.DialogTurn{Text: "Hola", VoiceID: "cast-voice"})
+	require.NoError(t, err)
+	require.Equal(t, []byte("ID3-audio"), turn.Audio)
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestSynthesizeTurnDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		require.Equal(t, "/default-voice", r.URL.Path)
+		w.WriteHeader(http.StatusUnauthorized)
+	}, nil)
+
+	_, err := client.SynthesizeTurn(context.Background(), dialogs.Dialog{}, dialogs.DialogTurn{Text: "Hola"})
+	require.Error(t, err)
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestSynthesizeTurnGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+	client := testClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, &ElevenLabsOptions{MaxRetries: 2})
+
+	_, err := client.SynthesizeTurn(context.Background(), dialogs.Dialog{}, dialogs.DialogTurn{Text: "Hola"})
+	require.Error(t, err)
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestFromConfigZeroMaxRetriesDisablesRetries(t *testing.T) {
+	require.Equal(t, -1, maxRetries(config.Config{TTSMaxRetries: 0}))
+	require.Equal(t, 5, maxRetries(config.Config{TTSMaxRetries: 5}))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	require.Equal(t, 2*time.Second, parseRetryAfter("2"))
+	require.Zero(t, parseRetryAfter(""))
+	require.Zero(t, parseRetryAfter("soon"))
+
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	require.InDelta(t, time.Minute.Seconds(), parseRetryAfter(future).Seconds(), 2)
+}
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	limiter := NewRateLimiter(50, 1)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(ctx))
+	}
+	// One token is available up front; the other two arrive 20ms apart.
+	require.GreaterOrEqual(t, time.Since(start), 35*time.Millisecond)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, limiter.Wait(cancelled), context.Canceled)
+}
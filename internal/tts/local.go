@@ -0,0 +1,230 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"leveltalk/internal/dialogs"
+)
+
+// Supported local engines.
+const (
+	EnginePiper    = "piper"
+	EngineESpeakNG = "espeak-ng"
+)
+
+// LocalOptions configures LocalClient.
+type LocalOptions struct {
+	Engine string // EnginePiper or EngineESpeakNG
+	Binary string // Engine executable; defaults to the engine name on PATH
+	FFmpeg string // ffmpeg executable used to encode WAV as MP3; defaults to "ffmpeg"
+
+	// Voices maps dialog languages to a Piper model path or an espeak-ng
+	// voice name. espeak-ng falls back to the language code itself.
+	Voices map[string]string
+}
+
+// LocalClient implements TTSClient by running an offline engine per turn.
+type LocalClient struct {
+	logger *slog.Logger
+	engine string
+	binary string
+	ffmpeg string
+	voices map[string]string
+}
+
+// NewLocalClient creates a client for a locally installed Piper or espeak-ng.
+func NewLocalClient(logger *slog.Logger, opts LocalOptions) (*LocalClient, error) {
+	switch opts.Engine {
+	case EnginePiper, EngineESpeakNG:
+	default:
+		return nil, fmt.Errorf("unsupported local tts engine %q", opts.Engine)
+	}
+
+	binary := opts.Binary
+	if binary == "" {
+		binary = opts.Engine
+	}
+	if _, err := exec.LookPath(binary); err != nil {
+		return nil, fmt.Errorf("find %s: %w", opts.Engine, err)
+	}
+
+	ffmpeg := opts.FFmpeg
+	if ffmpeg == "" {
+		ffmpeg = "ffmpeg"
+	}
+	if _, err := exec.LookPath(ffmpeg); err != nil {
+		return nil, fmt.Errorf("find ffmpeg: %w", err)
+	}
+
+	voices := make(map[string]string, len(opts.Voices))
+	for lang, voice := range opts.Voices {
+		voices[strings.ToLower(lang)] = voice
+	}
+
+	return &LocalClient{
+		logger: logger,
+		engine: opts.Engine,
+		binary: binary,
+		ffmpeg: ffmpeg,
+		voices: voices,
+	}, nil
+}
+
+// SynthesizeDialog attaches synthesized MP3 bytes to each dialog turn.
+func (c *LocalClient) SynthesizeDialog(ctx context.Context, dlg dialogs.Dialog) (dialogs.Dialog, error) {
+	for i := range dlg.Turns {
+		turn, err := c.SynthesizeTurn(ctx, dlg, dlg.Turns[i])
+		if err != nil {
+			return dialogs.Dialog{}, fmt.Errorf("%s synthesize turn %d: %w", c.engine, i, err)
+		}
+		dlg.Turns[i] = turn
+	}
+	return dlg, nil
+}
+
+// SynthesizeTurn runs the engine for one turn and encodes its WAV output as
+// MP3. A cast VoiceID is passed to the engine as is, so a voice catalog may
+// list Piper model paths or espeak-ng voice names.
+func (c *LocalClient) SynthesizeTurn(ctx context.Context, dlg dialogs.Dialog, turn dialogs.DialogTurn) (dialogs.DialogTurn, error) {
+	if turn.ID == uuid.Nil {
+		turn.ID = uuid.New()
+	}
+	if turn.VoiceID == "" {
+		voice, err := c.voiceFor(dlg.DialogLanguage, turn.Gender)
+		if err != nil {
+			return dialogs.DialogTurn{}, err
+		}
+		turn.VoiceID = voice
+	}
+
+	wav, err := c.runEngine(ctx, turn.VoiceID, turn.Text)
+	if err != nil {
+		c.logger.Error("local tts synthesis failed",
+			slog.String("engine", c.engine),
+			slog.Int("turn", turn.Position),
+			slog.String("voice", turn.VoiceID),
+			slog.String("error", err.Error()),
+		)
+		return dialogs.DialogTurn{}, err
+	}
+
+	audio, err := c.encodeMP3(ctx, wav)
+	if err != nil {
+		return dialogs.DialogTurn{}, err
+	}
+
+	c.logger.Debug("local tts synthesis succeeded",
+		slog.String("engine", c.engine),
+		slog.Int("turn", turn.Position),
+		slog.Int("audio_bytes", len(audio)),
+	)
+
+	turn.Audio = audio
+	turn.AudioURL = ""
+	return turn, nil
+}
+
+// SynthesisFingerprint describes the engine and voice for cache keys. Turns
+// without a cast voice are keyed by the voice SynthesizeTurn will pick for
+// the dialog's language and the speaker's gender.
+func (c *LocalClient) SynthesisFingerprint(dlg dialogs.Dialog, turn dialogs.DialogTurn) string {
+	voice := turn.VoiceID
+	if voice == "" {
+		// A failed lookup fails synthesis too, so nothing is cached under it.
+		voice, _ = c.voiceFor(dlg.DialogLanguage, turn.Gender)
+	}
+	return fmt.Sprintf("local|engine=%s|voice=%s", c.engine, voice)
+}
+
+func (c *LocalClient) voiceFor(language, gender string) (string, error) {
+	language = strings.ToLower(language)
+	if voice, ok := c.voices[language]; ok {
+		return voice, nil
+	}
+	if c.engine == EnginePiper {
+		return "", fmt.Errorf("no piper model configured for language %q", language)
+	}
+	// espeak-ng ships voices named after languages; variants give some
+	// distinction between speakers.
+	switch gender {
+	case "female":
+		return language + "+f3", nil
+	case "male":
+		return language + "+m3", nil
+	default:
+		return language, nil
+	}
+}
+
+func (c *LocalClient) runEngine(ctx context.Context, voice, text string) ([]byte, error) {
+	if c.engine == EngineESpeakNG {
+		cmd := exec.CommandContext(ctx, c.binary, "-v", voice, "--stdout", "--stdin")
+		cmd.Stdin = strings.NewReader(text)
+		return run(cmd)
+	}
+
+	// Piper writes WAV files only to paths, not to stdout.
+	dir, err := os.MkdirTemp("", "leveltalk-piper-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := filepath.Join(dir, "turn.wav")
+	cmd := exec.CommandContext(ctx, c.binary, "--model", voice, "--output_file", out)
+	cmd.Stdin = strings.NewReader(text)
+	if _, err := run(cmd); err != nil {
+		return nil, err
+	}
+	wav, err := os.ReadFile(out)
+	if err != nil {
+		return nil, fmt.Errorf("read piper output: %w", err)
+	}
+	return wav, nil
+}
+
+func (c *LocalClient) encodeMP3(ctx context.Context, wav []byte) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, c.ffmpeg,
+		"-hide_banner", "-loglevel", "error",
+		"-f", "wav", "-i", "pipe:0",
+		"-codec:a", "libmp3lame", "-q:a", "4",
+		"-f", "mp3", "pipe:1",
+	)
+	cmd.Stdin = bytes.NewReader(wav)
+	audio, err := run(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("encode mp3: %w", err)
+	}
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("encode mp3: ffmpeg produced no output")
+	}
+	return audio, nil
+}
+
+// run executes cmd and returns its stdout, folding stderr into errors.
+func run(cmd *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("run %s: %w: %s", filepath.Base(cmd.Path), err, truncate(stderr.String(), 512))
+	}
+	return stdout.Bytes(), nil
+}
+
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "…"
+}
@@ -0,0 +1,96 @@
+package tts
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+	"leveltalk/internal/ttscache"
+)
+
+// writeScript installs an executable shell script standing in for an engine.
+func writeScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body), 0o755))
+	return path
+}
+
+func newFakeLocalClient(t *testing.T, engine, engineScript string, voices map[string]string) *LocalClient {
+	t.Helper()
+	dir := t.TempDir()
+	binary := writeScript(t, dir, engine, engineScript)
+	// The fake encoder tags its input so tests can see it ran.
+	ffmpeg := writeScript(t, dir, "ffmpeg", "printf 'MP3:'; cat\n")
+
+	client, err := NewLocalClient(slog.New(slog.NewTextHandler(io.Discard, nil)), LocalOptions{
+		Engine: engine,
+		Binary: binary,
+		FFmpeg: ffmpeg,
+		Voices: voices,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+func TestLocalClientESpeak(t *testing.T) {
+	// Echo the voice and the text read from stdin as the "WAV".
+	client := newFakeLocalClient(t, EngineESpeakNG, `printf 'WAV[%s]' "$2"; cat`+"\n", nil)
+
+	dlg := dialogs.Dialog{DialogLanguage: "es"}
+	turn, err := client.SynthesizeTurn(context.Background(), dlg, dialogs.DialogTurn{Text: "Hola", Gender: "female"})
+	require.NoError(t, err)
+	require.Equal(t, "es+f3", turn.VoiceID)
+	require.Equal(t, "MP3:WAV[es+f3]Hola", string(turn.Audio))
+}
+
+func TestLocalClientPiper(t *testing.T) {
+	// Piper takes --model M --output_file F and reads the text from stdin.
+	client := newFakeLocalClient(t, EnginePiper, `{ printf 'WAV[%s]' "$2"; cat; } > "$4"`+"\n", map[string]string{"FI": "/models/fi.onnx"})
+
+	dlg := dialogs.Dialog{DialogLanguage: "fi"}
+	turn, err := client.SynthesizeTurn(context.Background(), dlg, dialogs.DialogTurn{Text: "Moi"})
+	require.NoError(t, err)
+	require.Equal(t, "/models/fi.onnx", turn.VoiceID)
+	require.Equal(t, "MP3:WAV[/models/fi.onnx]Moi", string(turn.Audio))
+
+	_, err = client.SynthesizeTurn(context.Background(), dialogs.Dialog{DialogLanguage: "ru"}, dialogs.DialogTurn{Text: "Привет"})
+	require.ErrorContains(t, err, "no piper model")
+}
+
+func TestLocalClientCachesPerResolvedVoice(t *testing.T) {
+	client := newFakeLocalClient(t, EngineESpeakNG, `printf 'WAV[%s]' "$2"; cat`+"\n", nil)
+	backend, err := ttscache.NewDirBackend(t.TempDir())
+	require.NoError(t, err)
+	cache := ttscache.New(client, backend, nil)
+	ctx := context.Background()
+
+	es := dialogs.Dialog{DialogLanguage: "es"}
+	female, err := cache.SynthesizeTurn(ctx, es, dialogs.DialogTurn{Text: "Gracias.", Gender: "female"})
+	require.NoError(t, err)
+	male, err := cache.SynthesizeTurn(ctx, es, dialogs.DialogTurn{Text: "Gracias.", Gender: "male"})
+	require.NoError(t, err)
+	german, err := cache.SynthesizeTurn(ctx, dialogs.Dialog{DialogLanguage: "de"}, dialogs.DialogTurn{Text: "Gracias.", Gender: "female"})
+	require.NoError(t, err)
+	again, err := cache.SynthesizeTurn(ctx, es, dialogs.DialogTurn{Text: "Gracias.", Gender: "male"})
+	require.NoError(t, err)
+
+	require.Equal(t, "MP3:WAV[es+f3]Gracias.", string(female.Audio))
+	require.Equal(t, "MP3:WAV[es+m3]Gracias.", string(male.Audio))
+	require.Equal(t, "MP3:WAV[de+f3]Gracias.", string(german.Audio))
+	require.Equal(t, male.Audio, again.Audio)
+	require.Equal(t, ttscache.Stats{Hits: 1, Misses: 3}, cache.Stats())
+}
+
+func TestLocalClientReportsEngineErrors(t *testing.T) {
+	client := newFakeLocalClient(t, EngineESpeakNG, "echo 'unknown voice' >&2; exit 1\n", nil)
+
+	_, err := client.SynthesizeTurn(context.Background(), dialogs.Dialog{DialogLanguage: "xx"}, dialogs.DialogTurn{Text: "?"})
+	require.ErrorContains(t, err, "unknown voice")
+}
@@ -0,0 +1,43 @@
+package tts
+
+import (
+	"fmt"
+	"log/slog"
+
+	"leveltalk/internal/config"
+	"leveltalk/internal/dialogs"
+)
+
+// FromConfig builds the TTSClient selected by cfg.TTSProvider.
+func FromConfig(logger *slog.Logger, cfg config.Config) (dialogs.TTSClient, error) {
+	switch cfg.TTSProvider {
+	case "elevenlabs":
+		return NewElevenLabsClient(logger, cfg.ElevenLabsAPIKey, cfg.ElevenLabsVoice, &ElevenLabsOptions{
+			Limiter:    NewRateLimiter(cfg.TTSRateLimit, cfg.TTSRateBurst),
+			MaxRetries: maxRetries(cfg),
+			Speed:      cfg.ElevenLabsSpeed,
+		}), nil
+	case "local":
+		client, err := NewLocalClient(logger, LocalOptions{
+			Engine: cfg.LocalTTS.Engine,
+			Binary: cfg.LocalTTS.Binary,
+			FFmpeg: cfg.LocalTTS.FFmpeg,
+			Voices: cfg.LocalTTS.Voices,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("init local tts: %w", err)
+		}
+		return client, nil
+	default:
+		return NewStubClient(), nil
+	}
+}
+
+// maxRetries converts TTS_MAX_RETRIES, where zero disables retries, to the
+// MaxRetries option, where zero selects the default.
+func maxRetries(cfg config.Config) int {
+	if cfg.TTSMaxRetries == 0 {
+		return -1
+	}
+	return cfg.TTSMaxRetries
+}
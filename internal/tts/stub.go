@@ -20,10 +20,7 @@ func NewStubClient() *StubClient {
 // SynthesizeDialog assigns placeholder audio URLs.
 func (s *StubClient) SynthesizeDialog(ctx context.Context, dlg dialogs.Dialog) (dialogs.Dialog, error) {
 	for i := range dlg.Turns {
-		if dlg.Turns[i].ID == uuid.Nil {
-			dlg.Turns[i].ID = uuid.New()
-		}
-		dlg.Turns[i].AudioURL = fmt.Sprintf("/static/audio/placeholder.mp3?turn=%d", dlg.Turns[i].Position)
+		dlg.Turns[i], _ = s.SynthesizeTurn(ctx, dlg, dlg.Turns[i])
 	}
 
 	// TODO: Replace with ElevenLabs API call.
@@ -33,3 +30,12 @@ func (s *StubClient) SynthesizeDialog(ctx context.Context, dlg dialogs.Dialog) (
 
 	return dlg, nil
 }
+
+// SynthesizeTurn assigns a placeholder audio URL to a single turn.
+func (s *StubClient) SynthesizeTurn(ctx context.Context, dlg dialogs.Dialog, turn dialogs.DialogTurn) (dialogs.DialogTurn, error) {
+	if turn.ID == uuid.Nil {
+		turn.ID = uuid.New()
+	}
+	turn.AudioURL = fmt.Sprintf("%s?turn=%d", dialogs.PlaceholderAudioPath, turn.Position)
+	return turn, nil
+}
@@ -0,0 +1,197 @@
+// Package ttscache wraps any dialogs.TTSClient with a content-addressed cache
+// so identical utterances are synthesized only once.
+package ttscache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync/atomic"
+
+	"leveltalk/internal/dialogs"
+)
+
+// ErrMiss is returned by a Backend that holds no audio for a key.
+var ErrMiss = errors.New("tts cache miss")
+
+// Backend stores cached audio by key.
+type Backend interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, audio []byte) error
+	// Evict drops the least recently used entries until at most maxBytes
+	// of audio remain, returning the number of entries removed.
+	Evict(ctx context.Context, maxBytes int64) (int, error)
+}
+
+// Fingerprinter is implemented by TTS clients to describe every setting
+// besides the text that shapes a turn's audio: voice, model, voice settings,
+// speed. It gets the dialog so that clients which pick a voice per language
+// or gender key the voice they will use. Clients that do not implement it are
+// keyed by type and voice only.
+type Fingerprinter interface {
+	SynthesisFingerprint(dlg dialogs.Dialog, turn dialogs.DialogTurn) string
+}
+
+// Options configures a Client.
+type Options struct {
+	Logger   *slog.Logger
+	MaxBytes int64 // Size Evict trims the backend to; zero disables eviction
+}
+
+// Stats counts cache lookups since the Client was created.
+type Stats struct {
+	Hits   int64
+	Misses int64
+	Errors int64 // Backend failures; the lookup is then treated as a miss
+}
+
+// HitRatio returns the share of lookups served from the cache.
+func (s Stats) HitRatio() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+	return 0
+}
+
+// Client is a caching dialogs.TTSClient decorator.
+type Client struct {
+	inner    dialogs.TTSClient
+	backend  Backend
+	logger   *slog.Logger
+	maxBytes int64
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+var (
+	_ dialogs.TTSClient       = (*Client)(nil)
+	_ dialogs.TurnSynthesizer = (*Client)(nil)
+)
+
+// New wraps inner with a cache stored in backend.
+func New(inner dialogs.TTSClient, backend Backend, opts *Options) *Client {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	return &Client{
+		inner:    inner,
+		backend:  backend,
+		logger:   logger,
+		maxBytes: opts.MaxBytes,
+	}
+}
+
+// SynthesizeDialog synthesizes every turn through the cache.
+func (c *Client) SynthesizeDialog(ctx context.Context, dlg dialogs.Dialog) (dialogs.Dialog, error) {
+	for i := range dlg.Turns {
+		turn, err := c.SynthesizeTurn(ctx, dlg, dlg.Turns[i])
+		if err != nil {
+			return dialogs.Dialog{}, fmt.Errorf("synthesize turn %d: %w", i, err)
+		}
+		dlg.Turns[i] = turn
+	}
+	return dlg, nil
+}
+
+// SynthesizeTurn serves the turn's audio from the cache, or synthesizes it
+// with the wrapped client and caches the result.
+func (c *Client) SynthesizeTurn(ctx context.Context, dlg dialogs.Dialog, turn dialogs.DialogTurn) (dialogs.DialogTurn, error) {
+	key := c.Key(dlg, turn)
+
+	audio, err := c.backend.Get(ctx, key)
+	switch {
+	case err == nil:
+		c.hits.Add(1)
+		turn.Audio = audio
+		turn.AudioURL = ""
+		return turn, nil
+	case !errors.Is(err, ErrMiss):
+		c.errors.Add(1)
+		c.logger.Warn("tts cache lookup failed", slog.String("key", key), slog.String("error", err.Error()))
+	}
+	c.misses.Add(1)
+
+	synthesized, err := c.synthesize(ctx, dlg, turn)
+	if err != nil {
+		return dialogs.DialogTurn{}, err
+	}
+	// Placeholders carry no bytes and are not worth remembering.
+	if len(synthesized.Audio) > 0 {
+		if err := c.backend.Put(ctx, key, synthesized.Audio); err != nil {
+			c.errors.Add(1)
+			c.logger.Warn("tts cache store failed", slog.String("key", key), slog.String("error", err.Error()))
+		}
+	}
+	return synthesized, nil
+}
+
+func (c *Client) synthesize(ctx context.Context, dlg dialogs.Dialog, turn dialogs.DialogTurn) (dialogs.DialogTurn, error) {
+	if perTurn, ok := c.inner.(dialogs.TurnSynthesizer); ok {
+		return perTurn.SynthesizeTurn(ctx, dlg, turn)
+	}
+	dlg.Turns = []dialogs.DialogTurn{turn}
+	withAudio, err := c.inner.SynthesizeDialog(ctx, dlg)
+	if err != nil {
+		return dialogs.DialogTurn{}, err
+	}
+	if len(withAudio.Turns) != 1 {
+		return dialogs.DialogTurn{}, fmt.Errorf("tts client returned %d turns for 1", len(withAudio.Turns))
+	}
+	return withAudio.Turns[0], nil
+}
+
+// Key returns the cache key of turn in dlg: a SHA-256 over its text and the
+// wrapped client's fingerprint for it.
+func (c *Client) Key(dlg dialogs.Dialog, turn dialogs.DialogTurn) string {
+	fingerprint := fmt.Sprintf("%T|voice=%s", c.inner, turn.VoiceID)
+	if fp, ok := c.inner.(Fingerprinter); ok {
+		fingerprint = fp.SynthesisFingerprint(dlg, turn)
+	}
+	sum := sha256.Sum256([]byte(fingerprint + "\x00" + turn.Text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Stats returns the lookup counters.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+}
+
+// Evict trims the backend to the configured size.
+func (c *Client) Evict(ctx context.Context) (int, error) {
+	if c.maxBytes <= 0 {
+		return 0, nil
+	}
+	removed, err := c.backend.Evict(ctx, c.maxBytes)
+	if err != nil {
+		return 0, fmt.Errorf("evict tts cache: %w", err)
+	}
+	c.logger.Info("tts cache evicted", slog.Int("removed", removed))
+	return removed, nil
+}
+
+// LogStats logs the lookup counters and the hit ratio.
+func (c *Client) LogStats() {
+	stats := c.Stats()
+	c.logger.Info("tts cache stats",
+		slog.Int64("hits", stats.Hits),
+		slog.Int64("misses", stats.Misses),
+		slog.Int64("errors", stats.Errors),
+		slog.Float64("hit_ratio", stats.HitRatio()),
+	)
+}
@@ -0,0 +1,99 @@
+package ttscache
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+// countingTTS returns the turn text as audio and counts calls.
+type countingTTS struct {
+	calls int
+}
+
+func (c *countingTTS) SynthesizeDialog(ctx context.Context, dlg dialogs.Dialog) (dialogs.Dialog, error) {
+	for i := range dlg.Turns {
+		c.calls++
+		dlg.Turns[i].Audio = []byte("audio:" + dlg.Turns[i].Text)
+	}
+	return dlg, nil
+}
+
+func (c *countingTTS) SynthesisFingerprint(dlg dialogs.Dialog, turn dialogs.DialogTurn) string {
+	return "counting|" + turn.VoiceID
+}
+
+func TestClientServesRepeatedUtterancesFromCache(t *testing.T) {
+	backend, err := NewDirBackend(t.TempDir())
+	require.NoError(t, err)
+	inner := &countingTTS{}
+	client := New(inner, backend, nil)
+	ctx := context.Background()
+
+	dlg := dialogs.Dialog{Turns: []dialogs.DialogTurn{
+		{Text: "Hola, ¿qué tal?", VoiceID: "ana"},
+		{Text: "Bien, gracias.", VoiceID: "luis"},
+		{Text: "Hola, ¿qué tal?", VoiceID: "ana"},
+		{Text: "Hola, ¿qué tal?", VoiceID: "luis"},
+	}}
+	out, err := client.SynthesizeDialog(ctx, dlg)
+	require.NoError(t, err)
+
+	require.Equal(t, 3, inner.calls, "the repeated line in the same voice is synthesized once")
+	require.Equal(t, []byte("audio:Hola, ¿qué tal?"), out.Turns[2].Audio)
+	require.Equal(t, Stats{Hits: 1, Misses: 3}, client.Stats())
+}
+
+func TestClientLogsStatsWithoutEviction(t *testing.T) {
+	backend, err := NewDirBackend(t.TempDir())
+	require.NoError(t, err)
+	var logs bytes.Buffer
+	client := New(&countingTTS{}, backend, &Options{Logger: slog.New(slog.NewTextHandler(&logs, nil))})
+	ctx := context.Background()
+
+	dlg := dialogs.Dialog{Turns: []dialogs.DialogTurn{{Text: "Hola"}, {Text: "Hola"}}}
+	_, err = client.SynthesizeDialog(ctx, dlg)
+	require.NoError(t, err)
+
+	removed, err := client.Evict(ctx)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+	client.LogStats()
+	require.Contains(t, logs.String(), "hits=1 misses=1 errors=0 hit_ratio=0.5")
+}
+
+func TestDirBackendEvictsLeastRecentlyUsed(t *testing.T) {
+	root := t.TempDir()
+	backend, err := NewDirBackend(root)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	old := time.Now().Add(-time.Hour)
+	for i, key := range []string{"aaa111", "bbb222", "ccc333"} {
+		require.NoError(t, backend.Put(ctx, key, []byte("0123456789")))
+		at := old.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(filepath.Join(root, key[:2], key+".audio"), at, at))
+	}
+	// Reading the oldest entry makes it the most recently used.
+	_, err = backend.Get(ctx, "aaa111")
+	require.NoError(t, err)
+
+	removed, err := backend.Evict(ctx, 20)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	_, err = backend.Get(ctx, "bbb222")
+	require.ErrorIs(t, err, ErrMiss)
+	_, err = backend.Get(ctx, "aaa111")
+	require.NoError(t, err)
+	_, err = backend.Get(ctx, "ccc333")
+	require.NoError(t, err)
+}
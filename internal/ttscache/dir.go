@@ -0,0 +1,130 @@
+package ttscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirBackend keeps cached audio as files below a root directory. A file's
+// modification time records its last use, which drives LRU eviction.
+type DirBackend struct {
+	root string
+}
+
+var _ Backend = (*DirBackend)(nil)
+
+// NewDirBackend creates root if needed and returns a backend rooted there.
+func NewDirBackend(root string) (*DirBackend, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("create tts cache dir: %w", err)
+	}
+	return &DirBackend{root: root}, nil
+}
+
+func (b *DirBackend) path(key string) (string, error) {
+	if len(key) < 3 || strings.ContainsAny(key, `/\.`) {
+		return "", fmt.Errorf("invalid tts cache key %q", key)
+	}
+	return filepath.Join(b.root, key[:2], key+".audio"), nil
+}
+
+// Get reads the audio stored under key and marks it as recently used.
+func (b *DirBackend) Get(ctx context.Context, key string) ([]byte, error) {
+	path, err := b.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrMiss
+		}
+		return nil, fmt.Errorf("read tts cache entry: %w", err)
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return data, nil
+}
+
+// Put writes audio under key atomically.
+func (b *DirBackend) Put(ctx context.Context, key string, audio []byte) error {
+	path, err := b.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create tts cache shard: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(audio); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write tts cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close tts cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("rename tts cache entry: %w", err)
+	}
+	return nil
+}
+
+// Evict removes the least recently used files until at most maxBytes remain.
+func (b *DirBackend) Evict(ctx context.Context, maxBytes int64) (int, error) {
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var entries []entry
+	err := filepath.WalkDir(b.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".audio") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("scan tts cache: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.After(entries[j].modTime) })
+
+	var total int64
+	removed := 0
+	for _, e := range entries {
+		total += e.size
+		if total <= maxBytes {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, fmt.Errorf("remove tts cache entry: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"leveltalk/internal/dialogs"
 	"leveltalk/internal/i18n"
 )
 
@@ -29,6 +31,10 @@ func ParseTemplates() (*template.Template, error) {
 			return template.URL(u)
 		},
 		"dialogName": dialogName,
+		"highlight":  highlight,
+		"percent": func(v float64) string {
+			return fmt.Sprintf("%.0f%%", v*100)
+		},
 		"t": func(lang, key string) template.HTML {
 			return template.HTML(i18n.Get(lang, key))
 		},
@@ -108,6 +114,28 @@ func shortID(v any) string {
 	}
 }
 
+// highlight renders text with every vocabulary span wrapped in a <mark> whose
+// tooltip names the input word. Spans that overlap an earlier one or do not
+// fit the text are skipped, so stale offsets never break the page.
+func highlight(text string, spans []dialogs.VocabSpan) template.HTML {
+	var sb strings.Builder
+	cursor := 0
+	for _, span := range spans {
+		if span.Start < cursor || span.End <= span.Start || span.End > len(text) ||
+			!utf8.ValidString(text[span.Start:span.End]) {
+			continue
+		}
+		sb.WriteString(template.HTMLEscapeString(text[cursor:span.Start]))
+		fmt.Fprintf(&sb, `<mark class="vocab-mark" title="%s">%s</mark>`,
+			template.HTMLEscapeString(span.Word),
+			template.HTMLEscapeString(text[span.Start:span.End]),
+		)
+		cursor = span.End
+	}
+	sb.WriteString(template.HTMLEscapeString(text[cursor:]))
+	return template.HTML(sb.String())
+}
+
 // dialogName generates a concise name for a dialog.
 // Uses the stored title if available, otherwise falls back to metadata-based name.
 func dialogName(title, inputLang, dialogLang, cefr string, inputWords []string) string {
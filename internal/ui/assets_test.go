@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func TestHighlight(t *testing.T) {
+	text := "El niño <3 come pequeñas piñas."
+	require.Equal(t, 5, len("niño"))
+
+	got := highlight(text, []dialogs.VocabSpan{
+		{Start: 3, End: 8, Word: "ребёнок"},
+		// Overlaps the first span and is skipped.
+		{Start: 6, End: 12, Word: "x"},
+		// Starts inside the two-byte ñ of "pequeñas" and is skipped.
+		{Start: 23, End: 27, Word: "y"},
+		{Start: 27, End: 33, Word: "ананас"},
+		// Past the end of the text.
+		{Start: 33, End: 99, Word: "z"},
+	})
+	require.Equal(t, `El <mark class="vocab-mark" title="ребёнок">niño</mark> &lt;3 come pequeñas `+
+		`<mark class="vocab-mark" title="ананас">piñas</mark>.`, string(got))
+}
+
+func TestHighlightWithoutSpans(t *testing.T) {
+	require.Equal(t, "a &amp; b", string(highlight("a & b", nil)))
+}
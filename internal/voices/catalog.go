@@ -0,0 +1,168 @@
+// Package voices casts TTS voices onto dialog speakers from a configurable
+// catalog keyed by language and gender or persona.
+package voices
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"sort"
+	"strings"
+
+	"leveltalk/internal/dialogs"
+)
+
+// Voice is a single TTS voice available for casting.
+type Voice struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Language string `json:"language,omitempty"` // ISO code such as "es" or "es-MX"; empty or "*" for multilingual voices
+	Gender   string `json:"gender,omitempty"`   // "female" or "male"
+	Persona  string `json:"persona,omitempty"`  // Optional free-form tag such as "child" or "elderly"
+}
+
+type catalogFile struct {
+	Default string  `json:"default"`
+	Voices  []Voice `json:"voices"`
+}
+
+// Catalog implements dialogs.VoiceCaster.
+type Catalog struct {
+	voices       []Voice
+	defaultVoice string
+}
+
+var _ dialogs.VoiceCaster = (*Catalog)(nil)
+
+// NewCatalog builds a catalog from voices. defaultVoice is used for speakers
+// no catalog entry fits and may be empty to let the TTS client decide.
+func NewCatalog(voices []Voice, defaultVoice string) (*Catalog, error) {
+	normalized := make([]Voice, 0, len(voices))
+	for i, v := range voices {
+		v.ID = strings.TrimSpace(v.ID)
+		if v.ID == "" {
+			return nil, fmt.Errorf("voice %d: id is required", i)
+		}
+		v.Language = strings.ToLower(strings.TrimSpace(v.Language))
+		if v.Language == "*" {
+			v.Language = ""
+		}
+		v.Gender = strings.ToLower(strings.TrimSpace(v.Gender))
+		v.Persona = strings.ToLower(strings.TrimSpace(v.Persona))
+		normalized = append(normalized, v)
+	}
+	// Sorting makes casting independent of the order voices were configured in.
+	sort.Slice(normalized, func(i, j int) bool { return normalized[i].ID < normalized[j].ID })
+
+	return &Catalog{voices: normalized, defaultVoice: strings.TrimSpace(defaultVoice)}, nil
+}
+
+// LoadCatalog reads a JSON catalog of the form
+// {"default":"voice-id","voices":[{"id":"...","language":"es","gender":"female"}]}.
+// fallback is used as the default voice when the file does not name one.
+func LoadCatalog(path, fallback string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read voice catalog: %w", err)
+	}
+	var file catalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse voice catalog: %w", err)
+	}
+	if len(file.Voices) == 0 {
+		return nil, errors.New("voice catalog has no voices")
+	}
+	if file.Default == "" {
+		file.Default = fallback
+	}
+	return NewCatalog(file.Voices, file.Default)
+}
+
+// CastVoices assigns a voice to every speaker of dlg. Speakers are cast in
+// order of appearance, each preferring an unused voice that matches the
+// dialog language and the speaker's gender hint, then a multilingual voice
+// with that hint, then any voice of the language, then any multilingual
+// voice. Among equally good voices the pick is derived from a hash of the
+// dialog ID and speaker name, so the result is stable for a given dialog
+// while different dialogs get some variety.
+func (c *Catalog) CastVoices(dlg dialogs.Dialog) map[string]string {
+	language := strings.ToLower(strings.TrimSpace(dlg.DialogLanguage))
+
+	var speakers []string
+	hints := make(map[string]string)
+	for _, turn := range dlg.Turns {
+		if _, seen := hints[turn.Speaker]; !seen {
+			speakers = append(speakers, turn.Speaker)
+			hints[turn.Speaker] = ""
+		}
+		if hints[turn.Speaker] == "" {
+			hints[turn.Speaker] = strings.ToLower(strings.TrimSpace(turn.Gender))
+		}
+	}
+
+	cast := make(map[string]string, len(speakers))
+	used := make(map[string]bool)
+	for _, speaker := range speakers {
+		voice := c.pick(dlg.ID.String()+"\x00"+speaker, language, hints[speaker], used)
+		cast[speaker] = voice
+		if voice != "" {
+			used[voice] = true
+		}
+	}
+	return cast
+}
+
+func (c *Catalog) pick(seed, language, hint string, used map[string]bool) string {
+	tiers := [][]Voice{
+		c.filter(func(v Voice) bool { return speaksLanguage(v, language) && v.Language != "" && fitsHint(v, hint) }),
+		c.filter(func(v Voice) bool { return v.Language == "" && fitsHint(v, hint) }),
+		c.filter(func(v Voice) bool { return speaksLanguage(v, language) && v.Language != "" }),
+		c.filter(func(v Voice) bool { return v.Language == "" }),
+	}
+
+	// Prefer a voice nobody else in the dialog uses yet.
+	for _, tier := range tiers {
+		var unused []Voice
+		for _, v := range tier {
+			if !used[v.ID] {
+				unused = append(unused, v)
+			}
+		}
+		if len(unused) > 0 {
+			return choose(unused, seed)
+		}
+	}
+	// Every fitting voice is taken; share one rather than fall back further.
+	for _, tier := range tiers {
+		if len(tier) > 0 {
+			return choose(tier, seed)
+		}
+	}
+	return c.defaultVoice
+}
+
+func (c *Catalog) filter(keep func(Voice) bool) []Voice {
+	var out []Voice
+	for _, v := range c.voices {
+		if keep(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func speaksLanguage(v Voice, language string) bool {
+	return v.Language == language || strings.HasPrefix(v.Language, language+"-")
+}
+
+func fitsHint(v Voice, hint string) bool {
+	return hint == "" || v.Gender == hint || v.Persona == hint
+}
+
+func choose(voices []Voice, seed string) string {
+	h := fnv.New32a()
+	h.Write([]byte(seed))
+	return voices[h.Sum32()%uint32(len(voices))].ID
+}
@@ -0,0 +1,76 @@
+package voices
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+func testDialog(language string) dialogs.Dialog {
+	return dialogs.Dialog{
+		ID:             uuid.MustParse("6f1c7a52-8f0e-4d8a-9a65-1c2e3b4d5f60"),
+		DialogLanguage: language,
+		Turns: []dialogs.DialogTurn{
+			{Speaker: "Ana", Gender: "female"},
+			{Speaker: "Luis", Gender: "male"},
+			{Speaker: "Ana", Gender: "female"},
+		},
+	}
+}
+
+func TestCastVoicesPrefersLanguageAndGender(t *testing.T) {
+	catalog, err := NewCatalog([]Voice{
+		{ID: "es-f", Language: "es", Gender: "female"},
+		{ID: "es-m", Language: "es", Gender: "male"},
+		{ID: "fi-f", Language: "fi", Gender: "female"},
+		{ID: "multi-m", Language: "*", Gender: "male"},
+	}, "fallback")
+	require.NoError(t, err)
+
+	cast := catalog.CastVoices(testDialog("es"))
+	require.Equal(t, map[string]string{"Ana": "es-f", "Luis": "es-m"}, cast)
+
+	// Finnish has no male voice, so Luis gets the multilingual one.
+	cast = catalog.CastVoices(testDialog("fi"))
+	require.Equal(t, map[string]string{"Ana": "fi-f", "Luis": "multi-m"}, cast)
+}
+
+func TestCastVoicesKeepsSpeakersDistinct(t *testing.T) {
+	catalog, err := NewCatalog([]Voice{
+		{ID: "a", Gender: "female"},
+		{ID: "b", Gender: "female"},
+	}, "")
+	require.NoError(t, err)
+
+	dlg := testDialog("de")
+	cast := catalog.CastVoices(dlg)
+	require.NotEqual(t, cast["Ana"], cast["Luis"])
+	require.Equal(t, cast, catalog.CastVoices(dlg), "casting must be deterministic")
+}
+
+func TestCastVoicesFallsBackToDefault(t *testing.T) {
+	catalog, err := NewCatalog(nil, "fallback")
+	require.NoError(t, err)
+
+	cast := catalog.CastVoices(testDialog("es"))
+	require.Equal(t, map[string]string{"Ana": "fallback", "Luis": "fallback"}, cast)
+}
+
+func TestLoadCatalog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voices.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"voices":[{"id":"es-f","language":"ES","gender":"Female"}]}`), 0o644))
+
+	catalog, err := LoadCatalog(path, "fallback")
+	require.NoError(t, err)
+	require.Equal(t, "fallback", catalog.defaultVoice)
+	require.Equal(t, "es-f", catalog.CastVoices(testDialog("es"))["Ana"])
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"voices":[{"language":"es"}]}`), 0o644))
+	_, err = LoadCatalog(path, "")
+	require.Error(t, err)
+}
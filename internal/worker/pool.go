@@ -0,0 +1,126 @@
+// Package worker runs background dialog generation jobs.
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"leveltalk/internal/dialogs"
+)
+
+const defaultPollInterval = 2 * time.Second
+
+// Processor claims and runs a single job; dialogs.Service implements it.
+type Processor interface {
+	ProcessNextJob(ctx context.Context) error
+}
+
+// PoolOptions configures optional pool behavior.
+type PoolOptions struct {
+	PollInterval time.Duration
+}
+
+// Pool runs a fixed number of workers that poll the job queue.
+type Pool struct {
+	logger       *slog.Logger
+	processor    Processor
+	workers      int
+	pollInterval time.Duration
+
+	stop       chan struct{}
+	jobCtx     context.Context
+	cancelJobs context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewPool constructs a Pool; call Start to launch the workers.
+func NewPool(logger *slog.Logger, processor Processor, workers int, opts *PoolOptions) *Pool {
+	if opts == nil {
+		opts = &PoolOptions{}
+	}
+
+	pollInterval := opts.PollInterval
+	if pollInterval == 0 {
+		pollInterval = defaultPollInterval
+	}
+	if workers <= 0 {
+		workers = 1
+	}
+
+	jobCtx, cancel := context.WithCancel(context.Background())
+	return &Pool{
+		logger:       logger,
+		processor:    processor,
+		workers:      workers,
+		pollInterval: pollInterval,
+		stop:         make(chan struct{}),
+		jobCtx:       jobCtx,
+		cancelJobs:   cancel,
+	}
+}
+
+// Start launches the workers.
+func (p *Pool) Start() {
+	p.logger.Info("starting job workers", slog.Int("workers", p.workers))
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+		go p.run(i)
+	}
+}
+
+// Shutdown stops claiming new jobs and waits for in-flight jobs to finish.
+// When ctx expires first, running jobs are cancelled, which returns them to
+// the queue, and Shutdown waits for that hand-back before returning ctx.Err().
+func (p *Pool) Shutdown(ctx context.Context) error {
+	close(p.stop)
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancelJobs()
+		return nil
+	case <-ctx.Done():
+		p.logger.Warn("job drain timed out; returning in-flight jobs to the queue")
+		p.cancelJobs()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) run(worker int) {
+	defer p.wg.Done()
+	logger := p.logger.With(slog.Int("worker", worker))
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		err := p.processor.ProcessNextJob(p.jobCtx)
+		switch {
+		case err == nil:
+			continue
+		case errors.Is(err, dialogs.ErrNoJob):
+		case p.jobCtx.Err() != nil:
+			return
+		default:
+			logger.Error("job failed", slog.String("error", err.Error()))
+		}
+
+		select {
+		case <-p.stop:
+			return
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
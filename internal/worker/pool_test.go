@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"leveltalk/internal/dialogs"
+)
+
+type fakeProcessor struct {
+	remaining atomic.Int32
+	processed atomic.Int32
+	released  atomic.Int32
+	jobTime   time.Duration
+}
+
+func (f *fakeProcessor) ProcessNextJob(ctx context.Context) error {
+	if f.remaining.Add(-1) < 0 {
+		return dialogs.ErrNoJob
+	}
+	select {
+	case <-time.After(f.jobTime):
+		f.processed.Add(1)
+		return nil
+	case <-ctx.Done():
+		f.released.Add(1)
+		return ctx.Err()
+	}
+}
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestPoolDrainsInFlightJobsOnShutdown(t *testing.T) {
+	proc := &fakeProcessor{jobTime: 50 * time.Millisecond}
+	proc.remaining.Store(2)
+
+	pool := NewPool(testLogger(), proc, 2, &PoolOptions{PollInterval: 10 * time.Millisecond})
+	pool.Start()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, pool.Shutdown(ctx))
+	require.EqualValues(t, 2, proc.processed.Load())
+	require.EqualValues(t, 0, proc.released.Load())
+}
+
+func TestPoolCancelsJobsWhenDrainTimesOut(t *testing.T) {
+	proc := &fakeProcessor{jobTime: time.Minute}
+	proc.remaining.Store(1)
+
+	pool := NewPool(testLogger(), proc, 1, &PoolOptions{PollInterval: 10 * time.Millisecond})
+	pool.Start()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, pool.Shutdown(ctx), context.DeadlineExceeded)
+	require.EqualValues(t, 1, proc.released.Load())
+}
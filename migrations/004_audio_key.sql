@@ -0,0 +1 @@
+ALTER TABLE dialog_turns ADD COLUMN IF NOT EXISTS audio_key TEXT NOT NULL DEFAULT '';
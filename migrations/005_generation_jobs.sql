@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS generation_jobs (
+    id UUID PRIMARY KEY,
+    state TEXT NOT NULL CHECK (state IN ('queued','generating_text','synthesizing','done','failed')),
+    input JSONB NOT NULL,
+    dialog_id UUID REFERENCES dialogs(id) ON DELETE SET NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 3,
+    last_error TEXT NOT NULL DEFAULT '',
+    run_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_generation_jobs_queued ON generation_jobs(run_after, created_at) WHERE state = 'queued';
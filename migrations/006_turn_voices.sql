@@ -0,0 +1,2 @@
+ALTER TABLE dialog_turns ADD COLUMN IF NOT EXISTS gender TEXT NOT NULL DEFAULT '';
+ALTER TABLE dialog_turns ADD COLUMN IF NOT EXISTS voice_id TEXT NOT NULL DEFAULT '';
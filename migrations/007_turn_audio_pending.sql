@@ -0,0 +1 @@
+ALTER TABLE dialog_turns ADD COLUMN IF NOT EXISTS audio_pending BOOLEAN NOT NULL DEFAULT FALSE;
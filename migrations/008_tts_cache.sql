@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS tts_cache (
+    key TEXT PRIMARY KEY,
+    audio BYTEA NOT NULL,
+    size INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tts_cache_last_used ON tts_cache(last_used_at);
@@ -0,0 +1 @@
+ALTER TABLE dialogs ADD COLUMN IF NOT EXISTS repair_attempts INT NOT NULL DEFAULT 0;
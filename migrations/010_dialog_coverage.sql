@@ -0,0 +1,2 @@
+ALTER TABLE dialogs ADD COLUMN IF NOT EXISTS coverage REAL NOT NULL DEFAULT 1;
+ALTER TABLE dialogs ADD COLUMN IF NOT EXISTS coverage_flagged BOOLEAN NOT NULL DEFAULT FALSE;
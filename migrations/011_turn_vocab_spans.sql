@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS dialog_turn_spans (
+    turn_id UUID NOT NULL REFERENCES dialog_turns(id) ON DELETE CASCADE,
+    dialog_id UUID NOT NULL REFERENCES dialogs(id) ON DELETE CASCADE,
+    start_offset INT NOT NULL,
+    end_offset INT NOT NULL,
+    input_word TEXT NOT NULL,
+    PRIMARY KEY (turn_id, start_offset, input_word)
+);
+
+CREATE INDEX IF NOT EXISTS idx_dialog_turn_spans_dialog ON dialog_turn_spans(dialog_id);
+CREATE INDEX IF NOT EXISTS idx_dialog_turn_spans_word ON dialog_turn_spans(input_word);
@@ -0,0 +1 @@
+ALTER TABLE dialog_turns ADD COLUMN IF NOT EXISTS translation TEXT NOT NULL DEFAULT '';
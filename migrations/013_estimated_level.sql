@@ -0,0 +1 @@
+ALTER TABLE dialogs ADD COLUMN IF NOT EXISTS estimated_level TEXT NOT NULL DEFAULT '';
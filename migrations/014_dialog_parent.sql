@@ -0,0 +1,3 @@
+ALTER TABLE dialogs ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES dialogs(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_dialogs_parent_id ON dialogs(parent_id);
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS roleplay_sessions (
+    id UUID PRIMARY KEY,
+    input_language TEXT NOT NULL,
+    dialog_language TEXT NOT NULL,
+    cefr_level TEXT NOT NULL CHECK (cefr_level IN ('A1','A2','B1','B2','C1','C2')),
+    scenario TEXT NOT NULL DEFAULT '',
+    input_words JSONB NOT NULL,
+    learner_speaker TEXT NOT NULL,
+    partner_speaker TEXT NOT NULL,
+    partner_gender TEXT NOT NULL DEFAULT '',
+    partner_voice_id TEXT NOT NULL DEFAULT '',
+    dialog_id UUID REFERENCES dialogs(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS roleplay_messages (
+    id UUID PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES roleplay_sessions(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    speaker TEXT NOT NULL,
+    learner BOOLEAN NOT NULL,
+    text TEXT NOT NULL,
+    correction TEXT NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    audio_url TEXT NOT NULL DEFAULT '',
+    audio_key TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(session_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_roleplay_sessions_created_at ON roleplay_sessions(created_at DESC);
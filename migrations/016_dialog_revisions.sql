@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS dialog_revisions (
+    dialog_id UUID NOT NULL REFERENCES dialogs(id) ON DELETE CASCADE,
+    number INT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    translations JSONB NOT NULL DEFAULT '{}'::jsonb,
+    turns JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (dialog_id, number)
+);
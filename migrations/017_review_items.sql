@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS review_items (
+    id UUID PRIMARY KEY,
+    dialog_id UUID NOT NULL REFERENCES dialogs(id) ON DELETE CASCADE,
+    word TEXT NOT NULL,
+    ease DOUBLE PRECISION NOT NULL DEFAULT 2.5,
+    interval_days INT NOT NULL DEFAULT 0,
+    repetitions INT NOT NULL DEFAULT 0,
+    lapses INT NOT NULL DEFAULT 0,
+    due_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(dialog_id, word)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_items_due_at ON review_items(due_at);
+
+-- Data migrations that must run once, although the SQL files run on every
+-- start, record themselves here.
+CREATE TABLE IF NOT EXISTS data_migrations (
+    name TEXT PRIMARY KEY,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Seed the vocabulary of the dialogs created before the review queue. Later
+-- dialogs are seeded by the service. The marker row makes this a one-time
+-- backfill instead of a scan of every dialog on each start.
+WITH marker AS (
+    INSERT INTO data_migrations (name) VALUES ('017_review_items_backfill')
+    ON CONFLICT (name) DO NOTHING
+    RETURNING name
+)
+INSERT INTO review_items (id, dialog_id, word)
+SELECT gen_random_uuid(), d.id, w.word
+FROM dialogs d
+CROSS JOIN LATERAL jsonb_array_elements_text(d.input_words) AS w(word)
+WHERE EXISTS (SELECT 1 FROM marker)
+ON CONFLICT (dialog_id, word) DO NOTHING;
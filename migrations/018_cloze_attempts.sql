@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS cloze_attempts (
+    id UUID PRIMARY KEY,
+    learner_id UUID NOT NULL,
+    dialog_id UUID NOT NULL REFERENCES dialogs(id) ON DELETE CASCADE,
+    answers JSONB NOT NULL,
+    correct INT NOT NULL,
+    total INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cloze_attempts_learner ON cloze_attempts(learner_id, dialog_id, created_at DESC);
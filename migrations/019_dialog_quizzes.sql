@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS dialog_quizzes (
+    dialog_id UUID PRIMARY KEY REFERENCES dialogs(id) ON DELETE CASCADE,
+    questions JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
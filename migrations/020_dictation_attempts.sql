@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS dictation_attempts (
+    id UUID PRIMARY KEY,
+    learner_id UUID NOT NULL,
+    dialog_id UUID NOT NULL REFERENCES dialogs(id) ON DELETE CASCADE,
+    turn_id UUID NOT NULL REFERENCES dialog_turns(id) ON DELETE CASCADE,
+    given TEXT NOT NULL,
+    correct INT NOT NULL,
+    total INT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dictation_attempts_learner ON dictation_attempts(learner_id, dialog_id, turn_id);
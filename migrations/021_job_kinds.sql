@@ -0,0 +1,2 @@
+ALTER TABLE generation_jobs ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'create';
+ALTER TABLE generation_jobs ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES dialogs(id) ON DELETE SET NULL;
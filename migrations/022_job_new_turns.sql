@@ -0,0 +1 @@
+ALTER TABLE generation_jobs ADD COLUMN IF NOT EXISTS new_turns INT NOT NULL DEFAULT 0;